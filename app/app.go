@@ -33,6 +33,9 @@ import (
 	"github.com/wieku/danser-go/framework/graphics/blend"
 	"github.com/wieku/danser-go/framework/graphics/buffer"
 	"github.com/wieku/danser-go/framework/graphics/font"
+	"github.com/wieku/danser-go/framework/graphics/glext"
+	"github.com/wieku/danser-go/framework/graphics/headless"
+	"github.com/wieku/danser-go/framework/graphics/texture"
 	"github.com/wieku/danser-go/framework/graphics/viewport"
 	"github.com/wieku/danser-go/framework/math/mutils"
 	"github.com/wieku/danser-go/framework/math/vector"
@@ -78,12 +81,18 @@ var lastVSync bool
 
 var output string
 
+var layers *layerRenderer
+
 var recordMode bool
 var screenshotMode bool
 var screenshotTime float64
 
 var preciseProgress bool
 
+var headlessMode bool
+
+var logFile *os.File
+
 func run() {
 	defer func() {
 		if err := recover(); err != nil {
@@ -134,13 +143,20 @@ func run() {
 		out := flag.String("out", "", "If -ss flag is used, sets the name of screenshot, extension is PNG. If not, it overrides -record flag, specifies the name of recorded video file, extension is managed by settings")
 		ss := flag.Float64("ss", math.NaN(), "Screenshot mode. Snap single frame from danser at given time in seconds. Specify the name of file by -out, resolution is managed by Recording settings")
 
-		mods := flag.String("mods", "", "Specify beatmap/play mods. If NC/DT/HT is selected, overrides -speed and -pitch flags")
+		mods := flag.String("mods", "", "Specify beatmap/play mods. If NC/DT/HT is selected, overrides -speed and -pitch flags. Accepts osu!lazer mods MR, RD, DA, TC, WG, TR, GR, DF, AD, WU and WD with optional settings in parentheses, e.g. \"HDMR(axis=both)RD(seed=1337,angle=0.5)DA(ar=10,cs=5)AD(scale=6,style=gravity)WU(initial=1,final=1.5,pitch=false)\"")
+
+		exportReplay := flag.Bool("exportreplay", false, "Exports cursordance as .osr replay to danser's replays directory. Score is calculated by playing the map with the exported cursor")
+		exportCursor := flag.Int("exportcursor", 1, "Specify which cursor should be exported by -exportreplay flag if -cursors or -tag is greater than 1. Counted from 1")
+
+		verify := flag.String("verify", "", "Headless replay verification. Accepts a path to .osr file or a JSON list of paths. Prints a JSON report comparing danser's judgements with scores stored in replays and exits with non-zero code on mismatch")
 
 		replay := flag.String("replay", "", replayDesc)
 		flag.StringVar(replay, "r", "", replayDesc+shorthand)
 
 		skin := flag.String("skin", "", "Replace Skin.CurrentSkin setting temporarily")
 
+		mode := flag.String("mode", "osu", "Game mode used to watch osu!standard maps, accepts osu, taiko or catch. Replays set it automatically")
+
 		noDbCheck := flag.Bool("nodbcheck", false, "Don't validate the database and import new beatmaps if there are any. Useful for slow drives.")
 		noUpdCheck := flag.Bool("noupdatecheck", strings.HasPrefix(env.LibDir(), "/usr/lib/"), "Don't check for updates. Speeds up startup if older version of danser is needed for various reasons. Has no effect if danser is running as a linux package")
 
@@ -149,12 +165,20 @@ func run() {
 		cs := flag.Float64("cs", math.NaN(), "Modify map's CS, only in cursordance/play modes")
 		hp := flag.Float64("hp", math.NaN(), "Modify map's HP, only in cursordance/play modes")
 
+		judgementLog := flag.String("judgementlog", "", "Export judgements of each player to a file next to the rendered video. Accepts json or csv. Overrides Recording.JudgementLog setting")
+
 		offset := flag.Int("offset", 0, "Specify local audio offset in ms. Applies to recordings, unlike 'Audio.Offset'. Inverted compared to stable's local offset.")
 
 		flag.BoolVar(&preciseProgress, "preciseprogress", false, "Show rendering progress in 1% increments")
 
+		segments := flag.Int("segments", 1, "Split -record into given number of segments rendered in parallel by separate danser processes and join them without re-encoding. Each process simulates the map from the start, so it's most useful for long renders like knockouts")
+
+		noWindow := flag.Bool("headless", false, "Render without a window using offscreen EGL context, software renderers like Mesa's llvmpipe are supported. Works only with -record and -ss on Linux, enabled automatically if there's no display available")
+
 		flag.Parse()
 
+		loadSegmentEnv()
+
 		var knockoutReplays []string
 
 		if *knockout2 != "" {
@@ -192,9 +216,24 @@ func run() {
 			panic("Incompatible flags selected: -ss, -play")
 		} else if screenshotMode && recordMode {
 			panic("Incompatible flags selected: -ss, -record")
+		} else if *verify != "" && (*play || *record || screenshotMode || *knockout || *replay != "") {
+			panic("Incompatible flags selected: -verify, -play/-record/-ss/-knockout/-replay")
+		} else if *exportReplay && *play {
+			panic("Incompatible flags selected: -exportreplay, -play")
+		} else if *exportReplay && (*knockout || *replay != "") {
+			panic("Incompatible flags selected: -exportreplay, -knockout/-replay")
+		} else if *segments > 1 && !recordMode {
+			panic("flag -segments: works only with -record")
 		}
 
-		modsParsed := difficulty2.ParseMods(*mods)
+		segmentCount = mutils.Max(*segments, 1)
+
+		modsSeed := getModsSeed()
+
+		modsParsed, lazerMods, modsErr := difficulty2.ParseModsLazer(*mods, modsSeed)
+		if modsErr != nil {
+			panic(fmt.Sprintf("flag -mods: %s", modsErr))
+		}
 
 		if *replay != "" {
 			bytes, err := ioutil.ReadFile(*replay)
@@ -207,10 +246,6 @@ func run() {
 				panic(err)
 			}
 
-			if rp.PlayMode != 0 {
-				panic("Modes other than osu!standard are not supported")
-			}
-
 			if rp.ReplayData == nil || len(rp.ReplayData) < 2 {
 				panic("Replay is missing input data")
 			}
@@ -220,12 +255,61 @@ func run() {
 			modsParsed = difficulty2.Modifier(rp.Mods)
 			*knockout = true
 			settings.REPLAY = *replay
+			settings.PLAYMODE = int64(rp.PlayMode)
+		} else {
+			switch strings.ToLower(*mode) {
+			case "osu":
+				settings.PLAYMODE = beatmap.ModeOsu
+			case "taiko":
+				settings.PLAYMODE = beatmap.ModeTaiko
+			case "catch", "fruits":
+				settings.PLAYMODE = beatmap.ModeCatch
+			default:
+				panic(fmt.Sprintf("flag -mode: unsupported game mode \"%s\"", *mode))
+			}
+		}
+
+		var verifyReplays []string
+
+		if *verify != "" {
+			if strings.HasPrefix(strings.TrimSpace(*verify), "[") {
+				if err := json.Unmarshal([]byte(*verify), &verifyReplays); err != nil {
+					panic(fmt.Sprintf("Failed to parse replay list: %s", err))
+				}
+			} else {
+				verifyReplays = []string{*verify}
+			}
+
+			if len(verifyReplays) == 0 {
+				panic("No replays to verify")
+			}
+
+			bytes, err := ioutil.ReadFile(verifyReplays[0])
+			if err != nil {
+				panic(err)
+			}
+
+			rp, err := rplpa.ParseReplay(bytes)
+			if err != nil {
+				panic(err)
+			}
+
+			if *md5 == "" && *id < 0 {
+				*md5 = rp.BeatmapMD5
+			}
+
+			modsParsed = difficulty2.Modifier(rp.Mods)
 		}
 
 		if !modsParsed.Compatible() {
 			panic("Incompatible mods selected!")
 		}
 
+		if lazerMods != nil && (*knockout || *verify != "") {
+			log.Println("osu!lazer mods are not supported with replays, ignoring them...")
+			lazerMods = nil
+		}
+
 		closeAfterSettingsLoad := false
 
 		if (*md5+*artist+*title+*difficulty+*creator) == "" && *id < 0 {
@@ -245,7 +329,11 @@ func run() {
 		settings.START = *start
 		settings.END = *end
 		settings.RECORD = recordMode || screenshotMode
+
+		headlessMode = settings.RECORD && (*noWindow || headless.NoDisplay())
 		settings.LOCALOFFSET = *offset
+		settings.EXPORTREPLAY = *exportReplay
+		settings.EXPORTCURSOR = *exportCursor
 
 		if *settingsVersion == "credentials" || *settingsVersion == "launcher" {
 			panic(fmt.Sprintf("flag -settings: name \"%s\" is forbidden", *settingsVersion))
@@ -312,10 +400,24 @@ func run() {
 				}
 			}
 
+			if beatMap != nil {
+				if beatMap.Mode != beatmap.ModeOsu && settings.PLAYMODE != beatmap.ModeOsu && settings.PLAYMODE != beatMap.Mode {
+					panic("Replay's game mode doesn't match beatmap's game mode")
+				}
+
+				if settings.PLAYMODE == beatmap.ModeMania && beatMap.Mode != beatmap.ModeMania {
+					panic("Converting osu!standard maps to osu!mania is not supported")
+				}
+
+				if (beatMap.Mode != beatmap.ModeOsu || settings.PLAYMODE != beatmap.ModeOsu) && (*play || *exportReplay || verifyReplays != nil) {
+					panic("Game modes other than osu!standard can be only watched")
+				}
+			}
+
 			if beatMap == nil {
 				log.Println("Beatmap not found, closing...")
 				closeAfterSettingsLoad = true
-			} else {
+			} else if verifyReplays == nil && segment < 0 { // Main process of segmented recording already did that
 				beatMap.UpdatePlayStats()
 				database.UpdatePlayStats(beatMap)
 			}
@@ -323,27 +425,42 @@ func run() {
 			database.Close()
 		}
 
-		assets.Init(build.Stream == "Dev")
-
-		if !closeAfterSettingsLoad {
-			log.Println("Initializing GLFW...")
+		if verifyReplays != nil {
+			runVerification(beatMap, modsParsed, verifyReplays)
 		}
 
-		err := glfw.Init()
-		if err != nil {
-			panic("Failed to initialize GLFW: " + err.Error())
+		if segmentCount > 1 && segment < 0 && !closeAfterSettingsLoad {
+			runSegmented(modsSeed)
 		}
 
-		glfw.WindowHint(glfw.ContextVersionMajor, 3)
-		glfw.WindowHint(glfw.ContextVersionMinor, 3)
-		glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-		glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
-		glfw.WindowHint(glfw.Resizable, glfw.False)
-		glfw.WindowHint(glfw.Samples, 0)
-		glfw.WindowHint(glfw.Visible, glfw.False)
+		assets.Init(build.Stream == "Dev")
+
+		var monitor *glfw.Monitor
+
+		// There's no monitor to query in headless mode, assume the most common resolution
+		mWidth, mHeight := 1920, 1080
+
+		if !headlessMode {
+			if !closeAfterSettingsLoad {
+				log.Println("Initializing GLFW...")
+			}
+
+			err := glfw.Init()
+			if err != nil {
+				panic("Failed to initialize GLFW: " + err.Error())
+			}
+
+			glfw.WindowHint(glfw.ContextVersionMajor, 3)
+			glfw.WindowHint(glfw.ContextVersionMinor, 3)
+			glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+			glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+			glfw.WindowHint(glfw.Resizable, glfw.False)
+			glfw.WindowHint(glfw.Samples, 0)
+			glfw.WindowHint(glfw.Visible, glfw.False)
 
-		monitor := glfw.GetPrimaryMonitor()
-		mWidth, mHeight := monitor.GetVideoMode().Width, monitor.GetVideoMode().Height
+			monitor = glfw.GetPrimaryMonitor()
+			mWidth, mHeight = monitor.GetVideoMode().Width, monitor.GetVideoMode().Height
+		}
 
 		if newSettings {
 			settings.Graphics.SetDefaults(int64(mWidth), int64(mHeight))
@@ -362,6 +479,10 @@ func run() {
 			settings.KNOCKOUT = true
 			settings.Knockout.MaxPlayers = 0
 			allowDA = true
+
+			if settings.EXPORTREPLAY {
+				panic("Incompatible flags selected: -exportreplay, -mods=AT")
+			}
 		}
 
 		lastSamples = int(settings.Graphics.MSAA)
@@ -370,6 +491,14 @@ func run() {
 			settings.Skin.CurrentSkin = *skin
 		}
 
+		if strings.TrimSpace(*judgementLog) != "" {
+			settings.Recording.JudgementLog = strings.ToLower(strings.TrimSpace(*judgementLog))
+
+			if settings.Recording.JudgementLog != "json" && settings.Recording.JudgementLog != "csv" {
+				panic(fmt.Sprintf("Unknown judgement log format: %s", settings.Recording.JudgementLog))
+			}
+		}
+
 		if *quickstart {
 			settings.SKIP = true
 			settings.Playfield.LeadInTime = 0
@@ -387,57 +516,50 @@ func run() {
 			settings.Playfield.LeadInTime = 0
 		}
 
-		if screenshotMode {
-			settings.Playfield.LeadInHold = 0
-			settings.START = screenshotTime - 5
-			settings.SKIP = false
-		}
-
-		if settings.Graphics.Fullscreen {
-			glfw.WindowHint(glfw.RedBits, monitor.GetVideoMode().RedBits)
-			glfw.WindowHint(glfw.GreenBits, monitor.GetVideoMode().GreenBits)
-			glfw.WindowHint(glfw.BlueBits, monitor.GetVideoMode().BlueBits)
-			glfw.WindowHint(glfw.RefreshRate, monitor.GetVideoMode().RefreshRate)
-			//glfw.WindowHint(glfw.Decorated, glfw.False)
-			win, err = glfw.CreateWindow(int(settings.Graphics.Width), int(settings.Graphics.Height), "danser", monitor, nil)
-		} else {
-			win, err = glfw.CreateWindow(int(settings.Graphics.WindowWidth), int(settings.Graphics.WindowHeight), "danser", nil, nil)
+		if recordMode && settings.Recording.Transparent {
+			// Only gameplay elements and HUD should end up in the video
+			settings.Playfield.Background.LoadStoryboards = false
+			settings.Playfield.Background.LoadVideos = false
+			settings.Playfield.Background.FlashToTheBeat = false
+			settings.Playfield.Background.Blur.Enabled = false
+			settings.Playfield.Background.Triangles.Enabled = false
+			settings.Playfield.Background.Dim.Intro = 1
+			settings.Playfield.Background.Dim.Normal = 1
+			settings.Playfield.Background.Dim.Breaks = 1
 		}
 
-		if err != nil {
-			panic(err)
-		}
+		if recordMode && settings.Recording.Layers.Enabled {
+			// Bloom spans multiple layers, so it can't be split between them
+			settings.Playfield.Bloom.Enabled = false
 
-		if !*record {
-			win.SetFocusCallback(func(w *glfw.Window, focused bool) {
-				log.Println("Focus changed: ", focused)
-				input.Focused = focused
-			})
+			if !settings.Recording.HasAlpha() {
+				log.Println("Layer separation is enabled, but selected output doesn't support alpha channel. Layers will be rendered over black background.")
+			}
 		}
 
-		win.SetTitle("danser " + build.VERSION + " - " + beatMap.Artist + " - " + beatMap.Name + " [" + beatMap.Difficulty + "]")
-		input.Win = win
-
-		icon, eee := assets.GetPixmap("assets/textures/dansercoin.png")
-		if eee != nil {
-			log.Println(eee)
+		if screenshotMode {
+			settings.Playfield.LeadInHold = 0
+			settings.START = screenshotTime - 5
+			settings.SKIP = false
 		}
-		icon2, _ := assets.GetPixmap("assets/textures/dansercoin48.png")
-		icon3, _ := assets.GetPixmap("assets/textures/dansercoin24.png")
-		icon4, _ := assets.GetPixmap("assets/textures/dansercoin16.png")
 
-		win.SetIcon([]image.Image{icon.NRGBA(), icon2.NRGBA(), icon3.NRGBA(), icon4.NRGBA()})
+		if headlessMode {
+			log.Println("Initializing headless OpenGL context...")
 
-		icon.Dispose()
-		icon2.Dispose()
-		icon3.Dispose()
-		icon4.Dispose()
+			if err := headless.Init(); err != nil {
+				panic("Failed to initialize headless OpenGL context: " + err.Error())
+			}
 
-		win.MakeContextCurrent()
+			log.Println("Headless OpenGL context initialized!")
 
-		log.Println("GLFW initialized!")
+			if err := gl.InitWithProcAddrFunc(headless.GetProcAddress); err != nil {
+				panic("Failed to load OpenGL functions: " + err.Error())
+			}
+		} else {
+			createWindow(monitor, beatMap, *record)
 
-		gl.Init()
+			gl.Init()
+		}
 
 		extensionCheck()
 
@@ -509,10 +631,13 @@ func run() {
 		font.GetFont("Quicksand Bold").Draw(batch, 0, settings.Graphics.GetHeightF()-10, 32, "Loading...")
 
 		batch.End()
-		win.SwapBuffers()
 
-		glfw.SwapInterval(1)
-		lastVSync = true
+		if !headlessMode {
+			win.SwapBuffers()
+
+			glfw.SwapInterval(1)
+			lastVSync = true
+		}
 
 		bass.Init(settings.RECORD)
 		audio.LoadSamples()
@@ -532,6 +657,10 @@ func run() {
 		}
 
 		if settings.PLAY || !settings.KNOCKOUT || allowDA {
+			if lazerMods != nil && lazerMods.DifficultyAdjust {
+				applyDifficultyAdjust(beatMap, lazerMods)
+			}
+
 			if !math.IsNaN(*ar) {
 				beatMap.Diff.SetARCustom(*ar)
 			}
@@ -552,6 +681,7 @@ func run() {
 		}
 
 		beatMap.Diff.SetMods(modsParsed)
+		beatMap.Diff.LazerMods = lazerMods
 		beatmap.ParseTimingPointsAndPauses(beatMap)
 		beatmap.ParseObjects(beatMap, false, true)
 		beatMap.LoadCustomSamples()
@@ -567,6 +697,26 @@ func run() {
 	} else {
 		mainLoopNormal()
 	}
+
+	mainthread.Call(player.Dispose)
+}
+
+func applyDifficultyAdjust(beatMap *beatmap.BeatMap, lazerMods *difficulty2.LazerMods) {
+	if !math.IsNaN(lazerMods.AR) {
+		beatMap.Diff.SetARCustom(lazerMods.AR)
+	}
+
+	if !math.IsNaN(lazerMods.OD) {
+		beatMap.Diff.SetODCustom(lazerMods.OD)
+	}
+
+	if !math.IsNaN(lazerMods.CS) {
+		beatMap.Diff.SetCSCustom(lazerMods.CS)
+	}
+
+	if !math.IsNaN(lazerMods.HP) {
+		beatMap.Diff.SetHPCustom(lazerMods.HP)
+	}
 }
 
 func mainLoopRecord() {
@@ -583,22 +733,42 @@ func mainLoopRecord() {
 
 	var fbo *buffer.Framebuffer
 
+	layerNames := getLayerNames()
+
 	mainthread.Call(func() {
-		fbo = buffer.NewFrameMultisampleScreen(w, h, false, 0)
+		fbo = buffer.NewFrameMultisampleScreenFormat(w, h, false, 0, ffmpeg.GetFramebufferFormat())
+
+		if layerNames != nil {
+			layers = newLayerRenderer(w, h)
+		}
 	})
 
-	ffmpeg.StartFFmpeg(int(fps), w, h, audioFPS, output)
+	p, _ := player.(*states.Player)
 
 	updateFPS := math.Max(fps, 1000)
 	updateDelta := 1000 / updateFPS
 	fpsDelta := 1000 / fps
 	audioDelta := 1000.0 / audioFPS
 
+	// Frames are counted from the start of the recording, worker processes draw only frames of their segment
+	frameIndex, drawStart, firstFrame, endFrame := int64(0), int64(0), int64(0), int64(math.MaxInt64)
+
+	if segment >= 0 {
+		firstFrame, endFrame = getSegmentBounds(p, fpsDelta)
+
+		warmUp := mutils.Max(int64(math.Ceil(segmentWarmUp/fpsDelta)), int64(settings.Recording.MotionBlur.BlendFrames))
+		drawStart = mutils.Max(0, firstFrame-warmUp)
+
+		ffmpeg.SetSegment(segment, segmentCount, drawStart, firstFrame)
+
+		log.Println(fmt.Sprintf("Rendering segment %d/%d, simulating map up to its start...", segment+1, segmentCount))
+	}
+
+	ffmpeg.StartFFmpeg(int(fps), w, h, audioFPS, output, layerNames)
+
 	deltaSumF := fpsDelta
 	deltaSumA := 0.0
 
-	p, _ := player.(*states.Player)
-
 	lastCount := int64(0)
 	lastRealTime := qpc.GetMilliTimeF()
 
@@ -608,6 +778,12 @@ func mainLoopRecord() {
 		lastProgress = -1
 	}
 
+	progressLength := p.RunningTime
+
+	if segment >= 0 { // Worker processes report progress of their segment
+		progressLength = float64(mutils.Min(endFrame, int64(p.RunningTime/settings.SPEED/fpsDelta))-firstFrame) * fpsDelta
+	}
+
 	for !p.Update(updateDelta) {
 		deltaSumA += updateDelta
 		for deltaSumA >= audioDelta {
@@ -617,29 +793,55 @@ func mainLoopRecord() {
 		}
 
 		deltaSumF += updateDelta
-		if deltaSumF >= fpsDelta {
+		if deltaSumF >= fpsDelta && frameIndex < drawStart {
+			// Frames before segment's warm-up are skipped, map is only simulated
+			frameIndex++
+			deltaSumF -= fpsDelta
+		} else if deltaSumF >= fpsDelta {
 			mainthread.Call(func() {
+				if frameIndex == firstFrame { // Simulation before segment's start doesn't count into rendering speed
+					lastCount = count
+					lastRealTime = qpc.GetMilliTimeF()
+				}
+
 				fbo.Bind()
 
-				ffmpeg.PreFrame()
+				if layers != nil {
+					viewport.Push(int(settings.Graphics.GetWidth()), int(settings.Graphics.GetHeight()))
+					pushFrame()
 
-				viewport.Push(int(settings.Graphics.GetWidth()), int(settings.Graphics.GetHeight()))
-				pushFrame()
-				viewport.Pop()
+					for i := range layerNames {
+						ffmpeg.PreFrame(i)
+						layers.drawOutput(i)
+						ffmpeg.MakeFrame(i)
+					}
+
+					viewport.Pop()
+				} else {
+					ffmpeg.PreFrame(0)
 
-				ffmpeg.MakeFrame()
+					viewport.Push(int(settings.Graphics.GetWidth()), int(settings.Graphics.GetHeight()))
+					pushFrame()
+					viewport.Pop()
+
+					ffmpeg.MakeFrame(0)
+				}
 
 				fbo.Unbind()
 
 				count++
 
 				timeOffset := p.GetTimeOffset()
-				progress = int(math.Round(timeOffset / p.RunningTime * 100))
+				if segment >= 0 {
+					timeOffset = float64(frameIndex-firstFrame) * fpsDelta
+				}
+
+				progress = int(math.Round(timeOffset / progressLength * 100))
 
-				if (preciseProgress || progress%5 == 0) && lastProgress != progress {
+				if (preciseProgress || progress%5 == 0) && lastProgress != progress && frameIndex >= firstFrame {
 					speed := float64(count-lastCount) * (1000 / fps) / (qpc.GetMilliTimeF() - lastRealTime)
 
-					eta := int((p.RunningTime - timeOffset) / 1000 / speed)
+					eta := int((progressLength - timeOffset) / 1000 / speed)
 
 					etaText := util.FormatSeconds(eta)
 
@@ -656,7 +858,12 @@ func mainLoopRecord() {
 				}
 			})
 
+			frameIndex++
 			deltaSumF -= fpsDelta
+
+			if frameIndex >= endFrame {
+				break
+			}
 		}
 	}
 
@@ -750,6 +957,54 @@ func mainLoopNormal() {
 	settings.CloseWatcher()
 }
 
+func createWindow(monitor *glfw.Monitor, beatMap *beatmap.BeatMap, record bool) {
+	var err error
+
+	if settings.Graphics.Fullscreen {
+		glfw.WindowHint(glfw.RedBits, monitor.GetVideoMode().RedBits)
+		glfw.WindowHint(glfw.GreenBits, monitor.GetVideoMode().GreenBits)
+		glfw.WindowHint(glfw.BlueBits, monitor.GetVideoMode().BlueBits)
+		glfw.WindowHint(glfw.RefreshRate, monitor.GetVideoMode().RefreshRate)
+		//glfw.WindowHint(glfw.Decorated, glfw.False)
+		win, err = glfw.CreateWindow(int(settings.Graphics.Width), int(settings.Graphics.Height), "danser", monitor, nil)
+	} else {
+		win, err = glfw.CreateWindow(int(settings.Graphics.WindowWidth), int(settings.Graphics.WindowHeight), "danser", nil, nil)
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	if !record {
+		win.SetFocusCallback(func(w *glfw.Window, focused bool) {
+			log.Println("Focus changed: ", focused)
+			input.Focused = focused
+		})
+	}
+
+	win.SetTitle("danser " + build.VERSION + " - " + beatMap.Artist + " - " + beatMap.Name + " [" + beatMap.Difficulty + "]")
+	input.Win = win
+
+	icon, eee := assets.GetPixmap("assets/textures/dansercoin.png")
+	if eee != nil {
+		log.Println(eee)
+	}
+	icon2, _ := assets.GetPixmap("assets/textures/dansercoin48.png")
+	icon3, _ := assets.GetPixmap("assets/textures/dansercoin24.png")
+	icon4, _ := assets.GetPixmap("assets/textures/dansercoin16.png")
+
+	win.SetIcon([]image.Image{icon.NRGBA(), icon2.NRGBA(), icon3.NRGBA(), icon4.NRGBA()})
+
+	icon.Dispose()
+	icon2.Dispose()
+	icon3.Dispose()
+	icon4.Dispose()
+
+	win.MakeContextCurrent()
+
+	log.Println("GLFW initialized!")
+}
+
 func extensionCheck() {
 	extensions := []string{
 		"GL_ARB_clear_texture",
@@ -762,7 +1017,7 @@ func extensionCheck() {
 	var notSupported []string
 
 	for _, ext := range extensions {
-		if !glfw.ExtensionSupported(ext) {
+		if !glext.ExtensionSupported(ext) {
 			notSupported = append(notSupported, ext)
 		}
 	}
@@ -791,7 +1046,12 @@ func pushFrame() {
 			screenFBO.Dispose()
 		}
 
-		screenFBO = buffer.NewFrameMultisampleScreen(int(settings.Graphics.GetWidth()), int(settings.Graphics.GetHeight()), false, int(settings.Graphics.MSAA))
+		format := texture.RGBA
+		if recordMode {
+			format = ffmpeg.GetFramebufferFormat()
+		}
+
+		screenFBO = buffer.NewFrameMultisampleScreenFormat(int(settings.Graphics.GetWidth()), int(settings.Graphics.GetHeight()), false, int(settings.Graphics.MSAA), format)
 
 		lastSamples = int(settings.Graphics.MSAA)
 	}
@@ -800,10 +1060,17 @@ func pushFrame() {
 		screenFBO.Bind()
 	}
 
-	gl.ClearColor(0, 0, 0, 1)
+	if recordMode && settings.Recording.Transparent {
+		gl.ClearColor(0, 0, 0, 0)
+	} else {
+		gl.ClearColor(0, 0, 0, 1)
+	}
+
 	gl.Clear(gl.COLOR_BUFFER_BIT)
 
-	if player != nil {
+	if p, ok := player.(*states.Player); ok && layers != nil {
+		layers.drawPlayer(p)
+	} else if player != nil {
 		player.Draw(0)
 	}
 
@@ -878,16 +1145,18 @@ func Run() {
 
 	log.Println("danser-go version:", build.VERSION)
 
-	file, err := os.Create(filepath.Join(env.DataDir(), "danser.log"))
+	var err error
+
+	logFile, err = os.Create(filepath.Join(env.DataDir(), getLogName()))
 	if err != nil {
 		panic(err)
 	}
 
-	log.SetOutput(file)
+	log.SetOutput(logFile)
 
 	printPlatformInfo()
 
-	log.SetOutput(io.MultiWriter(os.Stdout, file))
+	log.SetOutput(io.MultiWriter(os.Stdout, logFile))
 
 	platform.DisableQuickEdit()
 
@@ -898,6 +1167,7 @@ func Run() {
 
 func closeHandler(err any, stackTrace []string) {
 	settings.CloseWatcher()
+	headless.Destroy()
 	discord.Disconnect()
 	platform.EnableQuickEdit()
 
@@ -37,6 +37,24 @@ func AddListener(function func(sampleSet int, hitsoundIndex, index int, volume f
 	listeners = append(listeners, function)
 }
 
+var hitSoundListeners = make([]*func(sampleSet, additionSet, hitsound, index int, objNum int64), 0)
+
+// AddHitSoundListener registers a function called for every played hitsound, returned function unregisters it
+func AddHitSoundListener(function func(sampleSet, additionSet, hitsound, index int, objNum int64)) (remove func()) {
+	listener := &function
+
+	hitSoundListeners = append(hitSoundListeners, listener)
+
+	return func() {
+		for i, l := range hitSoundListeners {
+			if l == listener {
+				hitSoundListeners = append(hitSoundListeners[:i], hitSoundListeners[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
 func LoadSamples() {
 	Samples[0][0] = LoadSample("normal-hitnormal")
 	Samples[0][1] = LoadSample("normal-hitwhistle")
@@ -68,6 +86,10 @@ func PlaySample(sampleSet, additionSet, hitsound, index int, volume float64, obj
 		additionSet = sampleSet
 	}
 
+	for _, f := range hitSoundListeners {
+		(*f)(normalizeSampleSet(sampleSet), normalizeSampleSet(additionSet), hitsound, index, objNum)
+	}
+
 	// Play normal
 	if skin.GetInfo().LayeredHitSounds || hitsound&1 > 0 || hitsound == 0 {
 		playSample(sampleSet, 0, index, volume, objNum, xPos)
@@ -99,11 +121,7 @@ func playSample(sampleSet int, hitsoundIndex, index int, volume float64, objNum
 		volume = 1.0
 	}
 
-	if sampleSet == 0 {
-		sampleSet = 2
-	} else if sampleSet < 0 || sampleSet > 3 {
-		sampleSet = 1
-	}
+	sampleSet = normalizeSampleSet(sampleSet)
 
 	for _, f := range listeners {
 		f(sampleSet, hitsoundIndex, index, volume, objNum)
@@ -116,6 +134,16 @@ func playSample(sampleSet int, hitsoundIndex, index int, volume float64, objNum
 	}
 }
 
+func normalizeSampleSet(sampleSet int) int {
+	if sampleSet == 0 {
+		return 2
+	} else if sampleSet < 0 || sampleSet > 3 {
+		return 1
+	}
+
+	return sampleSet
+}
+
 var whistleChannel *bass.SampleChannel = nil
 var slideChannel *bass.SampleChannel = nil
 var lastSampleSet = 0
@@ -178,11 +206,7 @@ func playSampleLoop(sampleSet int, hitsoundIndex, index int, volume float64, obj
 		volume = 1.0
 	}
 
-	if sampleSet == 0 {
-		sampleSet = 2
-	} else if sampleSet < 0 || sampleSet > 3 {
-		sampleSet = 1
-	}
+	sampleSet = normalizeSampleSet(sampleSet)
 
 	for _, f := range listeners {
 		f(sampleSet, hitsoundIndex, index, volume, objNum)
@@ -10,6 +10,14 @@ import (
 	"time"
 )
 
+// Game modes as stored in .osu files
+const (
+	ModeOsu = int64(iota)
+	ModeTaiko
+	ModeCatch
+	ModeMania
+)
+
 type BeatMap struct {
 	Artist        string
 	ArtistUnicode string
@@ -58,6 +66,8 @@ type BeatMap struct {
 	ARSpecified bool
 
 	LocalOffset int
+
+	lastUpdate float64
 }
 
 func NewBeatMap() *BeatMap {
@@ -82,12 +92,29 @@ func (beatMap *BeatMap) Reset() {
 	}
 }
 
+// Rewind prepares objects to be updated again from the given time.
+// Only objects which could have been already shown and are still visible at that time get their visuals rebuilt.
+func (beatMap *BeatMap) Rewind(time float64) {
+	beatMap.Queue = beatMap.GetObjectsCopy()
+	beatMap.Timings.Reset()
+
+	for _, o := range beatMap.HitObjects {
+		if o.GetStartTime()-beatMap.Diff.Preempt > beatMap.lastUpdate || time >= o.GetEndTime()+difficulty.HitFadeOut+float64(beatMap.Diff.Hit50) {
+			continue
+		}
+
+		o.SetDifficulty(beatMap.Diff)
+	}
+}
+
 func (beatMap *BeatMap) Clear() {
 	beatMap.HitObjects = make([]objects.IHitObject, 0)
 	beatMap.Timings = objects.NewTimings()
 }
 
 func (beatMap *BeatMap) Update(time float64) {
+	beatMap.lastUpdate = math.Max(beatMap.lastUpdate, time)
+
 	beatMap.Timings.Update(time)
 
 	for i := 0; i < len(beatMap.Queue); i++ {
@@ -48,6 +48,8 @@ type Difficulty struct {
 	ARReal      float64
 	ODReal      float64
 	CustomSpeed float64
+
+	LazerMods *LazerMods
 }
 
 func NewDifficulty(hp, cs, od, ar float64) *Difficulty {
@@ -176,7 +178,7 @@ func (diff *Difficulty) GetModStringFull() []string {
 }
 
 func (diff *Difficulty) GetModString() string {
-	mods := diff.Mods.String()
+	mods := diff.Mods.String() + diff.LazerMods.String()
 
 	if ar := diff.GetAR(); ar != diff.GetBaseAR() {
 		mods += fmt.Sprintf("AR%s", mutils.FormatWOZeros(ar, 2))
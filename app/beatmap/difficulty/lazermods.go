@@ -0,0 +1,342 @@
+package difficulty
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type MirrorAxis int
+
+const (
+	MirrorHorizontal = MirrorAxis(1 << iota)
+	MirrorVertical
+	MirrorBoth = MirrorHorizontal | MirrorVertical
+)
+
+// LazerMods holds osu!lazer mods that transform hit objects and their settings.
+// They are specified in -mods string after stable mods, settings are given in parentheses, e.g. "HDMR(axis=both)RD(seed=1337)DA(ar=10.5)"
+type LazerMods struct {
+	Mirror MirrorAxis
+
+	Random      bool
+	RandomSeed  int64
+	RandomAngle float64
+
+	DifficultyAdjust bool
+	AR, CS, OD, HP   float64 // NaN if not adjusted
+
+	Traceable bool
+
+	Wiggle         bool
+	WiggleStrength float64
+
+	Transform bool
+
+	Grow           bool
+	GrowStartScale float64
+
+	Deflate           bool
+	DeflateStartScale float64
+
+	ApproachDifferent bool
+	ApproachScale     float64
+	ApproachStyle     string
+
+	WindUp      bool
+	WindDown    bool
+	InitialRate float64
+	FinalRate   float64
+	AdjustPitch bool
+}
+
+var lazerModsString = [...]string{"MR", "RD", "DA", "TC", "WG", "TR", "GR", "DF", "AD", "WU", "WD"}
+
+// ApproachStyles lists styles accepted by Approach Different mod
+var ApproachStyles = [...]string{"linear", "gravity", "inout1", "inout2", "accelerate1", "accelerate2", "accelerate3", "decelerate1", "decelerate2", "decelerate3"}
+
+func newLazerMods() *LazerMods {
+	return &LazerMods{
+		RandomAngle:       1,
+		AR:                math.NaN(),
+		CS:                math.NaN(),
+		OD:                math.NaN(),
+		HP:                math.NaN(),
+		WiggleStrength:    1,
+		GrowStartScale:    0.5,
+		DeflateStartScale: 2,
+		ApproachScale:     4,
+		ApproachStyle:     "gravity",
+	}
+}
+
+// ParseModsLazer parses stable and osu!lazer mods, returned LazerMods is nil if there are no lazer mods.
+// randomSeed is used by Random mod if seed wasn't specified.
+func ParseModsLazer(mods string, randomSeed int64) (Modifier, *LazerMods, error) {
+	var stable strings.Builder
+
+	lazer := newLazerMods()
+	lazer.RandomSeed = randomSeed
+
+	anyLazer := false
+
+	runes := []rune(strings.ToUpper(mods))
+
+	for i := 0; i < len(runes); {
+		if runes[i] == ',' || unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		if i+1 >= len(runes) {
+			return None, nil, fmt.Errorf("invalid mod: \"%s\"", string(runes[i:]))
+		}
+
+		acronym := string(runes[i : i+2])
+		i += 2
+
+		params := make(map[string]string)
+
+		if i < len(runes) && runes[i] == '(' {
+			end := i + 1
+			for end < len(runes) && runes[end] != ')' {
+				end++
+			}
+
+			if end == len(runes) {
+				return None, nil, fmt.Errorf("missing ')' in settings of %s", acronym)
+			}
+
+			for _, param := range strings.Split(string(runes[i+1:end]), ",") {
+				if strings.TrimSpace(param) == "" {
+					continue
+				}
+
+				key, value, found := strings.Cut(param, "=")
+				if !found {
+					return None, nil, fmt.Errorf("invalid setting of %s: \"%s\"", acronym, param)
+				}
+
+				params[strings.ToLower(strings.TrimSpace(key))] = strings.ToLower(strings.TrimSpace(value))
+			}
+
+			i = end + 1
+		}
+
+		if !isLazerMod(acronym) {
+			if len(params) > 0 {
+				return None, nil, fmt.Errorf("mod %s doesn't have settings", acronym)
+			}
+
+			stable.WriteString(acronym)
+
+			continue
+		}
+
+		anyLazer = true
+
+		if err := lazer.apply(acronym, params); err != nil {
+			return None, nil, err
+		}
+	}
+
+	if lazer.WindUp && lazer.WindDown {
+		return None, nil, fmt.Errorf("WU and WD can't be used together")
+	}
+
+	if lazer.Grow && lazer.Deflate {
+		return None, nil, fmt.Errorf("GR and DF can't be used together")
+	}
+
+	if !anyLazer {
+		lazer = nil
+	}
+
+	return ParseMods(stable.String()), lazer, nil
+}
+
+func isLazerMod(acronym string) bool {
+	for _, m := range lazerModsString {
+		if m == acronym {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (mods *LazerMods) apply(acronym string, params map[string]string) (err error) {
+	float := func(key string, target *float64) {
+		if value, ok := params[key]; ok && err == nil {
+			delete(params, key)
+
+			if *target, err = strconv.ParseFloat(value, 64); err != nil {
+				err = fmt.Errorf("invalid value of %s setting in %s: \"%s\"", key, acronym, value)
+			}
+		}
+	}
+
+	switch acronym {
+	case "MR":
+		mods.Mirror = MirrorHorizontal
+
+		if axis, ok := params["axis"]; ok {
+			delete(params, "axis")
+
+			switch axis {
+			case "horizontal", "h":
+				mods.Mirror = MirrorHorizontal
+			case "vertical", "v":
+				mods.Mirror = MirrorVertical
+			case "both", "b":
+				mods.Mirror = MirrorBoth
+			default:
+				return fmt.Errorf("invalid mirror axis: \"%s\", accepts horizontal, vertical or both", axis)
+			}
+		}
+	case "RD":
+		mods.Random = true
+
+		if seed, ok := params["seed"]; ok {
+			delete(params, "seed")
+
+			if mods.RandomSeed, err = strconv.ParseInt(seed, 10, 64); err != nil {
+				return fmt.Errorf("invalid random seed: \"%s\"", seed)
+			}
+		}
+
+		float("angle", &mods.RandomAngle)
+	case "DA":
+		mods.DifficultyAdjust = true
+
+		float("ar", &mods.AR)
+		float("cs", &mods.CS)
+		float("od", &mods.OD)
+		float("hp", &mods.HP)
+	case "TC":
+		mods.Traceable = true
+	case "WG":
+		mods.Wiggle = true
+
+		float("strength", &mods.WiggleStrength)
+	case "TR":
+		mods.Transform = true
+	case "GR":
+		mods.Grow = true
+
+		float("scale", &mods.GrowStartScale)
+	case "DF":
+		mods.Deflate = true
+
+		float("scale", &mods.DeflateStartScale)
+	case "AD":
+		mods.ApproachDifferent = true
+
+		float("scale", &mods.ApproachScale)
+
+		if style, ok := params["style"]; ok {
+			delete(params, "style")
+
+			valid := false
+			for _, s := range ApproachStyles {
+				valid = valid || s == style
+			}
+
+			if !valid {
+				return fmt.Errorf("invalid approach style: \"%s\", accepts %s", style, strings.Join(ApproachStyles[:], ", "))
+			}
+
+			mods.ApproachStyle = style
+		}
+	case "WU", "WD":
+		mods.WindUp = acronym == "WU"
+		mods.WindDown = acronym == "WD"
+
+		mods.InitialRate = 1
+		mods.FinalRate = 1.5
+		mods.AdjustPitch = true
+
+		if mods.WindDown {
+			mods.FinalRate = 0.75
+		}
+
+		float("initial", &mods.InitialRate)
+		float("final", &mods.FinalRate)
+
+		if pitch, ok := params["pitch"]; ok {
+			delete(params, "pitch")
+
+			if mods.AdjustPitch, err = strconv.ParseBool(pitch); err != nil {
+				return fmt.Errorf("invalid value of pitch setting in %s: \"%s\"", acronym, pitch)
+			}
+		}
+
+		if mods.InitialRate <= 0 || mods.FinalRate <= 0 {
+			return fmt.Errorf("rates of %s have to be positive", acronym)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for key := range params {
+		return fmt.Errorf("unknown setting of %s: \"%s\"", acronym, key)
+	}
+
+	return nil
+}
+
+func (mods *LazerMods) String() (s string) {
+	if mods == nil {
+		return
+	}
+
+	if mods.Mirror > 0 {
+		s += "MR"
+	}
+
+	if mods.Random {
+		s += "RD"
+	}
+
+	if mods.DifficultyAdjust {
+		s += "DA"
+	}
+
+	if mods.Traceable {
+		s += "TC"
+	}
+
+	if mods.Wiggle {
+		s += "WG"
+	}
+
+	if mods.Transform {
+		s += "TR"
+	}
+
+	if mods.Grow {
+		s += "GR"
+	}
+
+	if mods.Deflate {
+		s += "DF"
+	}
+
+	if mods.ApproachDifferent {
+		s += "AD"
+	}
+
+	if mods.WindUp {
+		s += "WU"
+	}
+
+	if mods.WindDown {
+		s += "WD"
+	}
+
+	return
+}
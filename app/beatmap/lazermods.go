@@ -0,0 +1,148 @@
+package beatmap
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/framework/math/math32"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"log"
+	"math"
+	"math/rand"
+)
+
+const (
+	playfieldWidth    = 512.0
+	playfieldHeight   = 384.0
+	playfieldDiagonal = 640.0
+)
+
+// applyLazerMods applies osu!lazer mods that change positions of hit objects
+func applyLazerMods(beatMap *BeatMap) {
+	mods := beatMap.Diff.LazerMods
+	if mods == nil {
+		return
+	}
+
+	if mods.Random {
+		log.Println("Applying Random mod with seed:", mods.RandomSeed)
+
+		applyRandom(beatMap, mods)
+	}
+
+	if mods.Mirror > 0 {
+		for _, o := range beatMap.HitObjects {
+			o.TransformPositions(func(p vector.Vector2f) vector.Vector2f {
+				if mods.Mirror&difficulty.MirrorHorizontal > 0 {
+					p.X = playfieldWidth - p.X
+				}
+
+				if mods.Mirror&difficulty.MirrorVertical > 0 {
+					p.Y = playfieldHeight - p.Y
+				}
+
+				return p
+			})
+		}
+	}
+}
+
+// applyRandom repositions objects keeping distances between them but with randomized angles, similar to osu!lazer's Random mod.
+// Angle changes slower for short jumps so streams keep their shape.
+func applyRandom(beatMap *BeatMap, mods *difficulty.LazerMods) {
+	rng := rand.New(rand.NewSource(mods.RandomSeed))
+
+	prevEndOriginal := vector.NewVec2f(playfieldWidth/2, playfieldHeight/2)
+	prevEnd := prevEndOriginal
+
+	angle := 0.0
+	rateOfChange := 0.0
+	indexInCombo := 0
+	first := true
+
+	for _, o := range beatMap.HitObjects {
+		if o.GetType() == objects.SPINNER {
+			continue
+		}
+
+		if o.IsNewCombo() {
+			indexInCombo = 0
+		}
+
+		// Changes every 5 objects in a combo to prevent shaky streams
+		if indexInCombo%5 == 0 {
+			rateOfChange = rng.Float64()*2 - 1
+		}
+
+		indexInCombo++
+
+		startOriginal := o.GetStartPosition()
+		endOriginal := endPosition(o)
+
+		var distance float64
+
+		if first {
+			distance = rng.Float64() * playfieldHeight / 2
+			angle = rng.Float64()*2*math.Pi - math.Pi
+
+			first = false
+		} else {
+			distance = float64(startOriginal.Dst(prevEndOriginal))
+			angle += rateOfChange * 2 * math.Pi * math.Min(1, distance/(playfieldDiagonal*0.5)) * mods.RandomAngle
+		}
+
+		target := prevEnd.Add(vector.NewVec2fRad(float32(angle), float32(distance)))
+
+		offset := target.Sub(startOriginal)
+		o.TransformPositions(func(p vector.Vector2f) vector.Vector2f {
+			return p.Add(offset)
+		})
+
+		if shift := boundsShift(o); shift.Len() > 0 {
+			o.TransformPositions(func(p vector.Vector2f) vector.Vector2f {
+				return p.Add(shift)
+			})
+
+			// Continue from the direction the object actually ended up at
+			if movement := o.GetStartPosition().Sub(prevEnd); movement.Len() > 0.01 {
+				angle = float64(movement.AngleR())
+			}
+		}
+
+		prevEndOriginal = endOriginal
+		prevEnd = endPosition(o)
+	}
+}
+
+// endPosition returns the position the object really ends at, sliders with even number of spans end at their head
+func endPosition(o objects.IHitObject) vector.Vector2f {
+	if s, ok := o.(*objects.Slider); ok && s.RepeatCount%2 == 0 {
+		return s.GetStartPosition()
+	}
+
+	return o.GetEndPosition()
+}
+
+// boundsShift returns the shift needed to put the object back into the playfield
+func boundsShift(o objects.IHitObject) vector.Vector2f {
+	minP, maxP := o.GetStartPosition(), o.GetStartPosition()
+
+	if s, ok := o.(*objects.Slider); ok {
+		minP, maxP = s.GetPathBounds()
+	}
+
+	var shift vector.Vector2f
+
+	if minP.X < 0 {
+		shift.X = -minP.X
+	} else if maxP.X > playfieldWidth {
+		shift.X = math32.Max(playfieldWidth-maxP.X, -minP.X)
+	}
+
+	if minP.Y < 0 {
+		shift.Y = -minP.Y
+	} else if maxP.Y > playfieldHeight {
+		shift.Y = math32.Max(playfieldHeight-maxP.Y, -minP.Y)
+	}
+
+	return shift
+}
@@ -42,6 +42,9 @@ type Circle struct {
 	bounceStartTime float64
 	ArrowRotation   float64
 
+	// visualStartTime is the start time of the parent object, used by osu!lazer's visual mods
+	visualStartTime float64
+
 	SliderPoint      bool
 	SliderPointStart bool
 	SliderPointEnd   bool
@@ -138,11 +141,14 @@ func (circle *Circle) SetTiming(timings *Timings, _ bool) {
 
 func (circle *Circle) SetDifficulty(diff *difficulty.Difficulty) {
 	circle.diff = diff
+	circle.lastTime = 0
 
 	startTime := circle.StartTime - diff.Preempt
 
 	if circle.SliderPoint {
 		startTime = circle.appearTime
+	} else {
+		circle.visualStartTime = circle.StartTime
 	}
 
 	endTime := circle.StartTime
@@ -164,7 +170,7 @@ func (circle *Circle) SetDifficulty(diff *difficulty.Difficulty) {
 
 	circle.comboText = sprite.NewTextSpriteSize(strconv.Itoa(int(circle.ComboNumber)), skin.GetFont("default"), skin.GetFont("default").GetSize()*0.8, 0, vector.NewVec2d(0, 0), vector.Centre)
 
-	circle.sprites = append(circle.sprites, circle.hitCircle, circle.hitCircleOverlay, circle.comboText)
+	circle.sprites = []sprite.ISprite{circle.hitCircle, circle.hitCircleOverlay, circle.comboText}
 
 	circle.hitCircle.SetAlpha(0)
 	circle.hitCircleOverlay.SetAlpha(0)
@@ -173,7 +179,17 @@ func (circle *Circle) SetDifficulty(diff *difficulty.Difficulty) {
 
 	circles := []sprite.ISprite{circle.hitCircle, circle.hitCircleOverlay, circle.comboText}
 
+	isHead := !circle.SliderPoint || circle.SliderPointStart
+
 	for _, t := range circles {
+		if isHead && isTraceable(diff) {
+			continue // only approach circles are visible
+		}
+
+		if scale := lazerCircleScale(diff); isHead && scale != 1 {
+			t.AddTransform(animation.NewSingleTransform(animation.Scale, easing.Linear, startTime, endTime, scale, 1.0))
+		}
+
 		if diff.CheckModActive(difficulty.Hidden) {
 			if !circle.SliderPoint || circle.SliderPointStart || circle.firstEndCircle {
 				t.AddTransform(animation.NewSingleTransform(animation.Fade, easing.Linear, startTime, startTime+diff.Preempt*0.4, 0.0, 1.0))
@@ -216,7 +232,9 @@ func (circle *Circle) SetDifficulty(diff *difficulty.Difficulty) {
 			circle.approachCircle.AddTransform(animation.NewSingleTransform(animation.Fade, easing.Linear, startTime, math.Min(endTime, endTime-diff.Preempt+diff.TimeFadeIn*2), 0.0, 0.9))
 			circle.approachCircle.AddTransform(animation.NewSingleTransform(animation.Fade, easing.Linear, endTime, endTime, 0.0, 0.0))
 
-			circle.approachCircle.AddTransform(animation.NewSingleTransform(animation.Scale, easing.Linear, startTime, endTime, 4.0, 1.0))
+			approachScale, approachEasing := approachParameters(diff)
+
+			circle.approachCircle.AddTransform(animation.NewSingleTransform(animation.Scale, approachEasing, startTime, endTime, approachScale, 1.0))
 		}
 	}
 }
@@ -288,7 +306,7 @@ func (circle *Circle) UpdateStacking() {
 }
 
 func (circle *Circle) Draw(time float64, color color2.Color, batch *batch.QuadBatch) bool {
-	position := circle.GetStackedPositionAtMod(time, circle.diff.Mods)
+	position := circle.GetStackedPositionAtMod(time, circle.diff.Mods).Add(circle.visualOffset(time))
 
 	batch.SetSubScale(1, 1)
 	batch.SetTranslation(position.Copy64())
@@ -349,7 +367,7 @@ func (circle *Circle) DrawApproach(time float64, color color2.Color, batch *batc
 		return
 	}
 
-	position := circle.GetStackedPositionAtMod(time, circle.diff.Mods)
+	position := circle.GetStackedPositionAtMod(time, circle.diff.Mods).Add(circle.visualOffset(time))
 
 	batch.SetSubScale(1, 1)
 	batch.SetTranslation(position.Copy64())
@@ -360,6 +378,15 @@ func (circle *Circle) DrawApproach(time float64, color color2.Color, batch *batc
 	circle.approachCircle.Draw(time, batch)
 }
 
+func (circle *Circle) visualOffset(time float64) vector.Vector2f {
+	return lazerVisualOffset(circle.diff, circle.HitObjectID, circle.visualStartTime, time)
+}
+
 func (circle *Circle) GetType() Type {
 	return CIRCLE
 }
+
+// GetSample returns hitsound bits of the circle (2 - whistle, 4 - finish, 8 - clap)
+func (circle *Circle) GetSample() int {
+	return circle.sample
+}
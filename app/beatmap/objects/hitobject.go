@@ -47,6 +47,8 @@ type IHitObject interface {
 	GetType() Type
 
 	DisableAudioSubmission(value bool)
+
+	TransformPositions(transform func(vector.Vector2f) vector.Vector2f)
 }
 
 type ILongObject interface {
@@ -259,6 +261,12 @@ func (hitObject *HitObject) DisableAudioSubmission(value bool) {
 	hitObject.audioSubmissionDisabled = value
 }
 
+// TransformPositions moves the object using the given transformation, has to be called before SetTiming
+func (hitObject *HitObject) TransformPositions(transform func(vector.Vector2f) vector.Vector2f) {
+	hitObject.StartPosRaw = transform(hitObject.StartPosRaw)
+	hitObject.EndPosRaw = transform(hitObject.EndPosRaw)
+}
+
 func ModifyPosition(hitObject *HitObject, basePosition vector.Vector2f, modifier difficulty.Modifier) vector.Vector2f {
 	switch {
 	case modifier&difficulty.HardRock > 0:
@@ -0,0 +1,67 @@
+package objects
+
+import (
+	"github.com/wieku/danser-go/app/audio"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// HoldNote is an osu!mania long note, it doesn't have any visuals on its own as mania renderer takes care of that
+type HoldNote struct {
+	*HitObject
+
+	sample  int
+	Timings *Timings
+}
+
+func NewHoldNote(data []string) *HoldNote {
+	note := &HoldNote{
+		HitObject: commonParse(data, len(data)), // extras are prefixed with end time, so they have to be parsed separately
+	}
+
+	f, _ := strconv.ParseInt(data[4], 10, 64)
+	note.sample = int(f)
+
+	if len(data) > 5 {
+		extras := strings.SplitN(data[5], ":", 2)
+
+		endTime, _ := strconv.ParseFloat(extras[0], 64)
+		note.EndTime = math.Max(note.StartTime, endTime)
+
+		if len(extras) > 1 {
+			note.BasicHitSound = parseExtras(extras[1:], 0)
+		}
+	}
+
+	return note
+}
+
+func (note *HoldNote) SetTiming(timings *Timings, _ bool) {
+	note.Timings = timings
+}
+
+func (note *HoldNote) PlaySound() {
+	if note.audioSubmissionDisabled {
+		return
+	}
+
+	point := note.Timings.GetPointAt(note.StartTime)
+
+	index := note.BasicHitSound.CustomIndex
+	sampleSet := note.BasicHitSound.SampleSet
+
+	if index == 0 {
+		index = point.SampleIndex
+	}
+
+	if sampleSet == 0 {
+		sampleSet = point.SampleSet
+	}
+
+	audio.PlaySample(sampleSet, note.BasicHitSound.AdditionSet, note.sample, index, point.SampleVolume, note.HitObjectID, note.GetStartPosition().X64())
+}
+
+func (note *HoldNote) GetType() Type {
+	return LONGNOTE
+}
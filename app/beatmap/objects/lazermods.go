@@ -0,0 +1,119 @@
+package objects
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/framework/math/animation/easing"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+)
+
+const (
+	wiggleDuration    = 90.0
+	wiggleDistance    = 10.0
+	transformDistance = 250.0
+	transformStep     = 0.4
+)
+
+// lazerVisualOffset returns how much Wiggle and Transform mods move the object drawn at the given time.
+// Objects reach their real positions when they have to be hit, so judgements are not affected.
+func lazerVisualOffset(diff *difficulty.Difficulty, id int64, startTime, time float64) (offset vector.Vector2f) {
+	mods := diff.LazerMods
+	if mods == nil || time >= startTime || !(mods.Wiggle || mods.Transform) {
+		return
+	}
+
+	appearTime := startTime - diff.Preempt
+
+	if mods.Transform {
+		progress := mutils.ClampF((time-appearTime)/diff.Preempt, 0, 1)
+		theta := float32(id) * transformStep
+
+		offset = offset.Add(vector.NewVec2fRad(theta, float32(transformDistance*(1-progress))))
+	}
+
+	if mods.Wiggle {
+		step := math.Max(0, time-appearTime) / wiggleDuration
+		index := int64(step)
+
+		p1 := wigglePoint(id, index)
+		p2 := wigglePoint(id, index+1)
+
+		// Fade out the wiggle during the last step so the object doesn't jump when it's hit
+		strength := mods.WiggleStrength * wiggleDistance * mutils.ClampF((startTime-time)/wiggleDuration, 0, 1)
+
+		offset = offset.Add(p1.Lerp(p2, float32(easing.InOutSine(step-float64(index)))).Scl(float32(strength)))
+	}
+
+	return
+}
+
+// wigglePoint returns deterministic random point on a unit circle for the given object and wiggle step
+func wigglePoint(id, index int64) vector.Vector2f {
+	return vector.NewVec2fRad(float32(hash(uint64(id)<<32|uint64(index))*2*math.Pi), 1)
+}
+
+// hash is splitmix64 finalizer mapped to 0-1 range
+func hash(x uint64) float64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return float64(x>>11) / float64(1<<53)
+}
+
+// lazerCircleScale returns initial scale of hit circles set by Grow and Deflate mods
+func lazerCircleScale(diff *difficulty.Difficulty) float64 {
+	mods := diff.LazerMods
+
+	switch {
+	case mods == nil:
+		return 1
+	case mods.Grow:
+		return mods.GrowStartScale
+	case mods.Deflate:
+		return mods.DeflateStartScale
+	}
+
+	return 1
+}
+
+// approachParameters returns initial scale and easing of approach circles, changed by Approach Different mod
+func approachParameters(diff *difficulty.Difficulty) (float64, easing.Easing) {
+	mods := diff.LazerMods
+	if mods == nil || !mods.ApproachDifferent {
+		return 4.0, easing.Linear
+	}
+
+	var ease easing.Easing
+
+	switch mods.ApproachStyle {
+	case "gravity":
+		ease = easing.InBack
+	case "inout1":
+		ease = easing.InOutCubic
+	case "inout2":
+		ease = easing.InOutQuint
+	case "accelerate1":
+		ease = easing.InQuad
+	case "accelerate2":
+		ease = easing.InCubic
+	case "accelerate3":
+		ease = easing.InQuint
+	case "decelerate1":
+		ease = easing.OutQuad
+	case "decelerate2":
+		ease = easing.OutCubic
+	case "decelerate3":
+		ease = easing.OutQuint
+	default:
+		ease = easing.Linear
+	}
+
+	return mods.ApproachScale, ease
+}
+
+func isTraceable(diff *difficulty.Difficulty) bool {
+	return diff.LazerMods != nil && diff.LazerMods.Traceable
+}
@@ -88,6 +88,9 @@ type Slider struct {
 	EndTimeLazer     float64
 	ScorePointsLazer []TickPoint
 	spanDuration     float64
+
+	controlPoints []vector.Vector2f
+	curveType     string
 }
 
 func NewSlider(data []string) *Slider {
@@ -114,11 +117,12 @@ func NewSlider(data []string) *Slider {
 		points = append(points, vector.NewVec2f(float32(x), float32(y)))
 	}
 
-	slider.multiCurve = curves.NewMultiCurveT(list[0], points, slider.pixelLength)
+	slider.curveType = list[0]
+	slider.controlPoints = points
+
+	slider.createCurve()
 
 	slider.EndTime = slider.StartTime
-	slider.EndPosRaw = slider.multiCurve.PointAt(1.0)
-	slider.Pos = slider.StartPosRaw
 
 	slider.samples = make([]int, slider.RepeatCount+1)
 	slider.sampleSets = make([]int, slider.RepeatCount+1)
@@ -168,6 +172,44 @@ func NewSlider(data []string) *Slider {
 	return slider
 }
 
+func (slider *Slider) createCurve() {
+	if settings.Objects.Sliders.StablePaths {
+		slider.multiCurve = curves.NewMultiCurveStable(slider.curveType, slider.controlPoints, slider.pixelLength)
+	} else {
+		slider.multiCurve = curves.NewMultiCurveT(slider.curveType, slider.controlPoints, slider.pixelLength)
+	}
+
+	slider.StartPosRaw = slider.controlPoints[0]
+	slider.EndPosRaw = slider.multiCurve.PointAt(1.0)
+	slider.Pos = slider.StartPosRaw
+}
+
+// TransformPositions transforms control points of the slider and recreates its path, has to be called before SetTiming
+func (slider *Slider) TransformPositions(transform func(vector.Vector2f) vector.Vector2f) {
+	points := make([]vector.Vector2f, len(slider.controlPoints))
+	for i, p := range slider.controlPoints {
+		points[i] = transform(p)
+	}
+
+	slider.controlPoints = points
+
+	slider.createCurve()
+}
+
+// GetPathBounds returns corners of the bounding box of unstacked slider path
+func (slider *Slider) GetPathBounds() (vector.Vector2f, vector.Vector2f) {
+	minP, maxP := slider.StartPosRaw, slider.StartPosRaw
+
+	for _, line := range slider.multiCurve.GetLines() {
+		for _, p := range []vector.Vector2f{line.Point1, line.Point2} {
+			minP = vector.NewVec2f(math32.Min(minP.X, p.X), math32.Min(minP.Y, p.Y))
+			maxP = vector.NewVec2f(math32.Max(maxP.X, p.X), math32.Max(maxP.Y, p.Y))
+		}
+	}
+
+	return minP, maxP
+}
+
 func (slider *Slider) GetHalf() vector.Vector2f {
 	return slider.multiCurve.PointAt(0.5).Add(slider.StackOffset)
 }
@@ -453,6 +495,8 @@ func (slider *Slider) UpdateStacking() {
 
 func (slider *Slider) SetDifficulty(diff *difficulty.Difficulty) {
 	slider.diff = diff
+	slider.lastTime = 0
+	slider.isSliding = false
 	slider.sliderSnakeTail = animation.NewGlider(0)
 	slider.sliderSnakeHead = animation.NewGlider(0)
 
@@ -494,7 +538,10 @@ func (slider *Slider) SetDifficulty(diff *difficulty.Difficulty) {
 	slider.startCircle.StackOffsetEZ = slider.StackOffsetEZ
 	slider.startCircle.SetDifficulty(diff)
 
-	slider.edges = append(slider.edges, slider.startCircle)
+	slider.edges = []*Circle{slider.startCircle}
+	slider.endCircles = nil
+	slider.headEndCircles = nil
+	slider.tailEndCircles = nil
 
 	sixty := 1000.0 / 60
 	frameDelay := math.Max(150/slider.Timings.GetVelocity(slider.TPoint)*sixty, sixty)
@@ -536,6 +583,7 @@ func (slider *Slider) SetDifficulty(diff *difficulty.Difficulty) {
 		circle.StackOffsetEZ = slider.StackOffsetEZ
 		circle.SetTiming(slider.Timings, false)
 		circle.SetDifficulty(diff)
+		circle.visualStartTime = slider.StartTime
 
 		slider.endCircles = append(slider.endCircles, circle)
 		slider.edges = append(slider.edges, circle)
@@ -579,6 +627,10 @@ func (slider *Slider) SetDifficulty(diff *difficulty.Difficulty) {
 		slider.TickReverse[i] = p
 	}
 
+	if slider.body != nil {
+		slider.body.Dispose()
+	}
+
 	slider.body = sliderrenderer.NewBody(slider.multiCurve, diff.Mods&difficulty.HardRock > 0, float32(slider.diff.CircleRadius))
 }
 
@@ -894,7 +946,7 @@ func (slider *Slider) DrawBodyBase(_ float64, projection mgl32.Mat4) {
 	slider.body.DrawBase(slider.sliderSnakeHead.GetValue(), slider.sliderSnakeTail.GetValue(), projection)
 }
 
-func (slider *Slider) DrawBody(_ float64, bodyColor, innerBorder, outerBorder color2.Color, projection mgl32.Mat4, scale float32) {
+func (slider *Slider) DrawBody(time float64, bodyColor, innerBorder, outerBorder color2.Color, projection mgl32.Mat4, scale float32) {
 	colorAlpha := slider.bodyFade.GetValue() * float64(bodyColor.A)
 
 	bodyOpacityInner := mutils.ClampF(float32(settings.Objects.Colors.Sliders.Body.InnerAlpha), 0.0, 1.0)
@@ -945,6 +997,11 @@ func (slider *Slider) DrawBody(_ float64, bodyColor, innerBorder, outerBorder co
 	bodyInner.A = float32(colorAlpha) * bodyOpacityInner
 	bodyOuter.A = float32(colorAlpha) * bodyOpacityOuter
 
+	if isTraceable(slider.diff) { // only the border is visible
+		bodyInner.A = 0
+		bodyOuter.A = 0
+	}
+
 	stackOffset := slider.StackOffset
 	if slider.diff.Mods&difficulty.HardRock > 0 {
 		stackOffset = slider.StackOffsetHR
@@ -952,6 +1009,8 @@ func (slider *Slider) DrawBody(_ float64, bodyColor, innerBorder, outerBorder co
 		stackOffset = slider.StackOffsetEZ
 	}
 
+	stackOffset = stackOffset.Add(lazerVisualOffset(slider.diff, slider.HitObjectID, slider.StartTime, time))
+
 	slider.body.DrawNormal(projection, stackOffset, scale, bodyInner, bodyOuter, borderInner, borderOuter)
 }
 
@@ -975,11 +1034,13 @@ func (slider *Slider) Draw(time float64, color color2.Color, batch *batch.QuadBa
 
 				scorePoint := skin.GetTexture("sliderscorepoint")
 
+				offset := lazerVisualOffset(slider.diff, slider.HitObjectID, slider.StartTime, time)
+
 				for _, p := range slider.TickPoints {
 					al := p.fade.GetValue()
 
 					if al > 0.001 {
-						batch.SetTranslation(p.Pos.Copy64())
+						batch.SetTranslation(p.Pos.Add(offset).Copy64())
 						batch.SetSubScale(p.scale.GetValue(), p.scale.GetValue())
 
 						if settings.Objects.Colors.Sliders.WhiteScorePoints || settings.Skin.UseColorsFromSkin {
@@ -1087,3 +1148,22 @@ func (slider *Slider) DrawApproach(time float64, color color2.Color, batch *batc
 func (slider *Slider) GetType() Type {
 	return SLIDER
 }
+
+func (slider *Slider) GetPixelLength() float64 {
+	return slider.pixelLength
+}
+
+// GetBaseSample returns hitsound bits of the whole slider
+func (slider *Slider) GetBaseSample() int {
+	return slider.baseSample
+}
+
+// GetEdgeSamples returns hitsound bits of the head, repeats and the tail
+func (slider *Slider) GetEdgeSamples() []int {
+	return slider.samples
+}
+
+// GetControlPoints returns path points as they're stored in .osu file, the first one is slider's head
+func (slider *Slider) GetControlPoints() []vector.Vector2f {
+	return slider.controlPoints
+}
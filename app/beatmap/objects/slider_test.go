@@ -0,0 +1,80 @@
+package objects
+
+import (
+	"encoding/json"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"os"
+	"strings"
+	"testing"
+)
+
+const stableSliderTolerance = 0.5
+
+type stableSliderFixture struct {
+	Name             string     `json:"name"`
+	Data             string     `json:"data"`
+	SliderMultiplier float64    `json:"sliderMultiplier"`
+	TickRate         float64    `json:"tickRate"`
+	BeatLength       float64    `json:"beatLength"`
+	SliderVelocity   float64    `json:"sliderVelocity"`
+	End              [2]float32 `json:"end"`
+	EndTime          float64    `json:"endTime"`
+	Ticks            []float64  `json:"ticks"`
+}
+
+func loadStableSliderFixtures(t *testing.T) []stableSliderFixture {
+	data, err := os.ReadFile("testdata/stable_sliders.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fixtures []stableSliderFixture
+	if err = json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	return fixtures
+}
+
+func TestStableSliderPaths(t *testing.T) {
+	settings.Objects.Sliders.StablePaths = true
+	defer func() {
+		settings.Objects.Sliders.StablePaths = false
+	}()
+
+	for _, fixture := range loadStableSliderFixtures(t) {
+		t.Run(fixture.Name, func(t *testing.T) {
+			timings := NewTimings()
+			timings.SliderMult = fixture.SliderMultiplier
+			timings.TickRate = fixture.TickRate
+
+			timings.AddPoint(0, fixture.BeatLength, 0, 1, 1, 4, false, false, false)
+			timings.AddPoint(0, -100/fixture.SliderVelocity, 0, 1, 1, 4, true, false, false)
+			timings.FinalizePoints()
+
+			slider := NewSlider(strings.Split(fixture.Data, ","))
+			slider.SetTiming(timings, false)
+
+			if slider.EndTime != fixture.EndTime {
+				t.Errorf("expected end time %.0f, got %.0f", fixture.EndTime, slider.EndTime)
+			}
+
+			expected := vector.NewVec2f(fixture.End[0], fixture.End[1])
+
+			if end := slider.EndPosRaw; end.Dst(expected) > stableSliderTolerance {
+				t.Errorf("expected end at %s, got %s", expected.String(), end.String())
+			}
+
+			if len(slider.TickPoints) != len(fixture.Ticks) {
+				t.Fatalf("expected %d ticks, got %d", len(fixture.Ticks), len(slider.TickPoints))
+			}
+
+			for i, tick := range slider.TickPoints {
+				if tick.Time != fixture.Ticks[i] {
+					t.Errorf("expected tick %d at %.0f, got %.0f", i, fixture.Ticks[i], tick.Time)
+				}
+			}
+		})
+	}
+}
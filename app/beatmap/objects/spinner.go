@@ -94,6 +94,7 @@ func (spinner *Spinner) UpdateStacking() {}
 
 func (spinner *Spinner) SetDifficulty(diff *difficulty.Difficulty) {
 	spinner.diff = diff
+	spinner.lastTime = 0
 
 	spinner.ScaledHeight = 768
 	spinner.ScaledWidth = settings.Graphics.GetAspectRatio() * spinner.ScaledHeight
@@ -17,6 +17,8 @@ func CreateObject(data []string) IHitObject {
 		}
 	} else if (objType & SLIDER) > 0 {
 		return NewSlider(data)
+	} else if (objType & LONGNOTE) > 0 {
+		return NewHoldNote(data)
 	}
 
 	return nil
@@ -29,5 +31,5 @@ const (
 	SLIDER
 	NEWCOMBO
 	SPINNER
-	LONGNOTE = Type(128) //only for mania, used to have correct number of sliders in database just in case
+	LONGNOTE = Type(128) //only for mania
 )
@@ -377,6 +377,8 @@ func ParseObjects(beatMap *BeatMap, diffCalcOnly, parseColors bool) {
 		num++
 	}
 
+	applyLazerMods(beatMap)
+
 	for _, obj := range beatMap.HitObjects {
 		obj.SetTiming(beatMap.Timings, diffCalcOnly)
 	}
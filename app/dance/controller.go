@@ -1,14 +1,18 @@
 package dance
 
 import (
+	"fmt"
 	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
 	"github.com/wieku/danser-go/app/beatmap/objects"
 	"github.com/wieku/danser-go/app/dance/movers"
 	"github.com/wieku/danser-go/app/dance/schedulers"
 	"github.com/wieku/danser-go/app/dance/spinners"
 	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/replay"
+	"github.com/wieku/danser-go/app/rulesets/osu"
 	"github.com/wieku/danser-go/app/settings"
-	"strings"
+	"time"
 )
 
 type Controller interface {
@@ -22,6 +26,37 @@ type GenericController struct {
 	bMap       *beatmap.BeatMap
 	cursors    []*graphics.Cursor
 	schedulers []schedulers.Scheduler
+
+	exportCursor *graphics.Cursor
+	ruleset      *osu.OsuRuleSet
+	recorder     *replay.Recorder
+	frameTicker  *frameTicker
+}
+
+// frameTicker marks 60fps replay frames, counting from the time of the first update
+type frameTicker struct {
+	lastTime float64
+	counter  float64
+	started  bool
+}
+
+// tick returns true if replay frame should be recorded at given time
+func (ticker *frameTicker) tick(time float64) bool {
+	if !ticker.started {
+		ticker.started = true
+		ticker.lastTime = time
+		ticker.counter = 1000.0 / 60 // First update is always a replay frame
+	}
+
+	ticker.counter += time - ticker.lastTime
+	ticker.lastTime = time
+
+	if ticker.counter >= 1000.0/60 {
+		ticker.counter -= 1000.0 / 60
+		return true
+	}
+
+	return false
 }
 
 func NewGenericController() Controller {
@@ -44,36 +79,18 @@ func (controller *GenericController) InitCursors() {
 
 		mover := "flower"
 		if len(settings.CursorDance.Movers) > 0 {
-			mover = strings.ToLower(settings.CursorDance.Movers[i%len(settings.CursorDance.Movers)].Mover)
+			mover = settings.CursorDance.Movers[i%len(settings.CursorDance.Movers)].Mover
 		}
 
-		var moverCtor func() movers.MultiPointMover
-
-		switch mover {
-		case "spline":
-			moverCtor = movers.NewSplineMover
-		case "bezier":
-			moverCtor = movers.NewBezierMover
-		case "circular":
-			moverCtor = movers.NewHalfCircleMover
-		case "linear":
-			moverCtor = movers.NewLinearMover
-		case "axis":
-			moverCtor = movers.NewAxisMover
-		case "exgon":
-			moverCtor = movers.NewExGonMover
-		case "aggressive":
-			moverCtor = movers.NewAggressiveMover
-		case "momentum":
-			moverCtor = movers.NewMomentumMover
-		case "pippi":
-			moverCtor = movers.NewPippiMover
-		default:
-			moverCtor = movers.NewAngleOffsetMover
-			mover = "flower"
+		moverCtor, mover := movers.GetMoverCtorByName(mover)
+
+		// Rule movers get ids the same way, counting cursors that used their mover before
+		moverIDs := make(map[string]int, len(counter))
+		for name, id := range counter {
+			moverIDs[name] = id
 		}
 
-		controller.schedulers[i] = schedulers.NewGenericScheduler(moverCtor, i, counter[mover])
+		controller.schedulers[i] = schedulers.NewGenericSchedulerWithRules(moverCtor, i, counter[mover], moverIDs, controller.bMap.Timings)
 
 		counter[mover]++
 	}
@@ -114,19 +131,28 @@ func (controller *GenericController) InitCursors() {
 	}
 
 	// If DoSpinnersTogether is true with tag mode, allow all tag cursors to spin the same spinner with different movers
-	for j, o := range queue {
+	sharedSpinner := func(o objects.IHitObject) bool {
 		_, isSpinner := o.(*objects.Spinner)
+		return isSpinner && settings.CursorDance.DoSpinnersTogether
+	}
 
-		if (isSpinner && settings.CursorDance.DoSpinnersTogether) || settings.CursorDance.Battle {
-			for i := range queues {
+	if settings.TAG > 1 && settings.CursorDance.TAGAssignment == "optimized" && !settings.CursorDance.ComboTag && !settings.CursorDance.Battle {
+		for i, objs := range assignOptimized(queue, settings.TAG, controller.bMap.Diff, sharedSpinner) {
+			queues[i].hitObjects = objs
+		}
+	} else {
+		for j, o := range queue {
+			if sharedSpinner(o) || settings.CursorDance.Battle {
+				for i := range queues {
+					queues[i].hitObjects = append(queues[i].hitObjects, o)
+				}
+			} else if settings.CursorDance.ComboTag {
+				i := int(o.GetComboSet()) % settings.TAG
+				queues[i].hitObjects = append(queues[i].hitObjects, o)
+			} else {
+				i := j % settings.TAG
 				queues[i].hitObjects = append(queues[i].hitObjects, o)
 			}
-		} else if settings.CursorDance.ComboTag {
-			i := int(o.GetComboSet()) % settings.TAG
-			queues[i].hitObjects = append(queues[i].hitObjects, o)
-		} else {
-			i := j % settings.TAG
-			queues[i].hitObjects = append(queues[i].hitObjects, o)
 		}
 	}
 
@@ -139,6 +165,25 @@ func (controller *GenericController) InitCursors() {
 
 		controller.schedulers[i].Init(queues[i].hitObjects, controller.bMap.Diff, controller.cursors[i], spinners.GetMoverCtorByName(spinMover), true)
 	}
+
+	if settings.EXPORTREPLAY {
+		controller.initExport()
+	}
+}
+
+func (controller *GenericController) initExport() {
+	if settings.EXPORTCURSOR < 1 || settings.EXPORTCURSOR > len(controller.cursors) {
+		panic(fmt.Sprintf("Invalid cursor selected for replay export: %d, available cursors: 1-%d", settings.EXPORTCURSOR, len(controller.cursors)))
+	}
+
+	controller.exportCursor = controller.cursors[settings.EXPORTCURSOR-1]
+	controller.exportCursor.ScoreTime = time.Now()
+
+	controller.ruleset = osu.NewOsuRuleset(controller.bMap, []*graphics.Cursor{controller.exportCursor}, []difficulty.Modifier{controller.bMap.Diff.Mods})
+	controller.ruleset.SetVisualFeedback(false) // cursordance handles object animations and hitsounds by itself
+	controller.recorder = replay.NewRecorder(controller.bMap, controller.exportCursor, controller.ruleset)
+	controller.recorder.Username = "danser"
+	controller.frameTicker = &frameTicker{}
 }
 
 func (controller *GenericController) Update(time float64, delta float64) {
@@ -149,6 +194,32 @@ func (controller *GenericController) Update(time float64, delta float64) {
 		controller.cursors[i].LeftButton = controller.cursors[i].LeftKey || controller.cursors[i].LeftMouse
 		controller.cursors[i].RightButton = controller.cursors[i].RightKey || controller.cursors[i].RightMouse
 	}
+
+	if controller.recorder != nil {
+		controller.updateExport(time)
+	}
+}
+
+func (controller *GenericController) updateExport(time float64) {
+	controller.exportCursor.IsReplayFrame = controller.frameTicker.tick(time)
+
+	controller.ruleset.UpdateClickFor(controller.exportCursor, int64(time))
+	controller.ruleset.UpdateNormalFor(controller.exportCursor, int64(time), false)
+	controller.ruleset.UpdatePostFor(controller.exportCursor, int64(time), false)
+	controller.ruleset.Update(int64(time))
+
+	controller.recorder.Update(int64(time))
+
+	if controller.ruleset.IsEnded() {
+		controller.recorder.Save()
+	}
+}
+
+// Stop saves the exported replay if the map was quit before its end
+func (controller *GenericController) Stop() {
+	if controller.recorder != nil {
+		controller.recorder.Save()
+	}
 }
 
 func (controller *GenericController) GetCursors() []*graphics.Cursor {
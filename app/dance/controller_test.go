@@ -0,0 +1,48 @@
+package dance
+
+import (
+	"testing"
+)
+
+// countReplayFrames updates the ticker every millisecond between start and end
+func countReplayFrames(ticker *frameTicker, start, end float64) (frames []float64) {
+	for t := start; t < end; t++ {
+		if ticker.tick(t) {
+			frames = append(frames, t)
+		}
+	}
+
+	return
+}
+
+func checkFrameSpacing(t *testing.T, frames []float64, start float64) {
+	if len(frames) == 0 || frames[0] != start {
+		t.Fatalf("first replay frame should be at %.0f, got %v", start, frames)
+	}
+
+	for i := 1; i < len(frames); i++ {
+		if diff := frames[i] - frames[i-1]; diff < 16 || diff > 17 {
+			t.Fatalf("replay frames at %.0f and %.0f are %.0fms apart", frames[i-1], frames[i], diff)
+		}
+	}
+}
+
+func TestFrameTickerNegativeLeadIn(t *testing.T) {
+	frames := countReplayFrames(&frameTicker{}, -1800, 1000)
+
+	checkFrameSpacing(t, frames, -1800)
+
+	if expected := 2800 * 60 / 1000; len(frames) < expected-1 || len(frames) > expected+1 {
+		t.Fatalf("expected about %d replay frames, got %d", expected, len(frames))
+	}
+}
+
+func TestFrameTickerLateStart(t *testing.T) {
+	frames := countReplayFrames(&frameTicker{}, 60000, 61000)
+
+	checkFrameSpacing(t, frames, 60000)
+
+	if expected := 1000 * 60 / 1000; len(frames) < expected-1 || len(frames) > expected+1 {
+		t.Fatalf("expected about %d replay frames, got %d", expected, len(frames))
+	}
+}
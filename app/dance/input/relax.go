@@ -43,3 +43,11 @@ func (processor *RelaxInputProcessor) Update(time float64) {
 		processor.wasLeft = !processor.wasLeft
 	}
 }
+
+func (processor *RelaxInputProcessor) WasLeft() bool {
+	return processor.wasLeft
+}
+
+func (processor *RelaxInputProcessor) SetWasLeft(wasLeft bool) {
+	processor.wasLeft = wasLeft
+}
@@ -0,0 +1,168 @@
+package dance
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/replay"
+	"github.com/wieku/danser-go/app/rulesets/catch"
+	"github.com/wieku/danser-go/app/rulesets/mania"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/rulesets/taiko"
+	"github.com/wieku/rplpa"
+)
+
+// modePlayer judges a single player in game modes other than osu!standard, every player has its own ruleset
+type modePlayer interface {
+	// loadFrames converts replay frames cleaned with replay.CleanFrames to game mode's input
+	loadFrames(frames []*rplpa.ReplayData)
+	loadAutoplay()
+	update(time float64)
+	getScore() (accuracy float64, combo uint, grade osu.Grade)
+	getRuleset() any
+}
+
+// newModePlayer creates a player for the given game mode, muted players don't play hitsounds
+func newModePlayer(mode int64, bMap *beatmap.BeatMap, mods difficulty.Modifier, muted bool) modePlayer {
+	switch mode {
+	case beatmap.ModeMania:
+		ruleset := mania.NewManiaRuleset(bMap, mods)
+		if muted {
+			ruleset.Mute()
+		}
+
+		return &maniaPlayer{ruleset: ruleset}
+	case beatmap.ModeTaiko:
+		ruleset := taiko.NewTaikoRuleset(bMap, mods)
+		if muted {
+			ruleset.Mute()
+		}
+
+		return &taikoPlayer{ruleset: ruleset}
+	case beatmap.ModeCatch: // osu!catch ruleset doesn't play hitsounds
+		return &catchPlayer{ruleset: catch.NewCatchRuleset(bMap, mods)}
+	}
+
+	panic(fmt.Sprintf("Game mode %d is not supported", mode))
+}
+
+type maniaPlayer struct {
+	ruleset *mania.ManiaRuleset
+	frames  []mania.KeyFrame
+	index   int
+}
+
+func (player *maniaPlayer) loadFrames(frames []*rplpa.ReplayData) {
+	// osu!mania replays store held columns as bits of x coordinate
+	player.frames = replay.ConvertFrames(frames, func(time float64, frame *rplpa.ReplayData) mania.KeyFrame {
+		return mania.KeyFrame{Time: time, Keys: uint32(frame.MouseX)}
+	})
+}
+
+func (player *maniaPlayer) loadAutoplay() {
+	player.frames = mania.GenerateAutoplay(player.ruleset.GetNotes(), player.ruleset.GetKeys())
+}
+
+func (player *maniaPlayer) update(time float64) {
+	for ; player.index < len(player.frames) && player.frames[player.index].Time <= time; player.index++ {
+		frame := player.frames[player.index]
+
+		player.ruleset.UpdateKeys(frame.Time, frame.Keys)
+	}
+
+	player.ruleset.Update(time)
+}
+
+func (player *maniaPlayer) getScore() (float64, uint, osu.Grade) {
+	score := player.ruleset.GetScore()
+	return score.Accuracy, score.Combo, score.Grade
+}
+
+func (player *maniaPlayer) getRuleset() any {
+	return player.ruleset
+}
+
+type taikoPlayer struct {
+	ruleset *taiko.TaikoRuleset
+	frames  []taiko.KeyFrame
+	index   int
+}
+
+func (player *taikoPlayer) loadFrames(frames []*rplpa.ReplayData) {
+	player.frames = replay.ConvertFrames(frames, func(time float64, frame *rplpa.ReplayData) taiko.KeyFrame {
+		return taiko.KeyFrame{Time: time, Keys: taiko.ConvertKeys(frame.KeyPressed)}
+	})
+}
+
+func (player *taikoPlayer) loadAutoplay() {
+	player.frames = taiko.GenerateAutoplay(player.ruleset.GetObjects(), player.ruleset.GetHitWindows())
+}
+
+func (player *taikoPlayer) update(time float64) {
+	for ; player.index < len(player.frames) && player.frames[player.index].Time <= time; player.index++ {
+		frame := player.frames[player.index]
+
+		player.ruleset.UpdateKeys(frame.Time, frame.Keys)
+	}
+
+	player.ruleset.Update(time)
+}
+
+func (player *taikoPlayer) getScore() (float64, uint, osu.Grade) {
+	score := player.ruleset.GetScore()
+	return score.Accuracy, score.Combo, score.Grade
+}
+
+func (player *taikoPlayer) getRuleset() any {
+	return player.ruleset
+}
+
+type catchPlayer struct {
+	ruleset *catch.CatchRuleset
+	frames  []catch.Frame
+	index   int
+}
+
+func (player *catchPlayer) loadFrames(frames []*rplpa.ReplayData) {
+	// osu!catch replays store catcher's position as x coordinate
+	player.frames = replay.ConvertFrames(frames, func(time float64, frame *rplpa.ReplayData) catch.Frame {
+		return catch.Frame{
+			Time:    time,
+			X:       float64(frame.MouseX),
+			Dashing: frame.KeyPressed != nil && (frame.KeyPressed.LeftClick || frame.KeyPressed.Key1),
+		}
+	})
+}
+
+func (player *catchPlayer) loadAutoplay() {
+	player.frames = catch.GenerateAutoplay(player.ruleset.GetObjects())
+}
+
+func (player *catchPlayer) update(time float64) {
+	for player.index < len(player.frames)-1 && player.frames[player.index+1].Time <= time {
+		frame := player.frames[player.index+1]
+
+		// Objects are judged at every frame to not skip fast catcher movements between updates
+		player.ruleset.Update(frame.Time, frame.X, frame.Dashing)
+
+		player.index++
+	}
+
+	index := player.index
+	if len(player.frames) > 0 && player.frames[0].Time > time {
+		index = -1
+	}
+
+	x, dashing := catch.PositionAt(player.frames, index, time)
+
+	player.ruleset.Update(time, x, dashing)
+}
+
+func (player *catchPlayer) getScore() (float64, uint, osu.Grade) {
+	score := player.ruleset.GetScore()
+	return score.Accuracy, score.Combo, score.Grade
+}
+
+func (player *catchPlayer) getRuleset() any {
+	return player.ruleset
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/wieku/danser-go/app/beatmap/difficulty"
 	"github.com/wieku/danser-go/app/beatmap/objects"
 	"github.com/wieku/danser-go/framework/math/vector"
+	"strings"
 )
 
 const sixtyTime = 1000.0 / 60
@@ -17,6 +18,36 @@ type MultiPointMover interface {
 	GetEndTime() float64
 }
 
+// GetMoverCtorByName returns constructor of the mover and its name, unknown movers resolve to flower
+func GetMoverCtorByName(name string) (func() MultiPointMover, string) {
+	name = strings.ToLower(name)
+
+	switch name {
+	case "spline":
+		return NewSplineMover, name
+	case "bezier":
+		return NewBezierMover, name
+	case "circular":
+		return NewHalfCircleMover, name
+	case "linear":
+		return NewLinearMover, name
+	case "axis":
+		return NewAxisMover, name
+	case "exgon":
+		return NewExGonMover, name
+	case "aggressive":
+		return NewAggressiveMover, name
+	case "momentum":
+		return NewMomentumMover, name
+	case "pippi":
+		return NewPippiMover, name
+	case "script":
+		return NewScriptMover, name
+	}
+
+	return NewAngleOffsetMover, "flower"
+}
+
 type basicMover struct {
 	startTime float64
 	endTime float64
@@ -0,0 +1,230 @@
+package movers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/env"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/math/vector"
+	starlarkmath "go.starlark.net/lib/math"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"log"
+	"path/filepath"
+)
+
+// maxScriptSteps limits a single move() call so broken scripts can't freeze danser
+const maxScriptSteps = 1000000
+
+// ScriptMover calls move(ctx, time) function from user's Starlark script to get cursor position.
+//
+// ctx fields:
+//
+//	start, end - objects the cursor moves between
+//	prev, next - objects before start and after end, None if there's none
+//	start_time, end_time - time span of the movement
+//	diff - difficulty with ar, od, cs, hp, preempt, radius and speed fields
+//	id - mover's id, useful for varying patterns between tag cursors
+//
+// object fields: type, start_x, start_y, end_x, end_y, start_time, end_time, start_angle, end_angle (None for non-sliders), velocity (osu!pixels/s, 0 for non-sliders), new_combo
+//
+// move has to return (x, y) tuple or list, math module is available in the script.
+type ScriptMover struct {
+	*basicMover
+
+	file string
+	move starlark.Callable
+
+	failed bool
+
+	diffValue starlark.Value
+	last      starlark.Value
+	ctx       starlark.Value
+
+	startPos vector.Vector2f
+	endPos   vector.Vector2f
+}
+
+func NewScriptMover() MultiPointMover {
+	return &ScriptMover{basicMover: &basicMover{}}
+}
+
+func (mover *ScriptMover) Reset(diff *difficulty.Difficulty, id int) {
+	mover.basicMover.Reset(diff, id)
+
+	mover.move = nil
+	mover.failed = false
+	mover.last = starlark.None
+
+	config := settings.CursorDance.MoverSettings.Script[id%len(settings.CursorDance.MoverSettings.Script)]
+
+	mover.file = config.File
+	if !filepath.IsAbs(mover.file) {
+		mover.file = filepath.Join(env.ConfigDir(), mover.file)
+	}
+
+	globals, err := starlark.ExecFile(mover.newThread(), mover.file, nil, starlark.StringDict{"math": starlarkmath.Module})
+	if err != nil {
+		mover.fail("failed to load the script", err)
+		return
+	}
+
+	move, ok := globals["move"].(starlark.Callable)
+	if !ok {
+		mover.fail("failed to load the script", errors.New("move(ctx, time) function is not defined"))
+		return
+	}
+
+	mover.move = move
+
+	mover.diffValue = starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"ar":      starlark.Float(diff.ARReal),
+		"od":      starlark.Float(diff.ODReal),
+		"cs":      starlark.Float(diff.GetCS()),
+		"hp":      starlark.Float(diff.GetHP()),
+		"preempt": starlark.Float(diff.Preempt),
+		"radius":  starlark.Float(diff.CircleRadius),
+		"speed":   starlark.Float(diff.Speed),
+	})
+}
+
+func (mover *ScriptMover) SetObjects(objs []objects.IHitObject) int {
+	start, end := objs[0], objs[1]
+
+	mover.startTime = start.GetEndTime()
+	mover.endTime = end.GetStartTime()
+
+	mover.startPos = start.GetStackedEndPositionMod(mover.diff.Mods)
+	mover.endPos = end.GetStackedStartPositionMod(mover.diff.Mods)
+
+	if mover.move == nil {
+		return 2
+	}
+
+	startValue := mover.objectValue(start)
+
+	var next starlark.Value = starlark.None
+	if len(objs) > 2 {
+		next = mover.objectValue(objs[2])
+	}
+
+	mover.ctx = starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"start":      startValue,
+		"end":        mover.objectValue(end),
+		"prev":       mover.last,
+		"next":       next,
+		"start_time": starlark.Float(mover.startTime),
+		"end_time":   starlark.Float(mover.endTime),
+		"diff":       mover.diffValue,
+		"id":         starlark.MakeInt(mover.id),
+	})
+
+	mover.last = startValue
+
+	return 2
+}
+
+func (mover *ScriptMover) Update(time float64) vector.Vector2f {
+	if mover.move == nil || mover.failed {
+		t := mutils.ClampF((time-mover.startTime)/(mover.endTime-mover.startTime), 0, 1)
+		return mover.startPos.Lerp(mover.endPos, float32(t))
+	}
+
+	result, err := starlark.Call(mover.newThread(), mover.move, starlark.Tuple{mover.ctx, starlark.Float(time)}, nil)
+	if err != nil {
+		mover.fail("move() failed", err)
+		return mover.Update(time)
+	}
+
+	pos, err := toPosition(result)
+	if err != nil {
+		mover.fail("move() returned invalid value", err)
+		return mover.Update(time)
+	}
+
+	return pos
+}
+
+func (mover *ScriptMover) objectValue(o objects.IHitObject) starlark.Value {
+	typ := "circle"
+
+	switch o.GetType() {
+	case objects.SLIDER:
+		typ = "slider"
+	case objects.SPINNER:
+		typ = "spinner"
+	}
+
+	startPos := o.GetStackedStartPositionMod(mover.diff.Mods)
+	endPos := o.GetStackedEndPositionMod(mover.diff.Mods)
+
+	var startAngle, endAngle starlark.Value = starlark.None, starlark.None
+
+	velocity := 0.0
+
+	if s, ok := o.(*objects.Slider); ok {
+		startAngle = starlark.Float(s.GetStartAngleMod(mover.diff.Mods))
+		endAngle = starlark.Float(s.GetEndAngleMod(mover.diff.Mods))
+		velocity = s.Timings.GetVelocity(s.TPoint)
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"type":        starlark.String(typ),
+		"start_x":     starlark.Float(startPos.X),
+		"start_y":     starlark.Float(startPos.Y),
+		"end_x":       starlark.Float(endPos.X),
+		"end_y":       starlark.Float(endPos.Y),
+		"start_time":  starlark.Float(o.GetStartTime()),
+		"end_time":    starlark.Float(o.GetEndTime()),
+		"start_angle": startAngle,
+		"end_angle":   endAngle,
+		"velocity":    starlark.Float(velocity),
+		"new_combo":   starlark.Bool(o.IsNewCombo()),
+	})
+}
+
+func (mover *ScriptMover) newThread() *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: "mover",
+		Print: func(_ *starlark.Thread, msg string) {
+			log.Println("ScriptMover:", msg)
+		},
+	}
+
+	thread.SetMaxExecutionSteps(maxScriptSteps)
+
+	return thread
+}
+
+// fail reports script error and switches the mover to linear movement
+func (mover *ScriptMover) fail(reason string, err error) {
+	mover.failed = true
+
+	var evalErr *starlark.EvalError
+	if errors.As(err, &evalErr) {
+		log.Println(fmt.Sprintf("ScriptMover: %s, %s:\n%s", mover.file, reason, evalErr.Backtrace()))
+	} else {
+		log.Println(fmt.Sprintf("ScriptMover: %s, %s: %s", mover.file, reason, err))
+	}
+
+	log.Println("ScriptMover: Falling back to linear movement")
+}
+
+func toPosition(value starlark.Value) (vector.Vector2f, error) {
+	seq, ok := value.(starlark.Indexable)
+	if !ok || seq.Len() != 2 {
+		return vector.Vector2f{}, fmt.Errorf("expected (x, y), got %s", value.String())
+	}
+
+	x, okX := starlark.AsFloat(seq.Index(0))
+	y, okY := starlark.AsFloat(seq.Index(1))
+
+	if !okX || !okY {
+		return vector.Vector2f{}, fmt.Errorf("expected numbers in (x, y), got %s", value.String())
+	}
+
+	return vector.NewVec2f(float32(x), float32(y)), nil
+}
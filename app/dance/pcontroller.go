@@ -11,6 +11,7 @@ import (
 	"github.com/wieku/danser-go/app/dance/spinners"
 	"github.com/wieku/danser-go/app/graphics"
 	input2 "github.com/wieku/danser-go/app/input"
+	"github.com/wieku/danser-go/app/replay"
 	"github.com/wieku/danser-go/app/rulesets/osu"
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/app/utils"
@@ -39,6 +40,8 @@ type PlayerController struct {
 
 	quickRestart     bool
 	quickRestartTime float64
+
+	recorder *replay.Recorder
 }
 
 func NewPlayerController() Controller {
@@ -57,6 +60,10 @@ func (controller *PlayerController) InitCursors() {
 	controller.window = glfw.GetCurrentContext()
 	controller.ruleset = osu.NewOsuRuleset(controller.bMap, controller.cursors, []difficulty.Modifier{controller.bMap.Diff.Mods})
 
+	if settings.Gameplay.SavePlayReplays {
+		controller.recorder = replay.NewRecorder(controller.bMap, controller.cursors[0], controller.ruleset)
+	}
+
 	if !controller.bMap.Diff.CheckModActive(difficulty.Relax) {
 		input2.RegisterListener(controller.KeyEvent)
 	} else {
@@ -168,11 +175,26 @@ func (controller *PlayerController) Update(time float64, delta float64) {
 	controller.ruleset.UpdatePostFor(controller.cursors[0], int64(time), false)
 	controller.ruleset.Update(int64(time))
 
+	if controller.recorder != nil {
+		controller.recorder.Update(int64(time))
+
+		if controller.ruleset.IsEnded() {
+			controller.recorder.Save()
+		}
+	}
+
 	controller.lastTime = time
 
 	controller.cursors[0].Update(delta)
 }
 
+// Stop saves the replay of unfinished play, so quitting or failing doesn't lose it
+func (controller *PlayerController) Stop() {
+	if controller.recorder != nil {
+		controller.recorder.Save()
+	}
+}
+
 func (controller *PlayerController) GetRuleset() *osu.OsuRuleSet {
 	return controller.ruleset
 }
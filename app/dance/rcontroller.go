@@ -3,10 +3,6 @@ package dance
 import (
 	"fmt"
 	"github.com/karrick/godirwalk"
-	"github.com/wieku/danser-go/app/dance/input"
-	"github.com/wieku/danser-go/app/dance/movers"
-	"github.com/wieku/danser-go/app/dance/schedulers"
-	"github.com/wieku/danser-go/app/dance/spinners"
 	"github.com/wieku/danser-go/framework/env"
 	"github.com/wieku/danser-go/framework/math/mutils"
 	"github.com/wieku/rplpa"
@@ -17,10 +13,10 @@ import (
 	"github.com/wieku/danser-go/app/beatmap"
 	"github.com/wieku/danser-go/app/beatmap/difficulty"
 	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/replay"
 	"github.com/wieku/danser-go/app/rulesets/osu"
 	"github.com/wieku/danser-go/app/settings"
 	//"github.com/wieku/danser-go/app/utils"
-	"github.com/wieku/danser-go/framework/math/math32"
 	"github.com/wieku/danser-go/framework/math/vector"
 	"io/ioutil"
 	"log"
@@ -35,6 +31,12 @@ import (
 
 const replaysMaster = "replays"
 
+// snapshotInterval is the initial time between snapshots used to move replays back in time
+const snapshotInterval = 2000.0
+
+// maxSnapshots limits memory used by snapshots, when it's exceeded every other snapshot is dropped and the interval is doubled
+const maxSnapshots = 128
+
 type RpData struct {
 	Name      string
 	Mods      string
@@ -49,14 +51,11 @@ type RpData struct {
 
 type subControl struct {
 	danceController Controller
-	replayIndex     int
-	replayTime      int64
 	frames          []*rplpa.ReplayData
-	newHandling     bool
+	osuVersion      int32
+	processor       *replay.FrameProcessor
+	player          modePlayer
 	lastTime        int64
-	oldSpinners     bool
-	relaxController *input.RelaxInputProcessor
-	mouseController schedulers.Scheduler
 	mods            difficulty.Modifier
 }
 
@@ -65,38 +64,78 @@ func NewSubControl() *subControl {
 	return control
 }
 
+type cursorState struct {
+	position         vector.Vector2f
+	lastFrameTime    int64
+	currentFrameTime int64
+	isReplayFrame    bool
+	leftKey          bool
+	rightKey         bool
+	leftMouse        bool
+	rightMouse       bool
+	leftButton       bool
+	rightButton      bool
+	smokeKey         bool
+}
+
+type controlState struct {
+	replayIndex int
+	replayTime  int64
+	lastTime    int64
+	wasLeft     bool
+	cursor      cursorState
+}
+
+type replaySnapshot struct {
+	time     float64
+	ruleset  *osu.Snapshot
+	controls []controlState
+}
+
+// ReplayFrameInfo describes the last replay frame processed for a player
+type ReplayFrameInfo struct {
+	Frame    *rplpa.ReplayData // nil if no frame was processed yet
+	Index    int
+	Time     int64 // absolute time of the frame
+	PrevTime int64 // absolute time of the previous frame
+	NextTime int64 // absolute time of the next frame, -1 if it's the last one
+}
+
 type ReplayController struct {
 	bMap        *beatmap.BeatMap
+	mode        int64
 	replays     []RpData
 	cursors     []*graphics.Cursor
 	controllers []*subControl
 	ruleset     *osu.OsuRuleSet
 	lastTime    float64
+
+	rewindable       bool
+	snapshots        []*replaySnapshot
+	snapshotInterval float64
 }
 
 func NewReplayController() Controller {
 	_ = os.MkdirAll(filepath.Join(env.DataDir(), replaysMaster), 0755)
 
-	return &ReplayController{lastTime: -200}
+	return &ReplayController{lastTime: -200, snapshotInterval: snapshotInterval}
 }
 
 func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
 	controller.bMap = beatMap
 
+	controller.mode = beatMap.Mode
+	if controller.mode == beatmap.ModeOsu {
+		controller.mode = settings.PLAYMODE
+	}
+
 	organizeReplays()
 
 	candidates := make([]*rplpa.Replay, 0)
 
 	localReplay := false
 	if settings.REPLAY != "" {
-		log.Println("Loading: ", settings.REPLAY)
-
-		data, err := ioutil.ReadFile(settings.REPLAY)
-		if err != nil {
-			panic(err)
-		}
-
-		replayD, _ := rplpa.ParseReplay(data)
+		replayD := loadLocalReplay()
 
 		if replayD.ReplayData == nil || len(replayD.ReplayData) == 0 {
 			log.Println("Excluding for missing input data:", replayD.Username)
@@ -105,7 +144,7 @@ func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
 
 			localReplay = true
 		}
-	} else if settings.Knockout.MaxPlayers > 0 || (settings.KNOCKOUTREPLAYS != nil && len(settings.KNOCKOUTREPLAYS) > 0) { // ignore max player limit with new knockout
+	} else if settings.KNOCKOUT && (settings.Knockout.MaxPlayers > 0 || (settings.KNOCKOUTREPLAYS != nil && len(settings.KNOCKOUTREPLAYS) > 0)) { // ignore max player limit with new knockout
 		candidates = controller.getCandidates()
 	}
 
@@ -133,8 +172,7 @@ func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
 
 		mxCombo := replay.MaxCombo
 
-		control.newHandling = replay.OsuVersion >= 20190506 // This was when slider scoring was changed, so *I think* replay handling as well: https://osu.ppy.sh/home/changelog/cuttingedge/20190506
-		control.oldSpinners = replay.OsuVersion < 20190510  // This was when spinner scoring was changed: https://osu.ppy.sh/home/changelog/cuttingedge/20190510.2
+		control.osuVersion = replay.OsuVersion
 
 		controller.replays = append(controller.replays, RpData{replay.Username + string(rune(unicode.MaxRune-i)), (control.mods & displayedMods).String(), control.mods, 100, 0, int64(mxCombo), osu.NONE, replay.ScoreID, replay.Timestamp})
 		controller.controllers = append(controller.controllers, control)
@@ -147,8 +185,11 @@ func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
 		control := NewSubControl()
 		control.mods = difficulty.Autoplay | beatMap.Diff.Mods
 
-		control.danceController = NewGenericController()
-		control.danceController.SetBeatMap(beatMap)
+		// Other game modes generate autoplay input in InitCursors
+		if controller.mode == beatmap.ModeOsu {
+			control.danceController = NewGenericController()
+			control.danceController.SetBeatMap(beatMap)
+		}
 
 		controller.replays = append([]RpData{{settings.Knockout.DanserName, control.mods.String(), control.mods, 100, 0, 0, osu.NONE, -1, time.Now()}}, controller.replays...)
 		controller.controllers = append([]*subControl{control}, controller.controllers...)
@@ -161,6 +202,23 @@ func (controller *ReplayController) SetBeatMap(beatMap *beatmap.BeatMap) {
 	settings.PLAYERS = len(controller.replays)
 }
 
+// loadLocalReplay loads the replay given by -replay flag
+func loadLocalReplay() *rplpa.Replay {
+	log.Println("Loading: ", settings.REPLAY)
+
+	data, err := ioutil.ReadFile(settings.REPLAY)
+	if err != nil {
+		panic(err)
+	}
+
+	replay, err := rplpa.ParseReplay(data)
+	if err != nil {
+		panic(err)
+	}
+
+	return replay
+}
+
 func organizeReplays() {
 	replayDir := filepath.Join(env.DataDir(), replaysMaster)
 
@@ -231,6 +289,11 @@ func (controller *ReplayController) getCandidates() (candidates []*rplpa.Replay)
 			return
 		}
 
+		if int64(replayD.PlayMode) != controller.mode {
+			log.Println("Excluding for different game mode:", replayD.Username)
+			return
+		}
+
 		if !difficulty.Modifier(replayD.Mods).Compatible() || difficulty.Modifier(replayD.Mods).Active(difficulty.Target) {
 			log.Println("Excluding for incompatible mods:", replayD.Username)
 			return
@@ -277,18 +340,7 @@ func (controller *ReplayController) getCandidates() (candidates []*rplpa.Replay)
 }
 
 func loadFrames(subController *subControl, frames []*rplpa.ReplayData) {
-	// Remove mania seed frame if its present
-	for i, frame := range frames {
-		if frame.Time == -12345 {
-			frames = append(frames[:i], frames[i+1:]...)
-			break
-		}
-	}
-
-	// Remove incorrect first frame if its delta is 0
-	if frames[0].Time == 0 {
-		frames = frames[1:]
-	}
+	frames = replay.CleanFrames(frames)
 
 	times := make([]float64, 0, len(frames))
 
@@ -332,6 +384,11 @@ func (controller *ReplayController) InitCursors() {
 	var modifiers []difficulty.Modifier
 
 	for i, c := range controller.controllers {
+		if controller.mode != beatmap.ModeOsu {
+			controller.cursors = append(controller.cursors, controller.initModePlayer(i, c))
+			continue
+		}
+
 		if controller.controllers[i].danceController != nil {
 			controller.controllers[i].danceController.InitCursors()
 
@@ -352,14 +409,10 @@ func (controller *ReplayController) InitCursors() {
 			cursor.Name = controller.replays[i].Name
 			cursor.ScoreID = controller.replays[i].scoreID
 			cursor.ScoreTime = controller.replays[i].ScoreTime
-			cursor.OldSpinnerScoring = controller.controllers[i].oldSpinners
 
-			cursor.SetPos(vector.NewVec2f(c.frames[0].MouseX, c.frames[0].MouseY))
+			c.processor = replay.NewFrameProcessor(cursor, c.mods, c.frames, c.osuVersion)
 			cursor.Update(0)
 
-			c.replayTime += c.frames[0].Time
-			c.frames = c.frames[1:]
-
 			controller.cursors = append(controller.cursors, cursor)
 		}
 
@@ -370,26 +423,62 @@ func (controller *ReplayController) InitCursors() {
 		modifiers = append(modifiers, controller.replays[i].ModsV)
 	}
 
+	// Other game modes have separate rulesets for every player and can't be moved back in time
+	if controller.mode != beatmap.ModeOsu {
+		return
+	}
+
 	controller.ruleset = osu.NewOsuRuleset(controller.bMap, controller.cursors, modifiers)
 
-	for i := range controller.controllers {
-		if controller.replays[i].ModsV.Active(difficulty.Relax) {
-			controller.controllers[i].relaxController = input.NewRelaxInputProcessor(controller.ruleset, controller.cursors[i])
+	for _, c := range controller.controllers {
+		if c.processor != nil {
+			c.processor.InitInput(controller.ruleset, controller.bMap)
 		}
+	}
 
-		if controller.replays[i].ModsV.Active(difficulty.Relax2) {
-			controller.controllers[i].mouseController = schedulers.NewGenericScheduler(movers.NewLinearMoverSimple, 0, 0)
-
-			diff := difficulty.NewDifficulty(controller.bMap.Diff.GetHP(), controller.bMap.Diff.GetCS(), controller.bMap.Diff.GetOD(), controller.bMap.Diff.GetAR())
-			diff.SetMods(controller.replays[i].ModsV)
-			diff.SetCustomSpeed(controller.bMap.Diff.CustomSpeed)
+	// Cursor dancing can't be moved back in time and there's no way to seek while recording
+	controller.rewindable = !settings.RECORD
 
-			controller.controllers[i].mouseController.Init(controller.bMap.GetObjectsCopy(), diff, controller.cursors[i], spinners.GetMoverCtorByName("circle"), false)
+	for _, c := range controller.controllers {
+		if c.danceController != nil {
+			controller.rewindable = false
 		}
 	}
+
+	if controller.rewindable {
+		controller.ruleset.EnableRewinding()
+	}
+}
+
+// initModePlayer creates the player and its cursor in game modes other than osu!standard, only the first player plays hitsounds
+func (controller *ReplayController) initModePlayer(i int, c *subControl) *graphics.Cursor {
+	cursor := graphics.NewCursor()
+	cursor.SetPos(vector.NewVec2f(256, 192)) // Cursor is not drawn in other game modes, but it's kept in the middle to not move the background
+	cursor.Name = controller.replays[i].Name
+	cursor.ScoreID = controller.replays[i].scoreID
+	cursor.ScoreTime = controller.replays[i].ScoreTime
+	cursor.Update(0)
+
+	c.player = newModePlayer(controller.mode, controller.bMap, c.mods, i > 0)
+
+	if c.frames != nil {
+		c.player.loadFrames(c.frames)
+	} else {
+		cursor.IsPlayer = true
+		cursor.IsAutoplay = true
+
+		c.player.loadAutoplay()
+	}
+
+	return cursor
 }
 
 func (controller *ReplayController) Update(time float64, delta float64) {
+	if controller.mode != beatmap.ModeOsu {
+		controller.updateModePlayers(time)
+		return
+	}
+
 	numSkipped := int(time-controller.lastTime) - 1
 
 	if numSkipped >= 1 {
@@ -412,6 +501,21 @@ func (controller *ReplayController) Update(time float64, delta float64) {
 	}
 }
 
+// updateModePlayers updates players in game modes other than osu!standard, their rulesets judge input at exact frame times
+func (controller *ReplayController) updateModePlayers(time float64) {
+	for i, c := range controller.controllers {
+		c.player.update(time)
+
+		accuracy, combo, grade := c.player.getScore()
+
+		controller.replays[i].Accuracy = accuracy
+		controller.replays[i].Combo = int64(combo)
+		controller.replays[i].Grade = grade
+	}
+
+	controller.lastTime = time
+}
+
 func (controller *ReplayController) updateMain(nTime float64) {
 	controller.bMap.Update(nTime)
 
@@ -435,110 +539,148 @@ func (controller *ReplayController) updateMain(nTime float64) {
 
 			c.lastTime = int64(nTime)
 		} else {
-			wasUpdated := false
+			if !c.processor.Update(controller.ruleset, int64(nTime)) {
+				c.processor.Interpolate(nTime)
+			}
+		}
+	}
 
-			isRelax := (controller.replays[i].ModsV & difficulty.Relax) > 0
-			isAutopilot := (controller.replays[i].ModsV & difficulty.Relax2) > 0
+	if int64(nTime) != int64(controller.lastTime) {
+		controller.ruleset.Update(int64(nTime))
+	}
 
-			if isAutopilot {
-				c.mouseController.Update(nTime)
-			}
+	controller.lastTime = nTime
 
-			if c.replayIndex < len(c.frames) {
-				for c.replayIndex < len(c.frames) && c.replayTime+c.frames[c.replayIndex].Time <= int64(nTime) {
-					frame := c.frames[c.replayIndex]
-					c.replayTime += frame.Time
+	if controller.rewindable && (len(controller.snapshots) == 0 || nTime >= controller.snapshots[len(controller.snapshots)-1].time+controller.snapshotInterval) {
+		controller.takeSnapshot(nTime)
+	}
+}
 
-					// If next frame is not in the next millisecond, assume it's -36ms slider end
-					processAhead := true
-					if c.replayIndex+1 < len(c.frames) && c.frames[c.replayIndex+1].Time == 1 {
-						processAhead = false
-					}
+func (controller *ReplayController) takeSnapshot(time float64) {
+	snapshot := &replaySnapshot{
+		time:     time,
+		ruleset:  controller.ruleset.CreateSnapshot(),
+		controls: make([]controlState, len(controller.controllers)),
+	}
 
-					if !isAutopilot {
-						controller.cursors[i].SetPos(vector.NewVec2f(frame.MouseX, frame.MouseY))
-					}
+	for i, c := range controller.controllers {
+		cursor := controller.cursors[i]
+
+		snapshot.controls[i] = controlState{
+			lastTime: c.lastTime,
+			cursor: cursorState{
+				position:         cursor.RawPosition,
+				lastFrameTime:    cursor.LastFrameTime,
+				currentFrameTime: cursor.CurrentFrameTime,
+				isReplayFrame:    cursor.IsReplayFrame,
+				leftKey:          cursor.LeftKey,
+				rightKey:         cursor.RightKey,
+				leftMouse:        cursor.LeftMouse,
+				rightMouse:       cursor.RightMouse,
+				leftButton:       cursor.LeftButton,
+				rightButton:      cursor.RightButton,
+				smokeKey:         cursor.SmokeKey,
+			},
+		}
 
-					controller.cursors[i].LastFrameTime = controller.cursors[i].CurrentFrameTime
-					controller.cursors[i].CurrentFrameTime = c.replayTime
-					controller.cursors[i].IsReplayFrame = true
+		if c.processor != nil {
+			snapshot.controls[i].replayIndex = c.processor.Index
+			snapshot.controls[i].replayTime = c.processor.Time
 
-					if !isRelax {
-						controller.cursors[i].LeftKey = frame.KeyPressed.LeftClick && frame.KeyPressed.Key1
-						controller.cursors[i].RightKey = frame.KeyPressed.RightClick && frame.KeyPressed.Key2
+			if c.processor.Relax != nil {
+				snapshot.controls[i].wasLeft = c.processor.Relax.WasLeft()
+			}
+		}
+	}
 
-						controller.cursors[i].LeftMouse = frame.KeyPressed.LeftClick && !frame.KeyPressed.Key1
-						controller.cursors[i].RightMouse = frame.KeyPressed.RightClick && !frame.KeyPressed.Key2
+	controller.snapshots = append(controller.snapshots, snapshot)
 
-						controller.cursors[i].LeftButton = frame.KeyPressed.LeftClick
-						controller.cursors[i].RightButton = frame.KeyPressed.RightClick
-					} else {
-						c.relaxController.Update(float64(c.replayTime))
-					}
+	if len(controller.snapshots) > maxSnapshots {
+		compacted := controller.snapshots[:0]
 
-					controller.cursors[i].SmokeKey = frame.KeyPressed.Smoke
+		for i, s := range controller.snapshots {
+			if i%2 == 0 {
+				compacted = append(compacted, s)
+			}
+		}
 
-					controller.ruleset.UpdateClickFor(controller.cursors[i], c.replayTime)
-					controller.ruleset.UpdateNormalFor(controller.cursors[i], c.replayTime, processAhead)
+		for i := len(compacted); i < len(controller.snapshots); i++ {
+			controller.snapshots[i] = nil
+		}
 
-					// New replays (after 20190506) scores object ends only on replay frame
-					if c.newHandling || c.replayIndex == len(c.frames)-1 {
-						controller.ruleset.UpdatePostFor(controller.cursors[i], c.replayTime, processAhead)
-					} else {
-						localIndex := mutils.Clamp(c.replayIndex+1, 0, len(c.frames)-1)
-						localFrame := c.frames[localIndex]
+		controller.snapshots = compacted
+		controller.snapshotInterval *= 2
+	}
+}
 
-						// HACK for older replays: update object ends till the next frame
-						for localTime := c.replayTime; localTime < c.replayTime+localFrame.Time; localTime++ {
-							controller.ruleset.UpdatePostFor(controller.cursors[i], localTime, false)
-						}
-					}
+// CanRewind returns true if replays can be moved back in time with Rewind
+func (controller *ReplayController) CanRewind() bool {
+	return controller.rewindable && len(controller.snapshots) > 0
+}
 
-					wasUpdated = true
+// Rewind restores the state from the latest snapshot taken before the given time.
+// Replays are simulated up to the given time during the next Update call.
+func (controller *ReplayController) Rewind(time float64) {
+	if !controller.CanRewind() {
+		return
+	}
 
-					c.replayIndex++
-				}
+	index := sort.Search(len(controller.snapshots), func(i int) bool {
+		return controller.snapshots[i].time > time
+	})
 
-				if !wasUpdated {
-					if !isAutopilot {
-						localIndex := mutils.Clamp(c.replayIndex, 0, len(c.frames)-1)
+	snapshot := controller.snapshots[mutils.Max(index-1, 0)]
 
-						progress := math32.Min(float32(nTime-float64(c.replayTime)), float32(c.frames[localIndex].Time)) / float32(c.frames[localIndex].Time)
+	// Moving forward from the current state is cheaper than from the snapshot
+	if time >= controller.lastTime && snapshot.time <= controller.lastTime {
+		return
+	}
 
-						prevIndex := mutils.Max(0, localIndex-1)
+	controller.ruleset.RestoreSnapshot(snapshot.ruleset)
 
-						mX := (c.frames[localIndex].MouseX-c.frames[prevIndex].MouseX)*progress + c.frames[prevIndex].MouseX
-						mY := (c.frames[localIndex].MouseY-c.frames[prevIndex].MouseY)*progress + c.frames[prevIndex].MouseY
+	for i, c := range controller.controllers {
+		state := snapshot.controls[i]
 
-						controller.cursors[i].SetPos(vector.NewVec2f(mX, mY))
-					}
+		c.lastTime = state.lastTime
 
-					controller.cursors[i].IsReplayFrame = false
-				}
+		if c.processor != nil {
+			c.processor.Index = state.replayIndex
+			c.processor.Time = state.replayTime
 
-				if c.replayIndex >= len(c.frames) {
-					controller.ruleset.PlayerStopped(controller.cursors[i], c.replayTime)
-				}
-			} else {
-				controller.cursors[i].LeftKey = false
-				controller.cursors[i].RightKey = false
-				controller.cursors[i].LeftMouse = false
-				controller.cursors[i].RightMouse = false
-				controller.cursors[i].LeftButton = false
-				controller.cursors[i].RightButton = false
+			if c.processor.Relax != nil {
+				c.processor.Relax.SetWasLeft(state.wasLeft)
+			}
 
-				controller.ruleset.UpdateClickFor(controller.cursors[i], int64(nTime))
-				controller.ruleset.UpdateNormalFor(controller.cursors[i], int64(nTime), false)
-				controller.ruleset.UpdatePostFor(controller.cursors[i], int64(nTime), false)
+			// Autopilot movement depends on all previous objects, so it has to be built again
+			if c.processor.Autopilot != nil {
+				c.processor.InitAutopilot(controller.bMap)
 			}
 		}
+
+		cursor := controller.cursors[i]
+
+		cursor.SetPos(state.cursor.position)
+		cursor.LastFrameTime = state.cursor.lastFrameTime
+		cursor.CurrentFrameTime = state.cursor.currentFrameTime
+		cursor.IsReplayFrame = state.cursor.isReplayFrame
+		cursor.LeftKey = state.cursor.leftKey
+		cursor.RightKey = state.cursor.rightKey
+		cursor.LeftMouse = state.cursor.leftMouse
+		cursor.RightMouse = state.cursor.rightMouse
+		cursor.LeftButton = state.cursor.leftButton
+		cursor.RightButton = state.cursor.rightButton
+		cursor.SmokeKey = state.cursor.smokeKey
 	}
 
-	if int64(nTime) != int64(controller.lastTime) {
-		controller.ruleset.Update(int64(nTime))
+	controller.lastTime = snapshot.time
+}
+
+func (controller *ReplayController) GetFirstSnapshotTime() float64 {
+	if len(controller.snapshots) == 0 {
+		return controller.lastTime
 	}
 
-	controller.lastTime = nTime
+	return controller.snapshots[0].time
 }
 
 func (controller *ReplayController) GetCursors() []*graphics.Cursor {
@@ -553,10 +695,44 @@ func (controller *ReplayController) GetRuleset() *osu.OsuRuleSet {
 	return controller.ruleset
 }
 
+// GetModeRuleset returns the ruleset of the first player in game modes other than osu!standard, nil in osu!standard
+func (controller *ReplayController) GetModeRuleset() any {
+	if controller.mode == beatmap.ModeOsu || len(controller.controllers) == 0 {
+		return nil
+	}
+
+	return controller.controllers[0].player.getRuleset()
+}
+
 func (controller *ReplayController) GetBeatMap() *beatmap.BeatMap {
 	return controller.bMap
 }
 
+// GetReplayFrame returns the last replay frame processed for the given player, ok is false for players without replay data
+func (controller *ReplayController) GetReplayFrame(player int) (info ReplayFrameInfo, ok bool) {
+	p := controller.controllers[player].processor
+
+	if p == nil || len(p.Frames) == 0 {
+		return
+	}
+
+	info.Index = p.Index - 1
+	info.Time = p.Time
+	info.PrevTime = p.Time
+	info.NextTime = -1
+
+	if info.Index >= 0 {
+		info.Frame = p.Frames[info.Index]
+		info.PrevTime = p.Time - info.Frame.Time
+	}
+
+	if p.Index < len(p.Frames) {
+		info.NextTime = p.Time + p.Frames[p.Index].Time
+	}
+
+	return info, true
+}
+
 func (controller *ReplayController) GetClick(player, key int) bool {
 	switch key {
 	case 0:
@@ -22,12 +22,23 @@ type GenericScheduler struct {
 	diff     *difficulty.Difficulty
 	index    int
 	id       int
+
+	timings      *objects.Timings
+	moverIDs     map[string]int
+	defaultMover movers.MultiPointMover
+	ruleMovers   map[string]movers.MultiPointMover
 }
 
 func NewGenericScheduler(mover func() movers.MultiPointMover, index, id int) Scheduler {
 	return &GenericScheduler{mover: mover(), index: index, id: id}
 }
 
+// NewGenericSchedulerWithRules creates a scheduler that switches movers according to mover rules of the cursor,
+// moverIDs holds ids of rule movers by mover name
+func NewGenericSchedulerWithRules(mover func() movers.MultiPointMover, index, id int, moverIDs map[string]int, timings *objects.Timings) Scheduler {
+	return &GenericScheduler{mover: mover(), index: index, id: id, moverIDs: moverIDs, timings: timings}
+}
+
 func (scheduler *GenericScheduler) Init(objs []objects.IHitObject, diff *difficulty.Difficulty, cursor *graphics.Cursor, spinnerMoverCtor func() spinners.SpinnerMover, initKeys bool) {
 	scheduler.diff = diff
 	scheduler.cursor = cursor
@@ -37,6 +48,8 @@ func (scheduler *GenericScheduler) Init(objs []objects.IHitObject, diff *difficu
 		scheduler.input = input.NewNaturalInputProcessor(objs, cursor)
 	}
 
+	scheduler.initRules()
+
 	scheduler.mover.Reset(diff, scheduler.id)
 
 	config := settings.CursorDance.Movers[scheduler.index%len(settings.CursorDance.Movers)]
@@ -89,7 +102,7 @@ func (scheduler *GenericScheduler) Init(objs []objects.IHitObject, diff *difficu
 	scheduler.cursor.SetPos(vector.NewVec2f(100, 100))
 	scheduler.cursor.Update(0)
 
-	toRemove := scheduler.mover.SetObjects(scheduler.queue) - 1
+	toRemove := scheduler.setObjects(scheduler.queue) - 1
 	scheduler.queue = scheduler.queue[toRemove:]
 }
 
@@ -129,7 +142,7 @@ func (scheduler *GenericScheduler) Update(time float64) {
 				toRemove := 1
 
 				if upperLimit-i > 1 {
-					toRemove = scheduler.mover.SetObjects(scheduler.queue[i:upperLimit]) - 1
+					toRemove = scheduler.setObjects(scheduler.queue[i:upperLimit]) - 1
 				}
 
 				scheduler.queue = append(scheduler.queue[:i], scheduler.queue[i+toRemove:]...)
@@ -0,0 +1,104 @@
+package schedulers
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/dance/movers"
+	"github.com/wieku/danser-go/app/settings"
+)
+
+func (scheduler *GenericScheduler) initRules() {
+	if scheduler.defaultMover == nil {
+		scheduler.defaultMover = scheduler.mover
+	}
+
+	scheduler.mover = scheduler.defaultMover
+	scheduler.ruleMovers = nil
+
+	if scheduler.timings == nil {
+		return
+	}
+
+	config := settings.CursorDance.Movers[scheduler.index%len(settings.CursorDance.Movers)]
+
+	for _, rule := range config.Rules {
+		if scheduler.ruleMovers == nil {
+			scheduler.ruleMovers = make(map[string]movers.MultiPointMover)
+		}
+
+		ctor, name := movers.GetMoverCtorByName(rule.Mover)
+
+		if _, ok := scheduler.ruleMovers[name]; !ok {
+			mover := ctor()
+			mover.Reset(scheduler.diff, scheduler.moverIDs[name])
+
+			scheduler.ruleMovers[name] = mover
+		}
+	}
+}
+
+// setObjects picks the mover for the next movement and passes objects to it.
+// Every movement starts where the previous one ended, but only the position carries over between movers,
+// so a mover taking over from another one is reset instead of continuing from its stale state.
+func (scheduler *GenericScheduler) setObjects(objs []objects.IHitObject) int {
+	mover, id := scheduler.selectMover(objs[0], objs[1])
+
+	if mover != scheduler.mover {
+		mover.Reset(scheduler.diff, id)
+	}
+
+	scheduler.mover = mover
+
+	return scheduler.mover.SetObjects(objs)
+}
+
+// selectMover returns the mover for the movement between given objects and its id
+func (scheduler *GenericScheduler) selectMover(start, end objects.IHitObject) (movers.MultiPointMover, int) {
+	if scheduler.ruleMovers == nil {
+		return scheduler.defaultMover, scheduler.id
+	}
+
+	config := settings.CursorDance.Movers[scheduler.index%len(settings.CursorDance.Movers)]
+
+	for _, rule := range config.Rules {
+		if scheduler.matchesRule(rule.Condition, rule.From, rule.To, start, end) {
+			_, name := movers.GetMoverCtorByName(rule.Mover)
+
+			return scheduler.ruleMovers[name], scheduler.moverIDs[name]
+		}
+	}
+
+	return scheduler.defaultMover, scheduler.id
+}
+
+func (scheduler *GenericScheduler) matchesRule(condition string, from, to float64, start, end objects.IHitObject) bool {
+	time := end.GetStartTime()
+	point := scheduler.timings.GetPointAt(time)
+
+	inRange := func(value float64) bool {
+		return value >= from && value <= to
+	}
+
+	switch condition {
+	case "time":
+		return inRange(time)
+	case "kiai":
+		return point.Kiai
+	case "bpm":
+		return inRange(60000 / point.GetBaseBeatLength() * scheduler.diff.Speed)
+	case "spacing":
+		return inRange(float64(start.GetStackedEndPositionMod(scheduler.diff.Mods).Dst(end.GetStackedStartPositionMod(scheduler.diff.Mods))))
+	case "stream":
+		return end.GetType() == objects.CIRCLE && !isSliderPoint(end) && time-start.GetEndTime() <= point.GetBaseBeatLength()/4+1
+	case "slider":
+		return end.GetType() == objects.SLIDER || isSliderPoint(end)
+	case "circle":
+		return end.GetType() == objects.CIRCLE && !isSliderPoint(end)
+	}
+
+	return false
+}
+
+func isSliderPoint(o objects.IHitObject) bool {
+	c, ok := o.(*objects.Circle)
+	return ok && c.SliderPoint
+}
@@ -24,6 +24,8 @@ func GetMoverByName(name string) SpinnerMover {
 		return NewSquareMover()
 	case "cube":
 		return NewCubeMover()
+	case "svg":
+		return NewSVGMover()
 	default:
 		return NewCircleMover()
 	}
@@ -0,0 +1,220 @@
+package spinners
+
+import (
+	"encoding/xml"
+	"errors"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/env"
+	"github.com/wieku/danser-go/framework/math/curves"
+	"github.com/wieku/danser-go/framework/math/math32"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type svgShape struct {
+	points  []vector.Vector2f
+	lengths []float32 // cumulative length at each point
+}
+
+// Parsed shapes are shared between cursors and spinners
+var svgCache = make(map[string]*svgShape)
+
+type SVGMover struct {
+	start float64
+	id    int
+
+	shape    *svgShape
+	fallback *CircleMover
+}
+
+func NewSVGMover() *SVGMover {
+	return &SVGMover{}
+}
+
+func (c *SVGMover) Init(start, end float64, id int) {
+	c.start = start
+	c.id = id
+
+	config := settings.CursorDance.Spinners[id%len(settings.CursorDance.Spinners)]
+
+	shape, ok := svgCache[config.SVG]
+	if !ok {
+		var err error
+
+		if shape, err = loadSVGShape(config.SVG); err != nil {
+			log.Println("SVGMover: Failed to load SVG, falling back to circle:", err)
+		}
+
+		svgCache[config.SVG] = shape
+	}
+
+	c.shape = shape
+
+	if c.shape == nil {
+		c.fallback = NewCircleMover()
+		c.fallback.Init(start, end, id)
+	}
+}
+
+func (c *SVGMover) GetPositionAt(time float64) vector.Vector2f {
+	if c.shape == nil {
+		return c.fallback.GetPositionAt(time)
+	}
+
+	config := settings.CursorDance.Spinners[c.id%len(settings.CursorDance.Spinners)]
+
+	speed := config.SVGSpeed
+	if speed <= 0 {
+		speed = 5000
+	}
+
+	elapsed := float32(time - c.start)
+
+	// Shape is normalized to unit radius so trace distance has to be scaled down
+	distance := math32.Mod(elapsed*float32(speed/math.Max(config.Radius, 1))/1000, c.shape.length())
+	if distance < 0 {
+		distance += c.shape.length()
+	}
+
+	rotation := elapsed * float32(config.SVGRotation) / 60000 * 2 * math32.Pi
+
+	return c.shape.pointAt(distance).Rotate(rotation).Scl(float32(config.Radius)).Add(center)
+}
+
+func (shape *svgShape) length() float32 {
+	return shape.lengths[len(shape.lengths)-1]
+}
+
+func (shape *svgShape) pointAt(distance float32) vector.Vector2f {
+	i := sort.Search(len(shape.lengths), func(i int) bool {
+		return shape.lengths[i] >= distance
+	})
+
+	if i == 0 {
+		return shape.points[0]
+	}
+
+	segmentLength := shape.lengths[i] - shape.lengths[i-1]
+	if segmentLength == 0 {
+		return shape.points[i]
+	}
+
+	return shape.points[i-1].Lerp(shape.points[i], (distance-shape.lengths[i-1])/segmentLength)
+}
+
+// loadSVGShape reads SVG path data or paths of .svg file and joins them into one closed loop that fits in a unit circle
+func loadSVGShape(source string) (*svgShape, error) {
+	source = strings.TrimSpace(source)
+
+	pathData := []string{source}
+
+	if strings.HasSuffix(strings.ToLower(source), ".svg") {
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(env.DataDir(), source)
+		}
+
+		var err error
+		if pathData, err = readSVGPaths(source); err != nil {
+			return nil, err
+		}
+	}
+
+	var points []vector.Vector2f
+
+	for _, data := range pathData {
+		paths, err := curves.ParseSVGPath(data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			points = append(points, path...)
+		}
+	}
+
+	if len(points) < 2 {
+		return nil, errors.New("SVG doesn't contain any paths")
+	}
+
+	// Close the loop so cursor can trace it repeatedly
+	points = append(points, points[0])
+
+	minP, maxP := points[0], points[0]
+	for _, p := range points {
+		minP = vector.NewVec2f(math32.Min(minP.X, p.X), math32.Min(minP.Y, p.Y))
+		maxP = vector.NewVec2f(math32.Max(maxP.X, p.X), math32.Max(maxP.Y, p.Y))
+	}
+
+	middle := minP.Mid(maxP)
+
+	var radius float32
+	for _, p := range points {
+		radius = math32.Max(radius, p.Dst(middle))
+	}
+
+	if radius == 0 {
+		return nil, errors.New("SVG paths have no area")
+	}
+
+	shape := &svgShape{
+		points:  make([]vector.Vector2f, len(points)),
+		lengths: make([]float32, len(points)),
+	}
+
+	for i, p := range points {
+		shape.points[i] = p.Sub(middle).Scl(1 / radius)
+
+		if i > 0 {
+			shape.lengths[i] = shape.lengths[i-1] + shape.points[i].Dst(shape.points[i-1])
+		}
+	}
+
+	return shape, nil
+}
+
+// readSVGPaths extracts path data from path, polygon and polyline elements of the SVG file. Transforms are not supported
+func readSVGPaths(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var paths []string
+
+	decoder := xml.NewDecoder(file)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range element.Attr {
+			switch {
+			case element.Name.Local == "path" && attr.Name.Local == "d":
+				paths = append(paths, attr.Value)
+			case element.Name.Local == "polyline" && attr.Name.Local == "points":
+				paths = append(paths, "M"+attr.Value)
+			case element.Name.Local == "polygon" && attr.Name.Local == "points":
+				paths = append(paths, "M"+attr.Value+"Z")
+			}
+		}
+	}
+
+	return paths, nil
+}
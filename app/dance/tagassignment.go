@@ -0,0 +1,120 @@
+package dance
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+)
+
+const (
+	// maxAssignmentBranches limits the number of assignment combinations checked in one lookahead window
+	maxAssignmentBranches  = 4096
+	maxAssignmentLookahead = 12
+
+	// busyPenalty is added when the cursor is still busy with the previous object, it's used only if all cursors are busy
+	busyPenalty = 1e9
+)
+
+type tagCursorState struct {
+	pos     vector.Vector2f
+	endTime float64
+	used    bool
+}
+
+func (state tagCursorState) moveCost(o objects.IHitObject, diff *difficulty.Difficulty) float64 {
+	if !state.used {
+		return 0
+	}
+
+	dist := float64(state.pos.Dst(o.GetStackedStartPositionMod(diff.Mods)))
+	dt := o.GetStartTime() - state.endTime
+
+	if dt <= 0 {
+		return busyPenalty + dist
+	}
+
+	// Squared velocity punishes fast moves harder so long jumps are split between cursors
+	velocity := dist / math.Max(dt, 1)
+
+	return velocity * velocity
+}
+
+func (state tagCursorState) after(o objects.IHitObject, diff *difficulty.Difficulty) tagCursorState {
+	return tagCursorState{
+		pos:     o.GetStackedEndPositionMod(diff.Mods),
+		endTime: o.GetEndTime(),
+		used:    true,
+	}
+}
+
+// assignOptimized distributes objects between cursors minimizing sum of squared cursor velocities.
+// For every object all cursor combinations for the next few objects are checked and the best cursor for the first one is picked.
+// Objects returned by shared (e.g. spinners with DoSpinnersTogether) are given to all cursors.
+func assignOptimized(queue []objects.IHitObject, cursors int, diff *difficulty.Difficulty, shared func(o objects.IHitObject) bool) [][]objects.IHitObject {
+	queues := make([][]objects.IHitObject, cursors)
+	states := make([]tagCursorState, cursors)
+
+	lookahead := 1
+	for branches := cursors; lookahead < maxAssignmentLookahead && branches*cursors <= maxAssignmentBranches; lookahead++ {
+		branches *= cursors
+	}
+
+	window := make([]objects.IHitObject, 0, lookahead)
+
+	for i, o := range queue {
+		if shared(o) {
+			for c := range queues {
+				queues[c] = append(queues[c], o)
+				states[c] = states[c].after(o, diff)
+			}
+
+			continue
+		}
+
+		window = window[:0]
+		for j := i; j < len(queue) && len(window) < lookahead; j++ {
+			if shared(queue[j]) {
+				break
+			}
+
+			window = append(window, queue[j])
+		}
+
+		best, _ := bestAssignment(window, states, diff, math.Inf(1))
+
+		queues[best] = append(queues[best], o)
+		states[best] = states[best].after(o, diff)
+	}
+
+	return queues
+}
+
+// bestAssignment returns cursor that should take the first object in window and the cost of the best assignment of whole window.
+// Branches that can't beat the limit are dropped.
+func bestAssignment(window []objects.IHitObject, states []tagCursorState, diff *difficulty.Difficulty, limit float64) (int, float64) {
+	if len(window) == 0 {
+		return 0, 0
+	}
+
+	bestCursor, bestCost := 0, math.Inf(1)
+
+	for c, state := range states {
+		cost := state.moveCost(window[0], diff)
+		if cost >= bestCost || cost >= limit {
+			continue
+		}
+
+		states[c] = state.after(window[0], diff)
+
+		_, rest := bestAssignment(window[1:], states, diff, math.Min(bestCost, limit)-cost)
+
+		states[c] = state
+
+		if cost+rest < bestCost {
+			bestCursor, bestCost = c, cost+rest
+		}
+	}
+
+	return bestCursor, bestCost
+}
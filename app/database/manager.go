@@ -162,17 +162,17 @@ func LoadBeatmaps(skipDatabaseCheck bool, importListener ImportListener) []*beat
 
 	allMaps := loadBeatmapsFromDatabase()
 
-	stdMaps := make([]*beatmap.BeatMap, 0, len(allMaps)/2)
+	supportedMaps := make([]*beatmap.BeatMap, 0, len(allMaps)/2)
 
 	for _, b := range allMaps {
-		if b.Mode == 0 {
-			stdMaps = append(stdMaps, b)
+		if b.Mode == beatmap.ModeOsu || b.Mode == beatmap.ModeTaiko || b.Mode == beatmap.ModeCatch || b.Mode == beatmap.ModeMania {
+			supportedMaps = append(supportedMaps, b)
 		}
 	}
 
-	log.Println("DatabaseManager: Loaded", len(stdMaps), "total.")
+	log.Println("DatabaseManager: Loaded", len(supportedMaps), "total.")
 
-	return stdMaps
+	return supportedMaps
 }
 
 func unpackMaps() {
@@ -411,8 +411,11 @@ func UpdateStarRating(maps []*beatmap.BeatMap, progressListener func(processed,
 
 	var toCalculate []*beatmap.BeatMap
 
+	version := performance.GetVersion()
+	calculator := performance.GetDifficultyCalculator()
+
 	for _, b := range maps {
-		if b.Mode == 0 && (b.Stars < 0 || b.StarsVersion < performance.CurrentVersion) {
+		if b.Mode == beatmap.ModeOsu && (b.Stars < 0 || b.StarsVersion != version) {
 			toCalculate = append(toCalculate, b)
 		}
 	}
@@ -430,7 +433,7 @@ func UpdateStarRating(maps []*beatmap.BeatMap, progressListener func(processed,
 	goroutines.Run(func() {
 		util.BalanceChan(workers, toCalculate, receive, func(bMap *beatmap.BeatMap) *beatmap.BeatMap {
 			defer func() {
-				bMap.StarsVersion = performance.CurrentVersion
+				bMap.StarsVersion = version
 				bMap.Clear() //Clear objects and timing to avoid OOM
 
 				if err := recover(); err != nil { //TODO: Technically should be fixed but unexpected parsing problem won't crash whole process
@@ -446,7 +449,7 @@ func UpdateStarRating(maps []*beatmap.BeatMap, progressListener func(processed,
 				log.Println("DatabaseManager:", bMap.Dir+"/"+bMap.File, "doesn't have enough hitobjects")
 				bMap.Stars = 0
 			} else {
-				attr := performance.CalculateSingle(bMap.HitObjects, bMap.Diff, false)
+				attr := calculator.CalculateSingle(bMap.HitObjects, bMap.Diff)
 				bMap.Stars = attr.Total
 			}
 
@@ -18,6 +18,9 @@ import (
 
 const MaxAudioBuffers = 2000
 
+// imageSequenceAudioCodec saves mixer's output without any conversion
+const imageSequenceAudioCodec = "pcm_f32le"
+
 var cmdAudio *exec.Cmd
 
 var audioPipe io.WriteCloser
@@ -27,7 +30,17 @@ var audioPool chan []byte
 var audioWriteQueue chan []byte
 var endSyncAudio *sync.WaitGroup
 
+// discardBuffer receives mixer's output in segments that don't record audio
+var discardBuffer []byte
+
 func startAudio(audioFPS float64) {
+	if !isLastSegment() {
+		// Mixer still has to be processed to advance the music
+		discardBuffer = make([]byte, bass.GetMixerRequiredBufferSize(1/audioFPS))
+
+		return
+	}
+
 	inputName := "-"
 
 	if runtime.GOOS != "windows" {
@@ -58,16 +71,22 @@ func startAudio(audioFPS float64) {
 		options = append(options, "-af", audioFilters)
 	}
 
-	options = append(options, "-c:a", settings.Recording.AudioCodec, "-strict", "-2")
+	var err error
 
-	encOptions, err := settings.Recording.GetAudioOptions().GenerateFFmpegArgs()
-	if err != nil {
-		panic(fmt.Sprintf("encoder \"%s\": %s", settings.Recording.AudioCodec, err))
-	} else if encOptions != nil {
-		options = append(options, encOptions...)
-	}
+	if settings.Recording.IsImageSequence() {
+		options = append(options, "-c:a", imageSequenceAudioCodec, filepath.Join(getWorkDir(), "audio.wav"))
+	} else {
+		options = append(options, "-c:a", settings.Recording.AudioCodec, "-strict", "-2")
+
+		encOptions, err := settings.Recording.GetAudioOptions().GenerateFFmpegArgs()
+		if err != nil {
+			panic(fmt.Sprintf("encoder \"%s\": %s", settings.Recording.AudioCodec, err))
+		} else if encOptions != nil {
+			options = append(options, encOptions...)
+		}
 
-	options = append(options, filepath.Join(settings.Recording.GetOutputDir(), output+"_temp", "audio."+settings.Recording.Container))
+		options = append(options, filepath.Join(getWorkDir(), "audio."+settings.Recording.Container))
+	}
 
 	log.Println("Running ffmpeg with options:", options)
 
@@ -117,6 +136,10 @@ func startAudio(audioFPS float64) {
 }
 
 func stopAudio() {
+	if cmdAudio == nil {
+		return
+	}
+
 	log.Println("Audio finished! Stopping audio pipe...")
 
 	close(audioWriteQueue)
@@ -133,6 +156,12 @@ func stopAudio() {
 }
 
 func PushAudio() {
+	if discardBuffer != nil {
+		bass.ProcessMixer(discardBuffer)
+
+		return
+	}
+
 	data := <-audioPool
 
 	bass.ProcessMixer(data)
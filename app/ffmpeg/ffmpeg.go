@@ -46,6 +46,11 @@ func preCheck() {
 
 	vcodec := settings.Recording.Encoder
 	acodec := settings.Recording.AudioCodec
+
+	if settings.Recording.IsImageSequence() {
+		vcodec = settings.Recording.ImageSequence.GetEncoder()
+		acodec = imageSequenceAudioCodec
+	}
 	vfound := false
 	afound := false
 
@@ -73,7 +78,18 @@ func preCheck() {
 	}
 }
 
-func StartFFmpeg(fps, _w, _h int, audioFPS float64, _output string) {
+// StartFFmpeg starts video and audio encoding. If layers are given, each one is encoded to a separate output
+func StartFFmpeg(fps, _w, _h int, audioFPS float64, _output string, layers []string) {
+	prepare(_output)
+
+	log.Println("Starting encoding!")
+
+	startVideo(fps, _w, _h, layers)
+	startAudio(audioFPS)
+}
+
+// prepare checks ffmpeg, sets the output name and creates the work directory
+func prepare(_output string) {
 	preCheck()
 
 	if strings.TrimSpace(_output) == "" {
@@ -82,17 +98,36 @@ func StartFFmpeg(fps, _w, _h int, audioFPS float64, _output string) {
 
 	output = _output
 
-	log.Println("Starting encoding!")
-
-	_ = os.RemoveAll(filepath.Join(settings.Recording.GetOutputDir(), output+"_temp"))
+	// Worker processes share the directory prepared by the main one
+	if !isSegmented() {
+		if settings.Recording.IsImageSequence() {
+			// Image sequence is written directly to the output directory, so refuse to mix frames with existing files instead of wiping them
+			if entries, err := os.ReadDir(getWorkDir()); err == nil && len(entries) > 0 {
+				panic(fmt.Sprintf("Image sequence output directory is not empty: %s", getWorkDir()))
+			}
+		} else {
+			_ = os.RemoveAll(getWorkDir())
+		}
+	}
 
-	err := os.MkdirAll(filepath.Join(settings.Recording.GetOutputDir(), output+"_temp"), 0755)
+	err := os.MkdirAll(getWorkDir(), 0755)
 	if err != nil && !os.IsExist(err) {
 		panic(err)
 	}
+}
 
-	startVideo(fps, _w, _h)
-	startAudio(audioFPS)
+// GetOutputName returns the name of currently rendered video without extension
+func GetOutputName() string {
+	return output
+}
+
+// getWorkDir returns the directory ffmpeg processes write to, for image sequences it's the final output directory
+func getWorkDir() string {
+	if settings.Recording.IsImageSequence() {
+		return filepath.Join(settings.Recording.GetOutputDir(), output)
+	}
+
+	return filepath.Join(settings.Recording.GetOutputDir(), output+"_temp")
 }
 
 func StopFFmpeg() {
@@ -103,23 +138,43 @@ func StopFFmpeg() {
 
 	log.Println("Ffmpeg finished.")
 
-	combine()
+	if isSegmented() {
+		log.Println(fmt.Sprintf("Segment %d/%d finished!", segmentIndex+1, segmentCount))
+
+		return
+	}
+
+	if settings.Recording.IsImageSequence() {
+		log.Println("Finished!")
+		log.Println("Image sequence is available at:", getWorkDir())
+
+		return
+	}
+
+	for _, out := range outputs {
+		combine(out.name, []string{"-i", filepath.Join(getWorkDir(), "video"+layerSuffix(out.name)+"."+settings.Recording.Container)})
+	}
+
+	cleanup()
 }
 
-func combine() {
-	options := []string{
-		"-y",
-		"-i", filepath.Join(settings.Recording.GetOutputDir(), output+"_temp", "video."+settings.Recording.Container),
-		"-i", filepath.Join(settings.Recording.GetOutputDir(), output+"_temp", "audio."+settings.Recording.Container),
+// combine muxes the video read with given input options and the audio into the final output
+func combine(name string, videoInput []string) {
+	suffix := layerSuffix(name)
+
+	options := append([]string{"-y"}, videoInput...)
+
+	options = append(options,
+		"-i", filepath.Join(getWorkDir(), "audio."+settings.Recording.Container),
 		"-c:v", "copy",
 		"-c:a", "copy", "-strict", "-2",
-	}
+	)
 
 	if settings.Recording.Container == "mp4" {
 		options = append(options, "-movflags", "+faststart")
 	}
 
-	finalOutputPath := filepath.Join(settings.Recording.GetOutputDir(), output+"."+settings.Recording.Container)
+	finalOutputPath := filepath.Join(settings.Recording.GetOutputDir(), output+suffix+"."+settings.Recording.Container)
 
 	options = append(options, finalOutputPath)
 
@@ -142,14 +197,12 @@ func combine() {
 			log.Println("Video is available at:", finalOutputPath)
 		}
 	}
-
-	cleanup()
 }
 
 func cleanup() {
 	log.Println("Cleaning up intermediate files...")
 
-	_ = os.RemoveAll(filepath.Join(settings.Recording.GetOutputDir(), output+"_temp"))
+	_ = os.RemoveAll(getWorkDir())
 
 	log.Println("Finished.")
 }
@@ -0,0 +1,86 @@
+package ffmpeg
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/graphics/texture"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// is16Bit returns true if frames are rendered and read back with 16 bits per channel
+func is16Bit() bool {
+	return settings.Recording.IsImageSequence() && settings.Recording.ImageSequence.Is16Bit()
+}
+
+// GetFramebufferFormat returns the format of framebuffers recorded frames are drawn to, 16-bit image sequences need 16-bit framebuffers
+func GetFramebufferFormat() texture.Format {
+	if is16Bit() {
+		return texture.RGBA16
+	}
+
+	return texture.RGBA
+}
+
+// setupImageReadFormat sets how frames are read from the framebuffer and returns ffmpeg's name of that pixel format
+func setupImageReadFormat() string {
+	config := settings.Recording.ImageSequence
+
+	channels := 3
+	readFormat = gl.RGB
+
+	if config.Alpha {
+		channels = 4
+		readFormat = gl.RGBA
+	}
+
+	if is16Bit() {
+		readType = gl.UNSIGNED_SHORT
+		readPixelSize = channels * 2
+
+		if config.Alpha {
+			return "rgba64le"
+		}
+
+		return "rgb48le"
+	}
+
+	readType = gl.UNSIGNED_BYTE
+	readPixelSize = channels
+
+	if config.Alpha {
+		return "rgba"
+	}
+
+	return "rgb24"
+}
+
+func imageSequenceOptions(options []string, name string) ([]string, error) {
+	encOptions, err := settings.Recording.ImageSequence.GenerateFFmpegArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	options = append(options, encOptions...)
+
+	dir := getWorkDir()
+
+	// Each separated layer gets its own subdirectory
+	if name != "" {
+		dir = filepath.Join(dir, name)
+
+		if err = os.MkdirAll(dir, 0755); err != nil && !os.IsExist(err) {
+			return nil, err
+		}
+	}
+
+	// Numbering frames from 0 makes frame's timestamp simply frame / fps, segments continue the numbering
+	startNumber := firstFrame
+
+	if settings.Recording.MotionBlur.Enabled {
+		startNumber /= int64(settings.Recording.MotionBlur.OversampleMultiplier)
+	}
+
+	return append(options, "-start_number", strconv.FormatInt(startNumber, 10), filepath.Join(dir, "%06d."+settings.Recording.ImageSequence.GetEncoder())), nil
+}
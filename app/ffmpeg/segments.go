@@ -0,0 +1,80 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/settings"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// segmentIndex is the index of the part of recording rendered by this process, -1 if recording is not segmented
+var segmentIndex = -1
+var segmentCount int
+
+// drawStart is the frame number of the first frame passed to MakeFrame, firstFrame is the first one that is saved
+var drawStart, firstFrame int64
+
+// SetSegment makes this process render only a part of the recording, has to be called before StartFFmpeg.
+// Frames are numbered from the start of the recording, frames before firstFrame only warm up the renderers.
+func SetSegment(index, count int, _drawStart, _firstFrame int64) {
+	segmentIndex = index
+	segmentCount = count
+	drawStart = _drawStart
+	firstFrame = _firstFrame
+}
+
+func isSegmented() bool {
+	return segmentIndex >= 0
+}
+
+// isLastSegment returns true if this process records the end of the video, only that one records the audio
+func isLastSegment() bool {
+	return !isSegmented() || segmentIndex == segmentCount-1
+}
+
+// segmentSuffix is appended to names of video files rendered by worker processes
+func segmentSuffix(index int) string {
+	return fmt.Sprintf("_%d", index)
+}
+
+// PrepareSegments creates the directory shared by worker processes and returns the name of the output
+func PrepareSegments(_output string) string {
+	prepare(_output)
+
+	return output
+}
+
+// CombineSegments joins videos rendered by worker processes with the audio, without re-encoding
+func CombineSegments(count int, layers []string) {
+	if settings.Recording.IsImageSequence() {
+		log.Println("Finished!")
+		log.Println("Image sequence is available at:", getWorkDir())
+
+		return
+	}
+
+	if len(layers) == 0 {
+		layers = []string{""}
+	}
+
+	for _, layer := range layers {
+		listPath := filepath.Join(getWorkDir(), "video"+layerSuffix(layer)+".txt")
+
+		var list strings.Builder
+
+		// Paths in the list are relative to the list itself
+		for i := 0; i < count; i++ {
+			list.WriteString(fmt.Sprintf("file 'video%s%s.%s'\n", layerSuffix(layer), segmentSuffix(i), settings.Recording.Container))
+		}
+
+		if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+			panic(err)
+		}
+
+		combine(layer, []string{"-f", "concat", "-i", listPath})
+	}
+
+	cleanup()
+}
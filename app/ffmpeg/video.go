@@ -26,21 +26,28 @@ import (
 
 const MaxVideoBuffers = 10
 
-var cmdVideo *exec.Cmd
+// videoOutput is a single ffmpeg process encoding one video or image sequence
+type videoOutput struct {
+	name string // layer name, empty if layers are not separated
 
-var videoPipe io.WriteCloser
+	cmd  *exec.Cmd
+	pipe io.WriteCloser
 
-var videoWriteQueue chan *PBO
-var endSyncVideo *sync.WaitGroup
+	writeQueue chan *PBO
+	endSync    *sync.WaitGroup
 
-var videoError string
-var videoErrorWait *sync.WaitGroup
+	err     string
+	errWait *sync.WaitGroup
 
-var freePBOPool chan *PBO
+	freePBOPool    chan *PBO
+	frameReadQueue []*PBO
 
-var frameReadQueue = make([]*PBO, 0)
+	blend *effects.Blend
 
-var blend *effects.Blend
+	frameNumber int64
+}
+
+var outputs []*videoOutput
 
 var w, h int
 
@@ -48,6 +55,13 @@ var limiter *frame.Limiter
 
 var parsedFormat pixconv.PixFmt
 
+// Format of frames read back directly from the framebuffer, used when no YUV conversion is done
+var readFormat, readType uint32
+var readPixelSize int
+
+// Framebuffers hold premultiplied alpha, but most formats expect straight alpha
+var unpremultiply bool
+
 type PBO struct {
 	handle     uint32
 	memPointer unsafe.Pointer
@@ -67,6 +81,14 @@ func createPBO(format pixconv.PixFmt) *PBO {
 
 	glSize := w * h * 3
 
+	if pbo.convFormat == pixconv.ARGB {
+		glSize = w * h * readPixelSize
+
+		if unpremultiply {
+			pbo.convData = make([]byte, glSize)
+		}
+	}
+
 	if pbo.convFormat == pixconv.I420 || pbo.convFormat == pixconv.NV12 || pbo.convFormat == pixconv.NV21 {
 		glSize = w * h * 3 / 2
 
@@ -92,7 +114,7 @@ func createPBO(format pixconv.PixFmt) *PBO {
 
 var rgbToYuvConverter *effects.RGBYUV
 
-func startVideo(fps, _w, _h int) {
+func startVideo(fps, _w, _h int, layers []string) {
 	w, h = _w, _h
 
 	if settings.Recording.MotionBlur.Enabled {
@@ -107,30 +129,87 @@ func startVideo(fps, _w, _h int) {
 	}
 
 	parsedFormat = pixconv.ARGB
+	inputPixFmt := "rgb24"
+
+	readFormat, readType, readPixelSize = gl.RGB, gl.UNSIGNED_BYTE, 3
+
+	if settings.Recording.IsImageSequence() { // Frames are saved as they are read, without YUV conversion
+		encoder = settings.Recording.ImageSequence.GetEncoder()
+		inputPixFmt = setupImageReadFormat()
+	} else {
+		switch outputFormat {
+		case "yuv420p":
+			parsedFormat = pixconv.I420
+		case "yuv422p":
+			parsedFormat = pixconv.I422
+		case "yuv444p":
+			parsedFormat = pixconv.I444
+		case "nv12":
+			parsedFormat = pixconv.NV12
+		case "nv21":
+			parsedFormat = pixconv.NV21
+		}
 
-	switch outputFormat {
-	case "yuv420p":
-		parsedFormat = pixconv.I420
-	case "yuv422p":
-		parsedFormat = pixconv.I422
-	case "yuv444p":
-		parsedFormat = pixconv.I444
-	case "nv12":
-		parsedFormat = pixconv.NV12
-	case "nv21":
-		parsedFormat = pixconv.NV21
+		if parsedFormat != pixconv.ARGB {
+			inputPixFmt = outputFormat
+		} else if settings.Recording.HasAlpha() {
+			inputPixFmt = "rgba"
+			readFormat, readPixelSize = gl.RGBA, 4
+		}
 	}
 
-	inputPixFmt := "rgb24"
-	if parsedFormat != pixconv.ARGB {
-		inputPixFmt = outputFormat
+	if settings.Recording.Transparent && !settings.Recording.HasAlpha() {
+		log.Println("Transparent background is enabled, but selected output doesn't support alpha channel. Background will be black.")
 	}
 
+	// OpenEXR stores premultiplied alpha
+	unpremultiply = settings.Recording.HasAlpha() && encoder != "exr"
+
 	videoFilters := strings.TrimSpace(settings.Recording.Filters)
 	if len(videoFilters) > 0 {
 		videoFilters = "," + videoFilters
 	}
 
+	outputs = nil
+
+	if len(layers) == 0 {
+		layers = []string{""}
+	}
+
+	for _, layer := range layers {
+		outputs = append(outputs, startOutput(layer, fps, encoder, outputFormat, inputPixFmt, videoFilters))
+	}
+
+	mainthread.Call(func() {
+		if parsedFormat != pixconv.ARGB {
+			rgbToYuvConverter = effects.NewRGBYUV(w, h, parsedFormat != pixconv.I444 && parsedFormat != pixconv.I422)
+		}
+
+		for _, out := range outputs {
+			for i := 0; i < MaxVideoBuffers; i++ {
+				out.freePBOPool <- createPBO(parsedFormat)
+			}
+
+			if settings.Recording.MotionBlur.Enabled {
+				bFrames := settings.Recording.MotionBlur.BlendFrames
+				if is16Bit() {
+					out.blend = effects.NewBlendFormat(w, h, bFrames, calculateWeights(bFrames), texture.RGBA16)
+				} else {
+					out.blend = effects.NewBlend(w, h, bFrames, calculateWeights(bFrames))
+				}
+			}
+		}
+	})
+
+	limiter = frame.NewLimiter(settings.Recording.EncodingFPSCap)
+}
+
+func startOutput(name string, fps int, encoder, outputFormat, inputPixFmt, videoFilters string) *videoOutput {
+	out := &videoOutput{
+		name:        name,
+		frameNumber: drawStart - 1,
+	}
+
 	inputName := "-"
 
 	if runtime.GOOS != "windows" {
@@ -140,7 +219,7 @@ func startVideo(fps, _w, _h int) {
 		}
 
 		inputName = pipe.Name()
-		videoPipe = pipe
+		out.pipe = pipe
 	}
 
 	options := []string{
@@ -156,33 +235,26 @@ func startVideo(fps, _w, _h int) {
 		"-an",
 
 		"-vf", "vflip" + videoFilters,
-		"-c:v", encoder,
-		"-color_range", "1",
-		"-colorspace", "1",
-		"-color_trc", "1",
-		"-color_primaries", "1",
-		"-movflags", "+write_colr",
 	}
 
-	if parsedFormat == pixconv.ARGB {
-		options = append(options, "-pix_fmt", outputFormat)
+	var err error
+
+	if settings.Recording.IsImageSequence() {
+		options, err = imageSequenceOptions(options, name)
+	} else {
+		options, err = videoOptions(options, encoder, outputFormat, name)
 	}
 
-	encOptions, err := settings.Recording.GetEncoderOptions().GenerateFFmpegArgs()
 	if err != nil {
 		panic(fmt.Sprintf("encoder \"%s\": %s", encoder, err))
-	} else if encOptions != nil {
-		options = append(options, encOptions...)
 	}
 
-	options = append(options, filepath.Join(settings.Recording.GetOutputDir(), output+"_temp", "video."+settings.Recording.Container))
-
 	log.Println("Running ffmpeg with options:", options)
 
-	cmdVideo = exec.Command(ffmpegExec, options...)
+	out.cmd = exec.Command(ffmpegExec, options...)
 
 	if runtime.GOOS == "windows" {
-		videoPipe, err = cmdVideo.StdinPipe()
+		out.pipe, err = out.cmd.StdinPipe()
 		if err != nil {
 			panic(err)
 		}
@@ -201,37 +273,19 @@ func startVideo(fps, _w, _h int) {
 		errList = append(errList, os.Stderr)
 	}
 
-	cmdVideo.Stdout = io.MultiWriter(outList...)
-	cmdVideo.Stderr = io.MultiWriter(errList...)
+	out.cmd.Stdout = io.MultiWriter(outList...)
+	out.cmd.Stderr = io.MultiWriter(errList...)
 
-	err = cmdVideo.Start()
+	err = out.cmd.Start()
 	if err != nil {
 		panic(fmt.Sprintf("ffmpeg's video process failed to start! Please check if video parameters are entered correctly or video codec is supported by provided container. Error: %s", err))
 	}
 
-	freePBOPool = make(chan *PBO, MaxVideoBuffers)
+	out.freePBOPool = make(chan *PBO, MaxVideoBuffers)
+	out.writeQueue = make(chan *PBO, MaxVideoBuffers)
 
-	mainthread.Call(func() {
-		if parsedFormat != pixconv.ARGB {
-			rgbToYuvConverter = effects.NewRGBYUV(w, h, parsedFormat != pixconv.I444 && parsedFormat != pixconv.I422)
-		}
-
-		for i := 0; i < MaxVideoBuffers; i++ {
-			freePBOPool <- createPBO(parsedFormat)
-		}
-
-		if settings.Recording.MotionBlur.Enabled {
-			bFrames := settings.Recording.MotionBlur.BlendFrames
-			blend = effects.NewBlend(w, h, bFrames, calculateWeights(bFrames))
-		}
-	})
-
-	videoWriteQueue = make(chan *PBO, MaxVideoBuffers)
-
-	limiter = frame.NewLimiter(settings.Recording.EncodingFPSCap)
-
-	videoErrorWait = &sync.WaitGroup{}
-	videoErrorWait.Add(1)
+	out.errWait = &sync.WaitGroup{}
+	out.errWait.Add(1)
 
 	goroutines.Run(func() {
 		sc := bufio.NewScanner(rFile)
@@ -255,79 +309,120 @@ func startVideo(fps, _w, _h int) {
 					strings.Contains(lineLower, "no capable devices found") ||
 					strings.Contains(lineLower, "does not support") {
 
-					videoError = encoder + ": " + cutLine
+					out.err = encoder + ": " + cutLine
 
 					oFile.Close()
 				}
 			}
 		}
 
-		videoErrorWait.Done()
+		out.errWait.Done()
 	})
 
-	endSyncVideo = &sync.WaitGroup{}
-	endSyncVideo.Add(1)
+	out.endSync = &sync.WaitGroup{}
+	out.endSync.Add(1)
 
 	goroutines.RunOS(func() {
-		for pbo := range videoWriteQueue {
+		for pbo := range out.writeQueue {
 			pbo.convertSync.Wait() // Wait for conversion to end
 
-			if _, err := videoPipe.Write(pbo.convData); err != nil {
+			if _, err := out.pipe.Write(pbo.convData); err != nil {
 				errorMsg := err.Error()
 
-				videoErrorWait.Wait()
+				out.errWait.Wait()
 
-				if videoError != "" {
-					errorMsg = videoError
+				if out.err != "" {
+					errorMsg = out.err
 				}
 
 				panic(fmt.Sprintf("ffmpeg's video process finished abruptly! Please check if you have enough storage or video parameters are entered correctly. Error: %s", errorMsg))
 			}
 
-			freePBOPool <- pbo
+			out.freePBOPool <- pbo
 		}
 
-		endSyncVideo.Done()
+		out.endSync.Done()
 	})
+
+	return out
+}
+
+func videoOptions(options []string, encoder, outputFormat, name string) ([]string, error) {
+	options = append(options,
+		"-c:v", encoder,
+		"-color_range", "1",
+		"-colorspace", "1",
+		"-color_trc", "1",
+		"-color_primaries", "1",
+		"-movflags", "+write_colr",
+	)
+
+	if parsedFormat == pixconv.ARGB {
+		options = append(options, "-pix_fmt", outputFormat)
+	}
+
+	encOptions, err := settings.Recording.GetEncoderOptions().GenerateFFmpegArgs()
+	if err != nil {
+		return nil, err
+	} else if encOptions != nil {
+		options = append(options, encOptions...)
+	}
+
+	fileName := "video" + layerSuffix(name)
+	if isSegmented() {
+		fileName += segmentSuffix(segmentIndex)
+	}
+
+	return append(options, filepath.Join(getWorkDir(), fileName+"."+settings.Recording.Container)), nil
 }
 
 func stopVideo() {
 	log.Println("Waiting for video to finish writing...")
 
-	checkData(true, true)
+	for _, out := range outputs {
+		out.checkData(true, true)
 
-	close(videoWriteQueue)
+		close(out.writeQueue)
+	}
 
-	endSyncVideo.Wait()
+	for _, out := range outputs {
+		out.endSync.Wait()
+	}
 
 	log.Println("Finished! Stopping video pipe...")
 
-	_ = videoPipe.Close()
+	for _, out := range outputs {
+		_ = out.pipe.Close()
+	}
 
 	log.Println("Video pipe closed. Waiting for video ffmpeg process to finish...")
 
-	_ = cmdVideo.Wait()
+	for _, out := range outputs {
+		_ = out.cmd.Wait()
+	}
 
 	log.Println("Video process finished.")
 }
 
-func PreFrame() {
+// PreFrame has to be called before drawing the frame of the output with given index
+func PreFrame(index int) {
 	if settings.Recording.MotionBlur.Enabled {
-		blend.Begin()
+		outputs[index].blend.Begin()
 	} else if rgbToYuvConverter != nil {
 		rgbToYuvConverter.Begin()
 	}
 }
 
-var frameNumber = int64(-1)
+// MakeFrame reads back the drawn frame and sends it to the output with given index
+func MakeFrame(index int) {
+	out := outputs[index]
 
-func MakeFrame() {
-	frameNumber++
+	out.frameNumber++
 
 	if settings.Recording.MotionBlur.Enabled {
-		blend.End()
+		out.blend.End()
 
-		if frameNumber%int64(settings.Recording.MotionBlur.OversampleMultiplier) != 0 {
+		if out.frameNumber%int64(settings.Recording.MotionBlur.OversampleMultiplier) != 0 || out.frameNumber < firstFrame {
 			return
 		}
 
@@ -335,20 +430,26 @@ func MakeFrame() {
 			rgbToYuvConverter.Begin()
 		}
 
-		blend.Blend()
+		out.blend.Blend()
 	}
 
 	var yuvFull, yuvHalf texture.Texture
 
 	if rgbToYuvConverter != nil {
 		rgbToYuvConverter.End()
+	}
+
+	if out.frameNumber < firstFrame { // Frames before segment's start only fill renderers' history
+		return
+	}
 
+	if rgbToYuvConverter != nil {
 		yuvFull, yuvHalf = rgbToYuvConverter.Draw()
 	}
 
-	checkData(len(freePBOPool) == 0, false) // Force wait for at least one frame to be retrieved if pbo pool is empty
+	out.checkData(len(out.freePBOPool) == 0, false) // Force wait for at least one frame to be retrieved if pbo pool is empty
 
-	pbo := <-freePBOPool // Wait for free PBO
+	pbo := <-out.freePBOPool // Wait for free PBO
 
 	gl.MemoryBarrier(gl.PIXEL_BUFFER_BARRIER_BIT)
 
@@ -366,23 +467,26 @@ func MakeFrame() {
 		gl.GetTextureSubImage(yuvFull.GetID(), 0, 0, 0, 0, int32(w), int32(h), 1, gl.GREEN, gl.UNSIGNED_BYTE, int32(w*h), gl.PtrOffset(w*h))
 		gl.GetTextureSubImage(yuvFull.GetID(), 0, 0, 0, 0, int32(w), int32(h), 1, gl.BLUE, gl.UNSIGNED_BYTE, int32(w*h), gl.PtrOffset(w*h*2))
 	} else {
-		gl.ReadPixels(0, 0, int32(w), int32(h), uint32(gl.RGB), gl.UNSIGNED_BYTE, gl.Ptr(nil))
+		gl.ReadPixels(0, 0, int32(w), int32(h), readFormat, readType, gl.Ptr(nil))
 	}
 
 	pbo.sync = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
 
 	gl.Flush()
 
-	frameReadQueue = append(frameReadQueue, pbo)
+	out.frameReadQueue = append(out.frameReadQueue, pbo)
 
-	checkData(false, false)
+	out.checkData(false, false)
 
-	limiter.Sync()
+	// All outputs share the same frame, so limit only once
+	if index == len(outputs)-1 {
+		limiter.Sync()
+	}
 }
 
-func checkData(waitForFirst, waitForAll bool) { // I tried to do that on another thread, but it needs another opengl context and creates other funky problems
-	for i := 0; len(frameReadQueue) > 0; i++ {
-		pbo := frameReadQueue[0]
+func (out *videoOutput) checkData(waitForFirst, waitForAll bool) { // I tried to do that on another thread, but it needs another opengl context and creates other funky problems
+	for i := 0; len(out.frameReadQueue) > 0; i++ {
+		pbo := out.frameReadQueue[0]
 
 		status := int32(gl.SIGNALED)
 
@@ -404,14 +508,26 @@ func checkData(waitForFirst, waitForAll bool) { // I tried to do that on another
 
 		gl.DeleteSync(pbo.sync)
 
-		frameReadQueue = frameReadQueue[1:]
+		out.frameReadQueue = out.frameReadQueue[1:]
 
-		submitFrame(pbo)
+		out.submitFrame(pbo)
 	}
 }
 
-func submitFrame(pbo *PBO) {
-	if pbo.convFormat == pixconv.I444 || pbo.convFormat == pixconv.I420 || pbo.convFormat == pixconv.ARGB { // For yuv444p and yuv420p or raw just dump the frame
+func (out *videoOutput) submitFrame(pbo *PBO) {
+	if pbo.convFormat == pixconv.ARGB && unpremultiply {
+		pbo.convertSync.Add(1)
+
+		goroutines.RunOS(func() {
+			if readType == gl.UNSIGNED_SHORT {
+				pixconv.UnpremultiplyRGBA64(pbo.data, pbo.convData, w, h)
+			} else {
+				pixconv.UnpremultiplyRGBA(pbo.data, pbo.convData, w, h)
+			}
+
+			pbo.convertSync.Done()
+		})
+	} else if pbo.convFormat == pixconv.I444 || pbo.convFormat == pixconv.I420 || pbo.convFormat == pixconv.ARGB { // For yuv444p and yuv420p or raw just dump the frame
 		pbo.convData = pbo.data
 	} else {
 		pbo.convertSync.Add(1)
@@ -427,5 +543,14 @@ func submitFrame(pbo *PBO) {
 		})
 	}
 
-	videoWriteQueue <- pbo
+	out.writeQueue <- pbo
+}
+
+// layerSuffix is appended to names of output files of separated layers
+func layerSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	return "_" + name
 }
@@ -73,6 +73,7 @@ type Cursor struct {
 	IsAutoplay    bool
 
 	OldSpinnerScoring bool
+	LazerReplay       bool // Replay was exported from osu!lazer
 
 	LastFrameTime    int64 //
 	CurrentFrameTime int64 //
@@ -127,8 +128,19 @@ func NewCursor() *Cursor {
 	return cursor
 }
 
+// NewCursorHeadless creates a cursor without renderer, it can be used only as an input for rulesets
+func NewCursorHeadless() *Cursor {
+	return &Cursor{Position: vector.NewVec2f(100, 100)}
+}
+
 func (cursor *Cursor) SetPos(pt vector.Vector2f) {
 	cursor.RawPosition = pt
+
+	if cursor.renderer == nil {
+		cursor.Position = pt
+		return
+	}
+
 	tmp := pt
 
 	if cursor.InvertDisplay {
@@ -352,7 +364,7 @@ func (cursor *Cursor) DrawM(scale float64, batch *batch.QuadBatch, color color2.
 		fboBatch.Begin()
 
 		blend.Push()
-		blend.SetFunction(blend.SrcAlpha, blend.One)
+		blend.SetFunctionSeparate(blend.SrcAlpha, blend.One, blend.One, blend.OneMinusSrcAlpha)
 
 		cursorFBOSprite.Draw(0, fboBatch)
 		fboBatch.Flush()
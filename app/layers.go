@@ -0,0 +1,148 @@
+package app
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/wieku/danser-go/app/ffmpeg"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/states"
+	batch2 "github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/blend"
+	"github.com/wieku/danser-go/framework/graphics/buffer"
+	"github.com/wieku/danser-go/framework/graphics/sprite"
+	"github.com/wieku/danser-go/framework/graphics/viewport"
+	"github.com/wieku/danser-go/framework/math/vector"
+)
+
+// layerRenderer splits Player's frame into separate framebuffers, one per selected layer
+type layerRenderer struct {
+	layers []states.Layer
+
+	fbos    map[states.Layer]*buffer.Framebuffer
+	sprites []*sprite.Sprite
+
+	// Layers that are not selected are drawn here and discarded
+	scratch *buffer.Framebuffer
+
+	current *buffer.Framebuffer
+
+	batch *batch2.QuadBatch
+}
+
+// getSelectedLayers returns layers chosen in recording settings
+func getSelectedLayers() (layers []states.Layer) {
+	config := settings.Recording.Layers
+
+	selected := []bool{config.Background, config.Objects, config.Cursors, config.HUD, config.Knockout}
+
+	for i, s := range selected {
+		if s {
+			layers = append(layers, states.Layer(i))
+		}
+	}
+
+	return
+}
+
+// getLayerNames returns names of layers rendered to separate outputs, used as suffixes of output files.
+// Returns nil if layers are not separated.
+func getLayerNames() (names []string) {
+	if !settings.Recording.Layers.Enabled {
+		return nil
+	}
+
+	for _, layer := range getSelectedLayers() {
+		names = append(names, layer.String())
+	}
+
+	if len(names) == 0 {
+		panic("Layer separation is enabled, but no layers are selected")
+	}
+
+	return
+}
+
+func newLayerRenderer(w, h int) *layerRenderer {
+	renderer := &layerRenderer{
+		layers: getSelectedLayers(),
+		fbos:   make(map[states.Layer]*buffer.Framebuffer),
+		batch:  batch2.NewQuadBatchSize(1),
+	}
+
+	format := ffmpeg.GetFramebufferFormat()
+
+	newFBO := func() *buffer.Framebuffer {
+		if settings.Graphics.MSAA > 0 {
+			return buffer.NewFrameMultisampleFormat(w, h, int(settings.Graphics.MSAA), format)
+		}
+
+		return buffer.NewFrameFormat(w, h, format, false)
+	}
+
+	for _, layer := range renderer.layers {
+		fbo := newFBO()
+
+		region := fbo.Texture().GetRegion()
+
+		renderer.fbos[layer] = fbo
+		renderer.sprites = append(renderer.sprites, sprite.NewSpriteSingle(&region, 0, vector.NewVec2d(float64(w)/2, float64(h)/2), vector.Centre))
+	}
+
+	renderer.scratch = newFBO()
+
+	renderer.batch.SetCamera(mgl32.Ortho(0, float32(w), 0, float32(h), -1, 1))
+
+	return renderer
+}
+
+// drawPlayer draws a single frame of the player, switching framebuffers each time player enters a different layer
+func (renderer *layerRenderer) drawPlayer(player *states.Player) {
+	for _, fbo := range renderer.fbos {
+		fbo.ClearColor(0, 0, 0, 0)
+	}
+
+	player.SetLayerHook(renderer.switchLayer)
+
+	player.Draw(0)
+
+	player.SetLayerHook(nil)
+
+	if renderer.current != nil {
+		renderer.current.Unbind()
+		renderer.current = nil
+	}
+}
+
+func (renderer *layerRenderer) switchLayer(layer states.Layer) {
+	fbo, ok := renderer.fbos[layer]
+	if !ok {
+		fbo = renderer.scratch
+	}
+
+	if fbo == renderer.current {
+		return
+	}
+
+	if renderer.current != nil {
+		renderer.current.Unbind()
+	}
+
+	fbo.Bind()
+
+	renderer.current = fbo
+}
+
+// drawOutput draws the layer with given index to the currently bound framebuffer
+func (renderer *layerRenderer) drawOutput(index int) {
+	viewport.Push(renderer.scratch.GetWidth(), renderer.scratch.GetHeight())
+
+	blend.Push()
+	blend.Disable()
+
+	renderer.batch.Begin()
+	renderer.sprites[index].Draw(0, renderer.batch)
+	renderer.batch.End()
+
+	blend.Pop()
+
+	viewport.Pop()
+}
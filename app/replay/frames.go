@@ -0,0 +1,212 @@
+package replay
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/dance/input"
+	"github.com/wieku/danser-go/app/dance/movers"
+	"github.com/wieku/danser-go/app/dance/schedulers"
+	"github.com/wieku/danser-go/app/dance/spinners"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/framework/math/math32"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"github.com/wieku/rplpa"
+)
+
+// CleanFrames removes mania seed frame and incorrect first frame with 0 delta
+func CleanFrames(frames []*rplpa.ReplayData) []*rplpa.ReplayData {
+	for i, frame := range frames {
+		if frame.Time == -12345 {
+			frames = append(frames[:i], frames[i+1:]...)
+			break
+		}
+	}
+
+	if len(frames) > 0 && frames[0].Time == 0 {
+		frames = frames[1:]
+	}
+
+	return frames
+}
+
+// ConvertFrames converts delta-timed frames to game mode specific frames with absolute time, frames have to be cleaned with CleanFrames beforehand
+func ConvertFrames[T any](frames []*rplpa.ReplayData, convert func(time float64, frame *rplpa.ReplayData) T) []T {
+	converted := make([]T, 0, len(frames))
+
+	time := int64(0)
+
+	for _, frame := range frames {
+		time += frame.Time
+
+		converted = append(converted, convert(float64(time), frame))
+	}
+
+	return converted
+}
+
+// FrameProcessor feeds osu!standard replay frames to a cursor and the ruleset.
+// It's shared by replay playback and verification, so both score replays the same way.
+type FrameProcessor struct {
+	Cursor *graphics.Cursor
+	Mods   difficulty.Modifier
+
+	Frames []*rplpa.ReplayData
+	Index  int   // index of the next frame to process
+	Time   int64 // absolute time of the last processed frame
+
+	NewHandling bool
+
+	Relax     *input.RelaxInputProcessor
+	Autopilot schedulers.Scheduler
+}
+
+// NewFrameProcessor moves the cursor to the first frame, frames have to be cleaned with CleanFrames beforehand
+func NewFrameProcessor(cursor *graphics.Cursor, mods difficulty.Modifier, frames []*rplpa.ReplayData, osuVersion int32) *FrameProcessor {
+	cursor.OldSpinnerScoring = osuVersion < 20190510 // This was when spinner scoring was changed: https://osu.ppy.sh/home/changelog/cuttingedge/20190510.2
+	cursor.LazerReplay = osuVersion >= 30000000      // osu!lazer exports replays with version based on its release date, which is way bigger than stable's
+
+	cursor.SetPos(vector.NewVec2f(frames[0].MouseX, frames[0].MouseY))
+
+	return &FrameProcessor{
+		Cursor:      cursor,
+		Mods:        mods,
+		Frames:      frames[1:],
+		Time:        frames[0].Time,
+		NewHandling: osuVersion >= 20190506, // This was when slider scoring was changed, so *I think* replay handling as well: https://osu.ppy.sh/home/changelog/cuttingedge/20190506
+	}
+}
+
+// InitInput creates input processors for Relax and Autopilot, has to be called after the ruleset is created
+func (processor *FrameProcessor) InitInput(ruleset *osu.OsuRuleSet, bMap *beatmap.BeatMap) {
+	if processor.Mods.Active(difficulty.Relax) {
+		processor.Relax = input.NewRelaxInputProcessor(ruleset, processor.Cursor)
+	}
+
+	if processor.Mods.Active(difficulty.Relax2) {
+		processor.InitAutopilot(bMap)
+	}
+}
+
+// InitAutopilot builds Autopilot movement from scratch
+func (processor *FrameProcessor) InitAutopilot(bMap *beatmap.BeatMap) {
+	processor.Autopilot = schedulers.NewGenericScheduler(movers.NewLinearMoverSimple, 0, 0)
+
+	diff := difficulty.NewDifficulty(bMap.Diff.GetHP(), bMap.Diff.GetCS(), bMap.Diff.GetOD(), bMap.Diff.GetAR())
+	diff.SetMods(processor.Mods)
+	diff.SetCustomSpeed(bMap.Diff.CustomSpeed)
+
+	processor.Autopilot.Init(bMap.GetObjectsCopy(), diff, processor.Cursor, spinners.GetMoverCtorByName("circle"), false)
+}
+
+// Finished returns true if all frames were processed
+func (processor *FrameProcessor) Finished() bool {
+	return processor.Index >= len(processor.Frames)
+}
+
+// Update processes all frames up to the given time, returns true if at least one frame was processed
+func (processor *FrameProcessor) Update(ruleset *osu.OsuRuleSet, time int64) bool {
+	cursor := processor.Cursor
+
+	if processor.Autopilot != nil {
+		processor.Autopilot.Update(float64(time))
+	}
+
+	if processor.Finished() {
+		cursor.LeftKey, cursor.RightKey = false, false
+		cursor.LeftMouse, cursor.RightMouse = false, false
+		cursor.LeftButton, cursor.RightButton = false, false
+
+		ruleset.UpdateClickFor(cursor, time)
+		ruleset.UpdateNormalFor(cursor, time, false)
+		ruleset.UpdatePostFor(cursor, time, false)
+
+		return false
+	}
+
+	wasUpdated := false
+
+	for processor.Index < len(processor.Frames) && processor.Time+processor.Frames[processor.Index].Time <= time {
+		frame := processor.Frames[processor.Index]
+		processor.Time += frame.Time
+
+		// If next frame is not in the next millisecond, assume it's -36ms slider end
+		processAhead := true
+		if processor.Index+1 < len(processor.Frames) && processor.Frames[processor.Index+1].Time == 1 {
+			processAhead = false
+		}
+
+		if processor.Autopilot == nil {
+			cursor.SetPos(vector.NewVec2f(frame.MouseX, frame.MouseY))
+		}
+
+		cursor.LastFrameTime = cursor.CurrentFrameTime
+		cursor.CurrentFrameTime = processor.Time
+		cursor.IsReplayFrame = true
+
+		if processor.Relax == nil {
+			cursor.LeftKey = frame.KeyPressed.LeftClick && frame.KeyPressed.Key1
+			cursor.RightKey = frame.KeyPressed.RightClick && frame.KeyPressed.Key2
+
+			cursor.LeftMouse = frame.KeyPressed.LeftClick && !frame.KeyPressed.Key1
+			cursor.RightMouse = frame.KeyPressed.RightClick && !frame.KeyPressed.Key2
+
+			cursor.LeftButton = frame.KeyPressed.LeftClick
+			cursor.RightButton = frame.KeyPressed.RightClick
+		} else {
+			processor.Relax.Update(float64(processor.Time))
+		}
+
+		cursor.SmokeKey = frame.KeyPressed.Smoke
+
+		ruleset.UpdateClickFor(cursor, processor.Time)
+		ruleset.UpdateNormalFor(cursor, processor.Time, processAhead)
+
+		// New replays (after 20190506) scores object ends only on replay frame
+		if processor.NewHandling || processor.Index == len(processor.Frames)-1 {
+			ruleset.UpdatePostFor(cursor, processor.Time, processAhead)
+		} else {
+			localFrame := processor.Frames[mutils.Clamp(processor.Index+1, 0, len(processor.Frames)-1)]
+
+			// HACK for older replays: update object ends till the next frame
+			for localTime := processor.Time; localTime < processor.Time+localFrame.Time; localTime++ {
+				ruleset.UpdatePostFor(cursor, localTime, false)
+			}
+		}
+
+		wasUpdated = true
+
+		processor.Index++
+	}
+
+	if !wasUpdated {
+		cursor.IsReplayFrame = false
+	}
+
+	if processor.Finished() {
+		ruleset.PlayerStopped(cursor, processor.Time)
+	}
+
+	return wasUpdated
+}
+
+// Interpolate moves the cursor between the last and the next frame, used to display smooth movement between frames
+func (processor *FrameProcessor) Interpolate(time float64) {
+	if processor.Finished() || processor.Autopilot != nil {
+		return
+	}
+
+	frames := processor.Frames
+
+	localIndex := mutils.Clamp(processor.Index, 0, len(frames)-1)
+
+	progress := math32.Min(float32(time-float64(processor.Time)), float32(frames[localIndex].Time)) / float32(frames[localIndex].Time)
+
+	prevIndex := mutils.Max(0, localIndex-1)
+
+	mX := (frames[localIndex].MouseX-frames[prevIndex].MouseX)*progress + frames[prevIndex].MouseX
+	mY := (frames[localIndex].MouseY-frames[prevIndex].MouseY)*progress + frames[prevIndex].MouseY
+
+	processor.Cursor.SetPos(vector.NewVec2f(mX, mY))
+}
@@ -0,0 +1,153 @@
+package replay
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/framework/env"
+	"github.com/wieku/danser-go/framework/files"
+	"github.com/wieku/rplpa"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const lifeBarInterval = 2000
+
+// Recorder captures cursor movement and key presses of a single cursor so they can be saved as .osr replay
+type Recorder struct {
+	bMap    *beatmap.BeatMap
+	cursor  *graphics.Cursor
+	ruleset *osu.OsuRuleSet
+
+	// Username is saved as replay's player name instead of cursor's name if set
+	Username string
+
+	frames  []*rplpa.ReplayData
+	lifeBar []rplpa.LifeBarGraph
+
+	lastTime     int64
+	lastKeys     int
+	lastLifeTime int64
+
+	saved bool
+}
+
+func NewRecorder(bMap *beatmap.BeatMap, cursor *graphics.Cursor, ruleset *osu.OsuRuleSet) *Recorder {
+	return &Recorder{
+		bMap:    bMap,
+		cursor:  cursor,
+		ruleset: ruleset,
+		frames: []*rplpa.ReplayData{
+			{Time: 0, MouseX: 256, MouseY: -500, KeyPressed: &rplpa.KeyPressed{}},
+			{Time: -1, MouseX: 256, MouseY: -500, KeyPressed: &rplpa.KeyPressed{}},
+		},
+		lastTime:     -1,
+		lastLifeTime: -lifeBarInterval,
+	}
+}
+
+// Update records a frame if cursor is on replay frame or pressed keys changed
+func (recorder *Recorder) Update(time int64) {
+	if recorder.saved || time <= recorder.lastTime {
+		return
+	}
+
+	keys := &rplpa.KeyPressed{
+		LeftClick:  recorder.cursor.LeftButton,
+		RightClick: recorder.cursor.RightButton,
+		Key1:       recorder.cursor.LeftKey,
+		Key2:       recorder.cursor.RightKey,
+		Smoke:      recorder.cursor.SmokeKey,
+	}
+
+	keyState := EncodeKeys(keys)
+
+	if recorder.cursor.IsReplayFrame || keyState != recorder.lastKeys {
+		recorder.frames = append(recorder.frames, &rplpa.ReplayData{
+			Time:       time - recorder.lastTime,
+			MouseX:     recorder.cursor.RawPosition.X,
+			MouseY:     recorder.cursor.RawPosition.Y,
+			KeyPressed: keys,
+		})
+
+		recorder.lastTime = time
+		recorder.lastKeys = keyState
+	}
+
+	if time-recorder.lastLifeTime >= lifeBarInterval {
+		recorder.lifeBar = append(recorder.lifeBar, rplpa.LifeBarGraph{
+			Time: int32(time),
+			HP:   float32(recorder.ruleset.GetHP(recorder.cursor)),
+		})
+
+		recorder.lastLifeTime = time
+	}
+}
+
+// GetReplay builds the replay from recorded frames and current score of the cursor
+func (recorder *Recorder) GetReplay() *rplpa.Replay {
+	score := recorder.ruleset.GetScore(recorder.cursor)
+
+	username := recorder.cursor.Name
+	if recorder.Username != "" {
+		username = recorder.Username
+	}
+
+	frames := make([]*rplpa.ReplayData, len(recorder.frames), len(recorder.frames)+1)
+	copy(frames, recorder.frames)
+
+	// osu! stores RNG seed in the last frame
+	frames = append(frames, &rplpa.ReplayData{Time: -12345, KeyPressed: &rplpa.KeyPressed{}})
+
+	return &rplpa.Replay{
+		PlayMode:     rplpa.OSU,
+		OsuVersion:   OsuVersion,
+		BeatmapMD5:   recorder.bMap.MD5,
+		Username:     username,
+		Count300:     uint16(score.Count300),
+		Count100:     uint16(score.Count100),
+		Count50:      uint16(score.Count50),
+		CountGeki:    uint16(score.CountGeki),
+		CountKatu:    uint16(score.CountKatu),
+		CountMiss:    uint16(score.CountMiss),
+		Score:        int32(score.Score),
+		MaxCombo:     uint16(score.Combo),
+		Fullcombo:    score.PerfectCombo,
+		Mods:         uint32(recorder.bMap.Diff.Mods),
+		LifebarGraph: recorder.lifeBar,
+		Timestamp:    recorder.cursor.ScoreTime,
+		ReplayData:   frames,
+		ScoreID:      0,
+	}
+}
+
+// Save writes the replay to danser's replays directory, does nothing if replay was already saved or nothing was recorded
+func (recorder *Recorder) Save() {
+	if recorder.saved || len(recorder.frames) <= 2 { // First two frames are always there
+		return
+	}
+
+	recorder.saved = true
+
+	replay := recorder.GetReplay()
+
+	dir := filepath.Join(env.DataDir(), "replays", recorder.bMap.MD5)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Println("Replay: Failed to create replay directory:", err)
+		return
+	}
+
+	mapName := fmt.Sprintf("%s - %s [%s]", recorder.bMap.Artist, recorder.bMap.Name, recorder.bMap.Difficulty)
+
+	path := filepath.Join(dir, files.FixName(CreateFileName(replay, mapName)))
+
+	if err := Save(replay, path); err != nil {
+		log.Println("Replay: Failed to save replay:", err)
+		return
+	}
+
+	log.Println("Replay: Saved replay to:", path)
+}
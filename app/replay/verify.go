@@ -0,0 +1,217 @@
+package replay
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/rplpa"
+	"log"
+	"math"
+	"os"
+	"strings"
+)
+
+type ScoreSummary struct {
+	Count300  uint    `json:"count300"`
+	Count100  uint    `json:"count100"`
+	Count50   uint    `json:"count50"`
+	CountMiss uint    `json:"countMiss"`
+	MaxCombo  uint    `json:"maxCombo"`
+	Score     int64   `json:"score"`
+	Accuracy  float64 `json:"accuracy"`
+}
+
+type VerificationResult struct {
+	File       string        `json:"file"`
+	Player     string        `json:"player"`
+	Mods       string        `json:"mods"`
+	Error      string        `json:"error,omitempty"`
+	Expected   *ScoreSummary `json:"expected,omitempty"`
+	Computed   *ScoreSummary `json:"computed,omitempty"`
+	Mismatches []string      `json:"mismatches"`
+	Match      bool          `json:"match"`
+}
+
+type VerificationReport struct {
+	Beatmap    string                `json:"beatmap"`
+	BeatmapMD5 string                `json:"beatmapMD5"`
+	Replays    []*VerificationResult `json:"replays"`
+	Match      bool                  `json:"match"`
+}
+
+// verifiedPlayer is a replay fed to osu! ruleset without any rendering
+type verifiedPlayer struct {
+	result    *VerificationResult
+	processor *FrameProcessor
+}
+
+// Verify runs provided replays through osu! ruleset without graphics and audio and compares the results with scores stored in replays.
+// Beatmap has to have its timing points and hit objects parsed.
+func Verify(bMap *beatmap.BeatMap, paths []string) *VerificationReport {
+	report := &VerificationReport{
+		Beatmap:    fmt.Sprintf("%s - %s [%s]", bMap.Artist, bMap.Name, bMap.Difficulty),
+		BeatmapMD5: bMap.MD5,
+		Replays:    make([]*VerificationResult, 0, len(paths)),
+		Match:      true,
+	}
+
+	players := make([]*verifiedPlayer, 0, len(paths))
+
+	for _, path := range paths {
+		result := &VerificationResult{File: path, Mismatches: make([]string, 0)}
+		report.Replays = append(report.Replays, result)
+
+		player, err := loadPlayer(bMap, path, result)
+		if err != nil {
+			result.Error = err.Error()
+			report.Match = false
+
+			continue
+		}
+
+		players = append(players, player)
+	}
+
+	if len(players) == 0 {
+		return report
+	}
+
+	cursors := make([]*graphics.Cursor, 0, len(players))
+	mods := make([]difficulty.Modifier, 0, len(players))
+
+	for _, player := range players {
+		cursors = append(cursors, player.processor.Cursor)
+		mods = append(mods, player.processor.Mods)
+	}
+
+	ruleset := osu.NewOsuRuleset(bMap, cursors, mods)
+	ruleset.SetVisualFeedback(false)
+
+	for _, player := range players {
+		player.processor.InitInput(ruleset, bMap)
+	}
+
+	startTime := int64(math.Min(-200, bMap.HitObjects[0].GetStartTime()-bMap.Diff.Preempt))
+	endTime := int64(bMap.HitObjects[len(bMap.HitObjects)-1].GetEndTime()) + bMap.Diff.Hit50 + 1000
+
+	for time := startTime; time <= endTime && !ruleset.IsEnded(); time++ {
+		for _, player := range players {
+			player.processor.Update(ruleset, time)
+		}
+
+		ruleset.Update(time)
+	}
+
+	if !ruleset.IsEnded() {
+		log.Println("Verify: Ruleset didn't finish processing all objects")
+	}
+
+	for _, player := range players {
+		score := ruleset.GetScore(player.processor.Cursor)
+
+		player.result.Computed = &ScoreSummary{
+			Count300:  score.Count300,
+			Count100:  score.Count100,
+			Count50:   score.Count50,
+			CountMiss: score.CountMiss,
+			MaxCombo:  score.Combo,
+			Score:     score.Score,
+			Accuracy:  score.Accuracy,
+		}
+
+		player.result.compare()
+
+		if !player.result.Match {
+			report.Match = false
+		}
+	}
+
+	return report
+}
+
+func loadPlayer(bMap *beatmap.BeatMap, path string, result *VerificationResult) (*verifiedPlayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	replay, err := rplpa.ParseReplay(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Player = replay.Username
+	result.Mods = difficulty.Modifier(replay.Mods).String()
+	result.Expected = &ScoreSummary{
+		Count300:  uint(replay.Count300),
+		Count100:  uint(replay.Count100),
+		Count50:   uint(replay.Count50),
+		CountMiss: uint(replay.CountMiss),
+		MaxCombo:  uint(replay.MaxCombo),
+		Score:     int64(replay.Score),
+		Accuracy:  calculateAccuracy(replay),
+	}
+
+	if replay.PlayMode != rplpa.OSU {
+		return nil, fmt.Errorf("modes other than osu!standard are not supported")
+	}
+
+	if !strings.EqualFold(replay.BeatmapMD5, bMap.MD5) {
+		return nil, fmt.Errorf("replay is for a different beatmap: %s", replay.BeatmapMD5)
+	}
+
+	mods := difficulty.Modifier(replay.Mods)
+
+	if !mods.Compatible() || mods.Active(difficulty.Target) {
+		return nil, fmt.Errorf("incompatible mods: %s", mods.String())
+	}
+
+	frames := CleanFrames(replay.ReplayData)
+
+	if len(frames) < 2 {
+		return nil, fmt.Errorf("replay is missing input data")
+	}
+
+	cursor := graphics.NewCursorHeadless()
+	cursor.Name = replay.Username
+	cursor.ScoreTime = replay.Timestamp
+
+	return &verifiedPlayer{
+		result:    result,
+		processor: NewFrameProcessor(cursor, mods, frames, replay.OsuVersion),
+	}, nil
+}
+
+func (result *VerificationResult) compare() {
+	expected, computed := result.Expected, result.Computed
+
+	check := func(name string, exp, comp interface{}) {
+		if exp != comp {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("%s: expected %v, got %v", name, exp, comp))
+		}
+	}
+
+	check("count300", expected.Count300, computed.Count300)
+	check("count100", expected.Count100, computed.Count100)
+	check("count50", expected.Count50, computed.Count50)
+	check("countMiss", expected.CountMiss, computed.CountMiss)
+	check("maxCombo", expected.MaxCombo, computed.MaxCombo)
+	check("score", expected.Score, computed.Score)
+
+	if math.Abs(expected.Accuracy-computed.Accuracy) > 0.005 {
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("accuracy: expected %.2f, got %.2f", expected.Accuracy, computed.Accuracy))
+	}
+
+	result.Match = len(result.Mismatches) == 0
+}
+
+func calculateAccuracy(replay *rplpa.Replay) float64 {
+	total := int(replay.Count300) + int(replay.Count100) + int(replay.Count50) + int(replay.CountMiss)
+	if total == 0 {
+		return 100
+	}
+
+	return 100 * float64(300*int(replay.Count300)+100*int(replay.Count100)+50*int(replay.Count50)) / float64(300*total)
+}
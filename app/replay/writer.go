@@ -0,0 +1,171 @@
+package replay
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"github.com/bnch/uleb128"
+	"github.com/itchio/lzma"
+	"github.com/wieku/rplpa"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Replays produced by danser are saved with a version that uses current slider and spinner handling
+const OsuVersion = 20220216
+
+// Ticks of .NET's DateTime between 0001-01-01 and unix epoch
+const epochTicks = 621355968000000000
+
+// Encode serializes the replay to .osr format
+func Encode(replay *rplpa.Replay) ([]byte, error) {
+	compressed, err := compressFrames(replay.ReplayData)
+	if err != nil {
+		return nil, err
+	}
+
+	if replay.ReplayMD5 == "" {
+		replay.ReplayMD5 = calculateReplayMD5(replay)
+	}
+
+	buf := new(bytes.Buffer)
+
+	write := func(data interface{}) {
+		_ = binary.Write(buf, binary.LittleEndian, data)
+	}
+
+	write(replay.PlayMode)
+	write(replay.OsuVersion)
+	writeString(buf, replay.BeatmapMD5)
+	writeString(buf, replay.Username)
+	writeString(buf, replay.ReplayMD5)
+	write(replay.Count300)
+	write(replay.Count100)
+	write(replay.Count50)
+	write(replay.CountGeki)
+	write(replay.CountKatu)
+	write(replay.CountMiss)
+	write(replay.Score)
+	write(replay.MaxCombo)
+	write(replay.Fullcombo)
+	write(replay.Mods)
+	writeString(buf, encodeLifeBar(replay.LifebarGraph))
+	write(replay.Timestamp.UTC().UnixNano()/100 + epochTicks)
+	write(int32(len(compressed)))
+	buf.Write(compressed)
+	write(replay.ScoreID)
+
+	return buf.Bytes(), nil
+}
+
+// Save writes the replay to the given path
+func Save(replay *rplpa.Replay, path string) error {
+	data, err := Encode(replay)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// CreateFileName creates stable-like replay file name
+func CreateFileName(replay *rplpa.Replay, mapName string) string {
+	return fmt.Sprintf("%s - %s (%s) OsuStandard.osr", replay.Username, mapName, replay.Timestamp.Local().Format("2006-01-02_15-04"))
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	if s == "" {
+		buf.WriteByte(0)
+		return
+	}
+
+	buf.WriteByte(11)
+	buf.Write(uleb128.Marshal(len(s)))
+	buf.WriteString(s)
+}
+
+func encodeLifeBar(graph []rplpa.LifeBarGraph) string {
+	var builder strings.Builder
+
+	for _, point := range graph {
+		builder.WriteString(strconv.FormatInt(int64(point.Time), 10))
+		builder.WriteByte('|')
+		builder.WriteString(strconv.FormatFloat(float64(point.HP), 'f', -1, 32))
+		builder.WriteByte(',')
+	}
+
+	return builder.String()
+}
+
+func encodeFrames(frames []*rplpa.ReplayData) []byte {
+	var builder strings.Builder
+
+	for _, frame := range frames {
+		builder.WriteString(strconv.FormatInt(frame.Time, 10))
+		builder.WriteByte('|')
+		builder.WriteString(strconv.FormatFloat(float64(frame.MouseX), 'f', -1, 32))
+		builder.WriteByte('|')
+		builder.WriteString(strconv.FormatFloat(float64(frame.MouseY), 'f', -1, 32))
+		builder.WriteByte('|')
+		builder.WriteString(strconv.Itoa(EncodeKeys(frame.KeyPressed)))
+		builder.WriteByte(',')
+	}
+
+	return []byte(builder.String())
+}
+
+func compressFrames(frames []*rplpa.ReplayData) ([]byte, error) {
+	raw := encodeFrames(frames)
+
+	buf := new(bytes.Buffer)
+
+	writer := lzma.NewWriterSize(buf, int64(len(raw)))
+
+	if _, err := writer.Write(raw); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func calculateReplayMD5(replay *rplpa.Replay) string {
+	hash := md5.Sum([]byte(fmt.Sprintf("%dp%do%do%dt%da%dr%de%sy%do%du%s%d%s", replay.Count100+replay.Count300, replay.Count50, replay.CountGeki, replay.CountKatu, replay.CountMiss, replay.MaxCombo, replay.Score, replay.BeatmapMD5, replay.Mods, replay.Score, replay.Username, time.Now().UnixNano(), "danser")))
+	return hex.EncodeToString(hash[:])
+}
+
+// EncodeKeys converts pressed keys to osu! key bits
+func EncodeKeys(keys *rplpa.KeyPressed) (state int) {
+	if keys == nil {
+		return
+	}
+
+	if keys.LeftClick {
+		state |= rplpa.LEFTCLICK
+	}
+
+	if keys.RightClick {
+		state |= rplpa.RIGHTCLICK
+	}
+
+	if keys.Key1 {
+		state |= rplpa.KEY1
+	}
+
+	if keys.Key2 {
+		state |= rplpa.KEY2
+	}
+
+	if keys.Smoke {
+		state |= rplpa.SMOKE
+	}
+
+	return
+}
@@ -0,0 +1,342 @@
+package catch
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+	"sort"
+)
+
+const (
+	PlayfieldWidth = 512.0
+
+	catcherBaseSize   = 106.75
+	allowedCatchRange = 0.8
+	baseDashSpeed     = 1.0
+	baseWalkSpeed     = 0.5
+
+	rngSeed = 1337
+)
+
+// GetCS returns CS with HR and EZ applied
+func GetCS(diff *difficulty.Difficulty) float64 {
+	cs := diff.GetCS()
+
+	if diff.CheckModActive(difficulty.HardRock) {
+		cs = math.Min(cs*1.3, 10)
+	} else if diff.CheckModActive(difficulty.Easy) {
+		cs /= 2
+	}
+
+	return cs
+}
+
+// GetCatcherScale returns the scale of the catcher and fruits
+func GetCatcherScale(cs float64) float64 {
+	return 1 - 0.7*(cs-5)/5
+}
+
+// GetCatchWidth returns the width of catcher's plate that can catch objects
+func GetCatchWidth(cs float64) float64 {
+	return catcherBaseSize * math.Abs(GetCatcherScale(cs)) * allowedCatchRange
+}
+
+// ConvertBeatMap creates osu!catch objects from beatmap's hit objects, native osu!catch maps are stored the same way as osu!standard ones.
+// Random offsets are generated with stable's seeded generator in the same order as in stable, so fruits land in the same places.
+func ConvertBeatMap(beatMap *beatmap.BeatMap, diff *difficulty.Difficulty) []*Object {
+	converter := &converter{
+		rng:      newLegacyRandom(rngSeed),
+		hardRock: diff.CheckModActive(difficulty.HardRock),
+	}
+
+	hitObjects := beatMap.HitObjects
+
+	catchObjects := make([]*Object, 0, len(hitObjects))
+
+	for i, o := range hitObjects {
+		lastInCombo := i == len(hitObjects)-1 || hitObjects[i+1].IsNewCombo()
+
+		switch obj := o.(type) {
+		case *objects.Circle:
+			catchObjects = append(catchObjects, converter.convertCircle(obj, lastInCombo))
+		case *objects.Slider:
+			catchObjects = append(catchObjects, converter.convertSlider(obj, lastInCombo)...)
+		case *objects.Spinner:
+			catchObjects = append(catchObjects, converter.convertSpinner(obj)...)
+		}
+	}
+
+	// Juice streams can overlap with following objects, hyperdash targets have to be found in time order
+	sort.SliceStable(catchObjects, func(i, j int) bool {
+		return catchObjects[i].StartTime < catchObjects[j].StartTime
+	})
+
+	initHyperDash(catchObjects, GetCS(diff))
+
+	return catchObjects
+}
+
+type converter struct {
+	rng      *legacyRandom
+	hardRock bool
+
+	hasLastPosition bool
+	lastPosition    float64
+	lastStartTime   float64
+}
+
+func (c *converter) convertCircle(circle *objects.Circle, lastInCombo bool) *Object {
+	fruit := &Object{
+		Type:        Fruit,
+		StartTime:   circle.StartTime,
+		X:           float64(circle.StartPosRaw.X),
+		OriginalX:   float64(circle.StartPosRaw.X),
+		ComboSet:    circle.GetComboSet(),
+		LastInCombo: lastInCombo,
+	}
+
+	if c.hardRock {
+		c.applyHardRockOffset(fruit)
+	}
+
+	return fruit
+}
+
+func (c *converter) convertSlider(slider *objects.Slider, lastInCombo bool) []*Object {
+	type event struct {
+		time float64
+		typ  Type
+
+		// boundaryOnly events don't create objects, they only split gaps filled with tiny droplets
+		boundaryOnly bool
+	}
+
+	// Stable's legacy last tick is used only for osu!standard scoring, it's not a droplet but still splits tiny droplets
+	legacyLastTick := math.Max(slider.StartTime+(slider.EndTimeLazer-slider.StartTime)/2, slider.EndTimeLazer-36)
+
+	events := []event{{time: slider.StartTime, typ: Fruit}}
+
+	for _, point := range slider.ScorePointsLazer {
+		if point.IsReverse {
+			events = append(events, event{time: point.Time, typ: Fruit})
+		} else if point.Time != legacyLastTick {
+			events = append(events, event{time: point.Time, typ: Droplet})
+		} else {
+			events = append(events, event{time: point.Time, boundaryOnly: true})
+		}
+	}
+
+	events = append(events, event{time: slider.EndTimeLazer, typ: Fruit})
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].time < events[j].time
+	})
+
+	newObject := func(typ Type, time float64) *Object {
+		x := float64(slider.PositionAtLazer(time).X)
+
+		return &Object{
+			Type:      typ,
+			StartTime: time,
+			X:         x,
+			OriginalX: x,
+			ComboSet:  slider.GetComboSet(),
+		}
+	}
+
+	stream := make([]*Object, 0, len(events))
+
+	for i, e := range events {
+		// Tiny droplets fill gaps between ticks, they're spaced by at most 100ms
+		if i > 0 {
+			last := events[i-1]
+
+			if sinceLastTick := float64(int(e.time) - int(last.time)); sinceLastTick > 80 {
+				timeBetweenTiny := sinceLastTick
+				for timeBetweenTiny > 100 {
+					timeBetweenTiny /= 2
+				}
+
+				for t := timeBetweenTiny; t < sinceLastTick; t += timeBetweenTiny {
+					droplet := newObject(TinyDroplet, last.time+t/sinceLastTick*(e.time-last.time))
+					droplet.StartTime = last.time + t
+
+					stream = append(stream, droplet)
+				}
+			}
+		}
+
+		if !e.boundaryOnly {
+			stream = append(stream, newObject(e.typ, e.time))
+		}
+	}
+
+	stream[len(stream)-1].LastInCombo = lastInCombo
+
+	// Stable used the last control point instead of path's end and start time instead of end time here
+	controlPoints := slider.GetControlPoints()
+
+	c.hasLastPosition = true
+	c.lastPosition = float64(controlPoints[len(controlPoints)-1].X)
+	c.lastStartTime = slider.StartTime
+
+	for _, o := range stream {
+		if o.Type == TinyDroplet {
+			o.X = o.OriginalX + mutils.ClampF(float64(c.rng.nextRange(-20, 20)), -o.OriginalX, PlayfieldWidth-o.OriginalX)
+		} else if o.Type == Droplet {
+			c.rng.next() // Stable retrieved a random droplet rotation
+		}
+	}
+
+	return stream
+}
+
+func (c *converter) convertSpinner(spinner *objects.Spinner) []*Object {
+	spacing := spinner.EndTime - spinner.StartTime
+	for spacing > 100 {
+		spacing /= 2
+	}
+
+	if spacing <= 0 {
+		return nil
+	}
+
+	bananas := make([]*Object, 0)
+
+	for t := spinner.StartTime; t <= spinner.EndTime; t += spacing {
+		banana := &Object{
+			Type:      Banana,
+			StartTime: t,
+			X:         c.rng.nextDouble() * PlayfieldWidth,
+		}
+
+		banana.OriginalX = banana.X
+
+		// Stable retrieved random banana type, rotation and colour
+		c.rng.next()
+		c.rng.next()
+		c.rng.next()
+
+		bananas = append(bananas, banana)
+	}
+
+	return bananas
+}
+
+func (c *converter) applyHardRockOffset(fruit *Object) {
+	position := fruit.OriginalX
+
+	if !c.hasLastPosition {
+		c.hasLastPosition = true
+		c.lastPosition = position
+		c.lastStartTime = fruit.StartTime
+
+		return
+	}
+
+	positionDiff := position - c.lastPosition
+
+	// Stable calculated time differences as ints which affects randomisation
+	timeDiff := int(fruit.StartTime - c.lastStartTime)
+
+	if timeDiff > 1000 {
+		c.lastPosition = position
+		c.lastStartTime = fruit.StartTime
+
+		return
+	}
+
+	if positionDiff == 0 {
+		fruit.X = c.applyRandomOffset(position, float64(timeDiff)/4)
+		return
+	}
+
+	if math.Abs(positionDiff) < float64(timeDiff/3) {
+		position = applyOffset(position, positionDiff)
+	}
+
+	fruit.X = position
+
+	c.lastPosition = position
+	c.lastStartTime = fruit.StartTime
+}
+
+func (c *converter) applyRandomOffset(position, maxOffset float64) float64 {
+	right := c.rng.nextBool()
+	random := math.Min(20, float64(float32(c.rng.nextRangeF(0, math.Max(0, maxOffset)))))
+
+	if right {
+		if position+random <= PlayfieldWidth {
+			return position + random
+		}
+
+		return position - random
+	}
+
+	if position-random >= 0 {
+		return position - random
+	}
+
+	return position + random
+}
+
+func applyOffset(position, amount float64) float64 {
+	if amount > 0 {
+		if position+amount < PlayfieldWidth {
+			return position + amount
+		}
+	} else if position+amount > 0 {
+		return position + amount
+	}
+
+	return position
+}
+
+// initHyperDash marks fruits and droplets that can't be reached with a normal dash, stable used the full catcher width here instead of the catching range
+func initHyperDash(catchObjects []*Object, cs float64) {
+	palpable := make([]*Object, 0, len(catchObjects))
+
+	for _, o := range catchObjects {
+		if o.Type == Fruit || o.Type == Droplet {
+			palpable = append(palpable, o)
+		}
+	}
+
+	halfCatcherWidth := GetCatchWidth(cs) / 2 / allowedCatchRange
+
+	lastDirection := 0
+	lastExcess := halfCatcherWidth
+
+	for i := 0; i < len(palpable)-1; i++ {
+		current, next := palpable[i], palpable[i+1]
+
+		direction := -1
+		if next.X > current.X {
+			direction = 1
+		}
+
+		// Stable truncated times to ints and gave 1/4th of a frame of grace time
+		timeToNext := float64(int(next.StartTime)-int(current.StartTime)) - 1000.0/60/4
+
+		distanceToNext := math.Abs(next.X - current.X)
+		if lastDirection == direction {
+			distanceToNext -= lastExcess
+		} else {
+			distanceToNext -= halfCatcherWidth
+		}
+
+		distanceToHyper := float64(float32(timeToNext*baseDashSpeed - distanceToNext))
+
+		if distanceToHyper < 0 {
+			current.HyperDashTarget = next
+			lastExcess = halfCatcherWidth
+		} else {
+			current.DistanceToHyperDash = distanceToHyper
+			lastExcess = mutils.ClampF(distanceToHyper, 0, halfCatcherWidth)
+		}
+
+		lastDirection = direction
+	}
+}
@@ -0,0 +1,61 @@
+package catch
+
+import (
+	"math"
+)
+
+// Frame is catcher's position in osu!pixels at the given time
+type Frame struct {
+	Time    float64
+	X       float64
+	Dashing bool
+}
+
+// GenerateAutoplay creates frames that move the catcher straight to every object, dash is held when walking is too slow
+func GenerateAutoplay(catchObjects []*Object) []Frame {
+	frames := []Frame{{Time: 0, X: PlayfieldWidth / 2}}
+
+	for _, o := range catchObjects {
+		last := frames[len(frames)-1]
+
+		if o.StartTime <= last.Time {
+			continue
+		}
+
+		// Catcher stays in place until it has to start moving
+		if o.StartTime-last.Time > 1000 {
+			frames = append(frames, Frame{Time: o.StartTime - 500, X: last.X})
+			last = frames[len(frames)-1]
+		}
+
+		// Dash state of a frame applies until the next one
+		frames[len(frames)-1].Dashing = math.Abs(o.X-last.X)/(o.StartTime-last.Time) > baseWalkSpeed
+
+		frames = append(frames, Frame{Time: o.StartTime, X: o.X})
+	}
+
+	return frames
+}
+
+// PositionAt returns catcher's position interpolated between frames, index is the index of the last frame at or before the given time
+func PositionAt(frames []Frame, index int, time float64) (x float64, dashing bool) {
+	if len(frames) == 0 {
+		return PlayfieldWidth / 2, false
+	}
+
+	if index < 0 {
+		return frames[0].X, frames[0].Dashing
+	}
+
+	frame := frames[index]
+
+	if index == len(frames)-1 || frames[index+1].Time <= frame.Time {
+		return frame.X, frame.Dashing
+	}
+
+	next := frames[index+1]
+
+	t := (time - frame.Time) / (next.Time - frame.Time)
+
+	return frame.X + (next.X-frame.X)*math.Min(t, 1), frame.Dashing
+}
@@ -0,0 +1,49 @@
+package catch
+
+type Type uint8
+
+const (
+	Fruit = Type(iota)
+	Droplet
+	TinyDroplet
+	Banana
+)
+
+func (t Type) String() string {
+	switch t {
+	case Droplet:
+		return "Droplet"
+	case TinyDroplet:
+		return "TinyDroplet"
+	case Banana:
+		return "Banana"
+	}
+
+	return "Fruit"
+}
+
+// Object is a single catchable object, juice streams and banana showers are flattened to their fruits, droplets and bananas
+type Object struct {
+	Type      Type
+	StartTime float64
+
+	// X is the final position in osu!pixels, it includes random offsets of tiny droplets, bananas and HR
+	X         float64
+	OriginalX float64
+
+	ComboSet int64
+	// LastInCombo is true for the last fruit of a combo, catching it clears the plate
+	LastInCombo bool
+
+	// HyperDashTarget is set if catcher can't reach the next fruit or droplet without a hyperdash
+	HyperDashTarget     *Object
+	DistanceToHyperDash float64
+
+	Judged bool
+	Caught bool
+}
+
+// IsHyperDash returns true if catching the object starts a hyperdash
+func (object *Object) IsHyperDash() bool {
+	return object.HyperDashTarget != nil
+}
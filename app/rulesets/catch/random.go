@@ -0,0 +1,59 @@
+package catch
+
+const intMask = 0x7FFFFFFF
+
+// legacyRandom is a xorshift generator used by osu!stable, fruit offsets have to be generated with it to match stable's maps
+type legacyRandom struct {
+	x, y, z, w uint32
+
+	bitBuffer uint32
+	bitIndex  int
+}
+
+func newLegacyRandom(seed int) *legacyRandom {
+	return &legacyRandom{
+		x:        uint32(seed),
+		y:        842502087,
+		z:        3579807591,
+		w:        273326509,
+		bitIndex: 32,
+	}
+}
+
+func (r *legacyRandom) nextUInt() uint32 {
+	t := r.x ^ (r.x << 11)
+	r.x, r.y, r.z = r.y, r.z, r.w
+	r.w = r.w ^ (r.w >> 19) ^ t ^ (t >> 8)
+
+	return r.w
+}
+
+func (r *legacyRandom) next() int {
+	return int(intMask & r.nextUInt())
+}
+
+func (r *legacyRandom) nextDouble() float64 {
+	return float64(r.next()) / (float64(intMask) + 1)
+}
+
+func (r *legacyRandom) nextRange(lower, upper int) int {
+	return int(float64(lower) + r.nextDouble()*float64(upper-lower))
+}
+
+func (r *legacyRandom) nextRangeF(lower, upper float64) float64 {
+	return lower + r.nextDouble()*(upper-lower)
+}
+
+func (r *legacyRandom) nextBool() bool {
+	if r.bitIndex == 32 {
+		r.bitBuffer = r.nextUInt()
+		r.bitIndex = 1
+
+		return r.bitBuffer&1 == 1
+	}
+
+	r.bitIndex++
+	r.bitBuffer >>= 1
+
+	return r.bitBuffer&1 == 1
+}
@@ -0,0 +1,172 @@
+package catch
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"log"
+	"math"
+)
+
+type catchListener func(time float64, object *Object, caught bool, catcherX float64)
+
+type CatchRuleset struct {
+	beatMap *beatmap.BeatMap
+	diff    *difficulty.Difficulty
+
+	cs         float64
+	catchWidth float64
+
+	objects     []*Object
+	objectIndex int
+
+	lastTime float64
+	catcherX float64
+	dashing  bool
+
+	// hyperDashTarget is the object the catcher is hyperdashing to
+	hyperDashTarget *Object
+
+	score *scoreProcessor
+
+	ended bool
+
+	listener catchListener
+}
+
+func NewCatchRuleset(beatMap *beatmap.BeatMap, mods difficulty.Modifier) *CatchRuleset {
+	diff := difficulty.NewDifficulty(beatMap.Diff.GetHP(), beatMap.Diff.GetCS(), beatMap.Diff.GetOD(), beatMap.Diff.GetAR())
+	diff.SetMods(mods)
+	diff.SetCustomSpeed(beatMap.Diff.CustomSpeed)
+
+	ruleset := &CatchRuleset{
+		beatMap:  beatMap,
+		diff:     diff,
+		cs:       GetCS(diff),
+		objects:  ConvertBeatMap(beatMap, diff),
+		lastTime: math.Inf(-1),
+		catcherX: PlayfieldWidth / 2,
+	}
+
+	ruleset.catchWidth = GetCatchWidth(ruleset.cs)
+	ruleset.score = newScoreProcessor(beatMap, diff)
+
+	counts := make(map[Type]int)
+	hyperDashes := 0
+
+	for _, o := range ruleset.objects {
+		counts[o.Type]++
+
+		if o.IsHyperDash() {
+			hyperDashes++
+		}
+	}
+
+	mode := "native"
+	if beatMap.Mode != beatmap.ModeCatch {
+		mode = "converted"
+	}
+
+	log.Println(fmt.Sprintf("osu!catch ruleset (%s): CS %.1f, catch width %.2f, %d fruits, %d droplets, %d tiny droplets, %d bananas, %d hyperdashes", mode, ruleset.cs, ruleset.catchWidth, counts[Fruit], counts[Droplet], counts[TinyDroplet], counts[Banana], hyperDashes))
+
+	return ruleset
+}
+
+// Update moves the catcher to x at the given time and judges objects that reached the plate since the last update,
+// catcher's position at the time of each object is interpolated between updates
+func (ruleset *CatchRuleset) Update(time, x float64, dashing bool) {
+	for ; ruleset.objectIndex < len(ruleset.objects); ruleset.objectIndex++ {
+		object := ruleset.objects[ruleset.objectIndex]
+
+		if object.StartTime > time {
+			break
+		}
+
+		catcherX := x
+		if time > ruleset.lastTime && !math.IsInf(ruleset.lastTime, -1) {
+			progress := math.Max(0, (object.StartTime-ruleset.lastTime)/(time-ruleset.lastTime))
+			catcherX = ruleset.catcherX + (x-ruleset.catcherX)*progress
+		}
+
+		ruleset.judge(object, catcherX)
+	}
+
+	if ruleset.hyperDashTarget != nil && ruleset.hyperDashTarget.Judged {
+		ruleset.hyperDashTarget = nil
+	}
+
+	ruleset.lastTime = time
+	ruleset.catcherX = x
+	ruleset.dashing = dashing
+}
+
+func (ruleset *CatchRuleset) judge(object *Object, catcherX float64) {
+	caught := math.Abs(object.X-catcherX) <= ruleset.catchWidth/2
+
+	object.Judged = true
+	object.Caught = caught
+
+	if caught && object.Type != Banana && object.Type != TinyDroplet {
+		ruleset.hyperDashTarget = object.HyperDashTarget
+	}
+
+	ruleset.score.addResult(object, caught)
+
+	if ruleset.listener != nil {
+		ruleset.listener(object.StartTime, object, caught, catcherX)
+	}
+
+	if ruleset.objectIndex == len(ruleset.objects)-1 && !ruleset.ended {
+		ruleset.ended = true
+
+		sc := ruleset.score.score
+
+		log.Println(fmt.Sprintf("osu!catch play finished: score %d, accuracy %.2f%%, max combo %d, fruits: %d, droplets: %d, tiny droplets: %d, missed tiny droplets: %d, miss: %d, bananas: %d", sc.Score, sc.Accuracy, sc.MaxCombo, sc.CountFruits, sc.CountDroplets, sc.CountTinyDroplets, sc.CountTinyMiss, sc.CountMiss, sc.CountBananas))
+	}
+}
+
+func (ruleset *CatchRuleset) SetListener(listener catchListener) {
+	ruleset.listener = listener
+}
+
+func (ruleset *CatchRuleset) GetObjects() []*Object {
+	return ruleset.objects
+}
+
+// GetCS returns CS with mods applied
+func (ruleset *CatchRuleset) GetCS() float64 {
+	return ruleset.cs
+}
+
+// GetPreempt returns the time objects take to fall down to the plate
+func (ruleset *CatchRuleset) GetPreempt() float64 {
+	return ruleset.diff.Preempt
+}
+
+func (ruleset *CatchRuleset) GetCatchWidth() float64 {
+	return ruleset.catchWidth
+}
+
+func (ruleset *CatchRuleset) GetCatcherX() float64 {
+	return ruleset.catcherX
+}
+
+func (ruleset *CatchRuleset) IsDashing() bool {
+	return ruleset.dashing
+}
+
+func (ruleset *CatchRuleset) IsHyperDashing() bool {
+	return ruleset.hyperDashTarget != nil
+}
+
+func (ruleset *CatchRuleset) GetScore() Score {
+	return *ruleset.score.score
+}
+
+func (ruleset *CatchRuleset) GetBeatMap() *beatmap.BeatMap {
+	return ruleset.beatMap
+}
+
+func (ruleset *CatchRuleset) IsEnded() bool {
+	return ruleset.ended
+}
@@ -0,0 +1,138 @@
+package catch
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+type Score struct {
+	Score    int64
+	Accuracy float64
+	Grade    osu.Grade
+	Combo    uint
+	MaxCombo uint
+	// CountFruits, CountDroplets and CountTinyDroplets are shown as 300s, 100s and 50s in stable
+	CountFruits       uint
+	CountDroplets     uint
+	CountTinyDroplets uint
+	// CountTinyMiss is the amount of missed tiny droplets, they don't break combo
+	CountTinyMiss uint
+	CountMiss     uint
+	CountBananas  uint
+}
+
+// scoreProcessor implements stable's osu!catch ScoreV1, fruits and droplets use osu!standard's combo formula while tiny droplets and bananas give flat score
+type scoreProcessor struct {
+	score *Score
+
+	modMultiplier   float64
+	scoreMultiplier float64
+	hidden          bool
+}
+
+func newScoreProcessor(beatMap *beatmap.BeatMap, diff *difficulty.Difficulty) *scoreProcessor {
+	return &scoreProcessor{
+		score:           &Score{Accuracy: 100, Grade: osu.NONE},
+		modMultiplier:   diff.GetScoreMultiplier(),
+		scoreMultiplier: getDifficultyMultiplier(beatMap),
+		hidden:          diff.CheckModActive(difficulty.Hidden | difficulty.Flashlight),
+	}
+}
+
+// getDifficultyMultiplier returns the same difficulty multiplier as in osu!standard
+func getDifficultyMultiplier(beatMap *beatmap.BeatMap) float64 {
+	if len(beatMap.HitObjects) == 0 {
+		return 0
+	}
+
+	pauses := int64(0)
+	for _, p := range beatMap.Pauses {
+		pauses += int64(p.GetEndTime() - p.GetStartTime())
+	}
+
+	drainTime := float32((int64(beatMap.HitObjects[len(beatMap.HitObjects)-1].GetEndTime()) - int64(beatMap.HitObjects[0].GetStartTime()) - pauses) / 1000)
+
+	return math.RoundToEven((float64(float32(beatMap.Diff.GetHP())) + float64(float32(beatMap.Diff.GetOD())) + float64(float32(beatMap.Diff.GetCS())) + float64(mutils.ClampF(float32(len(beatMap.HitObjects))/drainTime*8, 0, 16))) / 38 * 5)
+}
+
+func (s *scoreProcessor) addResult(object *Object, caught bool) {
+	sc := s.score
+
+	switch object.Type {
+	case Fruit, Droplet:
+		if !caught {
+			sc.CountMiss++
+			sc.Combo = 0
+
+			break
+		}
+
+		value := int64(300)
+		if object.Type == Droplet {
+			value = 100
+			sc.CountDroplets++
+		} else {
+			sc.CountFruits++
+		}
+
+		combo := mutils.Max(int64(sc.Combo)-1, 0)
+
+		sc.Score += value + int64(float64(value)*float64(combo)*s.scoreMultiplier*s.modMultiplier/25.0)
+
+		sc.Combo++
+		sc.MaxCombo = mutils.Max(sc.MaxCombo, sc.Combo)
+	case TinyDroplet:
+		if caught {
+			sc.CountTinyDroplets++
+			sc.Score += 10
+		} else {
+			sc.CountTinyMiss++
+		}
+	case Banana:
+		if caught {
+			sc.CountBananas++
+			sc.Score += 1100
+		}
+	}
+
+	s.updateAccuracy()
+}
+
+func (s *scoreProcessor) updateAccuracy() {
+	sc := s.score
+
+	caught := sc.CountFruits + sc.CountDroplets + sc.CountTinyDroplets
+
+	total := caught + sc.CountTinyMiss + sc.CountMiss
+	if total == 0 {
+		return
+	}
+
+	sc.Accuracy = 100 * float64(caught) / float64(total)
+
+	switch {
+	case sc.Accuracy >= 100:
+		sc.Grade = osu.SS
+	case sc.Accuracy > 98:
+		sc.Grade = osu.S
+	case sc.Accuracy > 94:
+		sc.Grade = osu.A
+	case sc.Accuracy > 90:
+		sc.Grade = osu.B
+	case sc.Accuracy > 85:
+		sc.Grade = osu.C
+	default:
+		sc.Grade = osu.D
+	}
+
+	if s.hidden {
+		if sc.Grade == osu.SS {
+			sc.Grade = osu.SSH
+		} else if sc.Grade == osu.S {
+			sc.Grade = osu.SH
+		}
+	}
+}
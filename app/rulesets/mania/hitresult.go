@@ -0,0 +1,90 @@
+package mania
+
+type HitResult uint8
+
+const (
+	Miss = HitResult(iota)
+	Hit50
+	Hit100
+	Hit200
+	Hit300
+	HitMax // Rainbow 300, called 300g or geki in stable
+)
+
+// ScoreValue returns the value judgement adds to base score in stable's ScoreV1
+func (r HitResult) ScoreValue() int64 {
+	switch r {
+	case Hit50:
+		return 50
+	case Hit100:
+		return 100
+	case Hit200:
+		return 200
+	case Hit300:
+		return 300
+	case HitMax:
+		return 320
+	}
+
+	return 0
+}
+
+// AccuracyValue returns the value judgement adds to accuracy, rainbow 300s are worth as much as normal ones
+func (r HitResult) AccuracyValue() int64 {
+	if r == HitMax {
+		return 300
+	}
+
+	return r.ScoreValue()
+}
+
+// bonusValue returns the multiplier of bonus score part
+func (r HitResult) bonusValue() float64 {
+	switch r {
+	case Hit50:
+		return 4
+	case Hit100:
+		return 8
+	case Hit200:
+		return 16
+	case Hit300, HitMax:
+		return 32
+	}
+
+	return 0
+}
+
+// bonusChange returns how much judgement changes the bonus, stable calls positive values bonus and negative ones punishment
+func (r HitResult) bonusChange() float64 {
+	switch r {
+	case Hit50:
+		return -44
+	case Hit100:
+		return -24
+	case Hit200:
+		return -8
+	case Hit300:
+		return 1
+	case HitMax:
+		return 2
+	}
+
+	return -100
+}
+
+func (r HitResult) String() string {
+	switch r {
+	case Hit50:
+		return "50"
+	case Hit100:
+		return "100"
+	case Hit200:
+		return "200"
+	case Hit300:
+		return "300"
+	case HitMax:
+		return "MAX"
+	}
+
+	return "Miss"
+}
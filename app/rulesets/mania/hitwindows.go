@@ -0,0 +1,82 @@
+package mania
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"math"
+)
+
+// HitWindows holds osu!mania judgement windows in beatmap time, every window is the maximum absolute offset for its judgement
+type HitWindows struct {
+	Max  float64
+	W300 float64
+	W200 float64
+	W100 float64
+	W50  float64
+	Miss float64
+}
+
+// NewHitWindows calculates stable's hit windows. Unlike osu!standard, HR and EZ scale windows directly instead of changing OD,
+// and rate changing mods don't change windows in real time, so they are scaled by playback speed.
+func NewHitWindows(diff *difficulty.Difficulty) HitWindows {
+	od := diff.GetOD()
+
+	scale := 1.0
+
+	if diff.CheckModActive(difficulty.HardRock) {
+		scale /= 1.4
+	} else if diff.CheckModActive(difficulty.Easy) {
+		scale *= 1.4
+	}
+
+	window := func(base float64) float64 {
+		return math.Floor(base*scale) * diff.Speed
+	}
+
+	return HitWindows{
+		Max:  window(16),
+		W300: window(64 - 3*od),
+		W200: window(97 - 3*od),
+		W100: window(127 - 3*od),
+		W50:  window(151 - 3*od),
+		Miss: window(188 - 3*od),
+	}
+}
+
+// ResultFor returns judgement for the given offset, Miss is returned for early presses outside of 50's window
+func (w HitWindows) ResultFor(offset float64) HitResult {
+	offset = math.Abs(offset)
+
+	switch {
+	case offset <= w.Max:
+		return HitMax
+	case offset <= w.W300:
+		return Hit300
+	case offset <= w.W200:
+		return Hit200
+	case offset <= w.W100:
+		return Hit100
+	case offset <= w.W50:
+		return Hit50
+	}
+
+	return Miss
+}
+
+// HoldResultFor returns judgement of a hold note, stable judges it once with combined head and release offsets
+func (w HitWindows) HoldResultFor(headOffset, tailOffset float64) HitResult {
+	head := math.Abs(headOffset)
+	combined := head + math.Abs(tailOffset)
+
+	switch {
+	case head <= w.Max*1.2 && combined <= w.Max*2.4:
+		return HitMax
+	case head <= w.W300*1.1 && combined <= w.W300*2.2:
+		return Hit300
+	case head <= w.W200 && combined <= w.W200*2:
+		return Hit200
+	case head <= w.W100 && combined <= w.W100*2:
+		return Hit100
+	}
+
+	return Hit50
+}
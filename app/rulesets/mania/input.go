@@ -0,0 +1,73 @@
+package mania
+
+import (
+	"sort"
+)
+
+// autoReleaseTime is the time after which autoplay releases a normal note
+const autoReleaseTime = 40.0
+
+// KeyFrame is the state of all columns at the given time, bit N of Keys is set if column N is held
+type KeyFrame struct {
+	Time float64
+	Keys uint32
+}
+
+// GenerateAutoplay creates key frames that hit every note perfectly
+func GenerateAutoplay(notes []*Note, keys int) []KeyFrame {
+	type keyEvent struct {
+		time    float64
+		column  int
+		pressed bool
+	}
+
+	events := make([]keyEvent, 0, len(notes)*2)
+
+	lastNotes := make([]*Note, keys)
+	releases := make([]int, keys) // indices of release events of last notes
+
+	for _, note := range notes {
+		releaseTime := note.EndTime
+		if !note.IsHold {
+			releaseTime += autoReleaseTime
+		}
+
+		// Release previous note earlier if it would overlap with the current one
+		if last := lastNotes[note.Column]; last != nil && events[releases[note.Column]].time >= note.StartTime {
+			events[releases[note.Column]].time = (last.EndTime + note.StartTime) / 2
+		}
+
+		events = append(events, keyEvent{note.StartTime, note.Column, true}, keyEvent{releaseTime, note.Column, false})
+
+		lastNotes[note.Column] = note
+		releases[note.Column] = len(events) - 1
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].time == events[j].time {
+			return !events[i].pressed && events[j].pressed
+		}
+
+		return events[i].time < events[j].time
+	})
+
+	keyFrames := make([]KeyFrame, 0, len(events)+1)
+
+	state := uint32(0)
+
+	for _, e := range events {
+		if e.pressed {
+			state |= 1 << e.column
+		} else {
+			state &^= 1 << e.column
+		}
+
+		if len(keyFrames) > 0 && keyFrames[len(keyFrames)-1].Time == e.time {
+			keyFrames[len(keyFrames)-1].Keys = state
+		} else {
+			keyFrames = append(keyFrames, KeyFrame{e.time, state})
+		}
+	}
+
+	return keyFrames
+}
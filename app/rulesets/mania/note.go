@@ -0,0 +1,57 @@
+package mania
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+type soundObject interface {
+	PlaySound()
+}
+
+// Note is a single note or a hold note placed in a column
+type Note struct {
+	Object objects.IHitObject
+
+	Column    int
+	StartTime float64
+	EndTime   float64
+	IsHold    bool
+
+	// Judged is true if note received its final judgement
+	Judged bool
+	Result HitResult
+
+	// HeadHit is true if hold note's head was pressed in time
+	HeadHit bool
+	// Holding is true while hold note's key is held after hitting its head
+	Holding bool
+	// Broken is true if hold note was released too early
+	Broken bool
+
+	headOffset float64
+}
+
+func newNote(object objects.IHitObject, keys int) *Note {
+	_, isHold := object.(*objects.HoldNote)
+
+	return &Note{
+		Object:    object,
+		Column:    GetColumn(object.GetStartPosition().X64(), keys),
+		StartTime: object.GetStartTime(),
+		EndTime:   object.GetEndTime(),
+		IsHold:    isHold,
+	}
+}
+
+// GetColumn converts x position of a hit object to mania column
+func GetColumn(x float64, keys int) int {
+	return mutils.Clamp(int(math.Floor(x*float64(keys)/512)), 0, keys-1)
+}
+
+func (note *Note) playSound() {
+	if s, ok := note.Object.(soundObject); ok {
+		s.PlaySound()
+	}
+}
@@ -0,0 +1,248 @@
+package mania
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"log"
+	"math"
+)
+
+const maxKeys = 18
+
+type hitListener func(time float64, note *Note, result HitResult, comboBreak bool)
+
+type ManiaRuleset struct {
+	beatMap *beatmap.BeatMap
+	diff    *difficulty.Difficulty
+
+	keys    int
+	windows HitWindows
+
+	notes   []*Note
+	columns [][]*Note
+	// indices of the first not judged notes in each column
+	columnIndices []int
+
+	keyState []bool
+
+	score *scoreProcessor
+
+	judged int
+	ended  bool
+	muted  bool
+
+	hitListener hitListener
+}
+
+// GetKeyCount returns the number of columns of a native osu!mania map, stable stores it as CircleSize
+func GetKeyCount(beatMap *beatmap.BeatMap) int {
+	return mutils.Clamp(int(math.Round(beatMap.Diff.GetCS())), 1, maxKeys)
+}
+
+func NewManiaRuleset(beatMap *beatmap.BeatMap, mods difficulty.Modifier) *ManiaRuleset {
+	diff := difficulty.NewDifficulty(beatMap.Diff.GetHP(), beatMap.Diff.GetCS(), beatMap.Diff.GetOD(), beatMap.Diff.GetAR())
+	diff.SetMods(mods)
+	diff.SetCustomSpeed(beatMap.Diff.CustomSpeed)
+
+	ruleset := &ManiaRuleset{
+		beatMap: beatMap,
+		diff:    diff,
+		keys:    GetKeyCount(beatMap),
+		windows: NewHitWindows(diff),
+	}
+
+	ruleset.columns = make([][]*Note, ruleset.keys)
+	ruleset.columnIndices = make([]int, ruleset.keys)
+	ruleset.keyState = make([]bool, ruleset.keys)
+
+	for _, o := range beatMap.HitObjects {
+		note := newNote(o, ruleset.keys)
+
+		ruleset.notes = append(ruleset.notes, note)
+		ruleset.columns[note.Column] = append(ruleset.columns[note.Column], note)
+	}
+
+	ruleset.score = newScoreProcessor(len(ruleset.notes), mods)
+
+	log.Println(fmt.Sprintf("osu!mania ruleset: %dK, OD %.1f, windows: MAX %.0fms, 300 %.0fms, 200 %.0fms, 100 %.0fms, 50 %.0fms", ruleset.keys, diff.GetOD(), ruleset.windows.Max, ruleset.windows.W300, ruleset.windows.W200, ruleset.windows.W100, ruleset.windows.W50))
+
+	return ruleset
+}
+
+// UpdateKeys processes key state changes at the given time, bit N of keys is set if column N is held
+func (ruleset *ManiaRuleset) UpdateKeys(time float64, keys uint32) {
+	ruleset.Update(time)
+
+	for column := 0; column < ruleset.keys; column++ {
+		pressed := keys&(1<<column) > 0
+
+		if pressed == ruleset.keyState[column] {
+			continue
+		}
+
+		ruleset.keyState[column] = pressed
+
+		if pressed {
+			ruleset.press(time, column)
+		} else {
+			ruleset.release(time, column)
+		}
+	}
+}
+
+func (ruleset *ManiaRuleset) press(time float64, column int) {
+	note := ruleset.nextNote(column)
+	if note == nil {
+		if notes := ruleset.columns[column]; len(notes) > 0 {
+			ruleset.playSound(notes[len(notes)-1])
+		}
+
+		return
+	}
+
+	ruleset.playSound(note)
+
+	offset := time - note.StartTime
+
+	if offset < -ruleset.windows.Miss || note.HeadHit {
+		return
+	}
+
+	if note.IsHold {
+		if ruleset.windows.ResultFor(offset) == Miss {
+			ruleset.judge(time, note, Miss, false)
+			return
+		}
+
+		note.HeadHit = true
+		note.Holding = true
+		note.headOffset = offset
+
+		return
+	}
+
+	ruleset.judge(time, note, ruleset.windows.ResultFor(offset), false)
+}
+
+func (ruleset *ManiaRuleset) release(time float64, column int) {
+	note := ruleset.nextNote(column)
+	if note == nil || !note.Holding {
+		return
+	}
+
+	note.Holding = false
+
+	tailOffset := time - note.EndTime
+
+	result := ruleset.windows.HoldResultFor(note.headOffset, tailOffset)
+
+	broken := tailOffset < -ruleset.windows.W50
+	if broken {
+		note.Broken = true
+		result = Hit50
+	}
+
+	ruleset.judge(time, note, result, broken)
+}
+
+// Update judges notes that weren't hit or released in time
+func (ruleset *ManiaRuleset) Update(time float64) {
+	for column := range ruleset.columns {
+		for note := ruleset.nextNote(column); note != nil; note = ruleset.nextNote(column) {
+			if note.HeadHit {
+				// Hold note was held past its end, it's judged as if it was released at the edge of 50's window
+				if time <= note.EndTime+ruleset.windows.W50 {
+					break
+				}
+
+				note.Holding = false
+
+				ruleset.judge(note.EndTime+ruleset.windows.W50, note, Hit50, false)
+
+				continue
+			}
+
+			if time <= note.StartTime+ruleset.windows.W50 {
+				break
+			}
+
+			ruleset.judge(note.StartTime+ruleset.windows.W50, note, Miss, false)
+		}
+	}
+}
+
+func (ruleset *ManiaRuleset) judge(time float64, note *Note, result HitResult, comboBreak bool) {
+	note.Judged = true
+	note.Result = result
+
+	ruleset.columnIndices[note.Column]++
+
+	ruleset.score.addResult(result, comboBreak)
+
+	if ruleset.hitListener != nil {
+		ruleset.hitListener(time, note, result, comboBreak || result == Miss)
+	}
+
+	ruleset.judged++
+
+	if ruleset.judged == len(ruleset.notes) && !ruleset.ended {
+		ruleset.ended = true
+
+		sc := ruleset.score.score
+
+		log.Println(fmt.Sprintf("osu!mania play finished: score %d, accuracy %.2f%%, max combo %d, MAX: %d, 300: %d, 200: %d, 100: %d, 50: %d, miss: %d", sc.Score, sc.Accuracy, sc.MaxCombo, sc.CountMax, sc.Count300, sc.Count200, sc.Count100, sc.Count50, sc.CountMiss))
+	}
+}
+
+func (ruleset *ManiaRuleset) nextNote(column int) *Note {
+	if ruleset.columnIndices[column] >= len(ruleset.columns[column]) {
+		return nil
+	}
+
+	return ruleset.columns[column][ruleset.columnIndices[column]]
+}
+
+func (ruleset *ManiaRuleset) playSound(note *Note) {
+	if !ruleset.muted {
+		note.playSound()
+	}
+}
+
+// Mute disables hitsounds, used when several players are judged at once and only one of them is shown
+func (ruleset *ManiaRuleset) Mute() {
+	ruleset.muted = true
+}
+
+func (ruleset *ManiaRuleset) SetListener(listener hitListener) {
+	ruleset.hitListener = listener
+}
+
+func (ruleset *ManiaRuleset) GetKeys() int {
+	return ruleset.keys
+}
+
+func (ruleset *ManiaRuleset) GetNotes() []*Note {
+	return ruleset.notes
+}
+
+func (ruleset *ManiaRuleset) GetHitWindows() HitWindows {
+	return ruleset.windows
+}
+
+func (ruleset *ManiaRuleset) IsKeyPressed(column int) bool {
+	return ruleset.keyState[column]
+}
+
+func (ruleset *ManiaRuleset) GetScore() Score {
+	return *ruleset.score.score
+}
+
+func (ruleset *ManiaRuleset) GetBeatMap() *beatmap.BeatMap {
+	return ruleset.beatMap
+}
+
+func (ruleset *ManiaRuleset) IsEnded() bool {
+	return ruleset.ended
+}
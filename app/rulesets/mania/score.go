@@ -0,0 +1,128 @@
+package mania
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+const maxScore = 1000000.0
+
+type Score struct {
+	Score     int64
+	Accuracy  float64
+	Grade     osu.Grade
+	Combo     uint
+	MaxCombo  uint
+	CountMax  uint
+	Count300  uint
+	Count200  uint
+	Count100  uint
+	Count50   uint
+	CountMiss uint
+}
+
+// scoreProcessor implements stable's osu!mania ScoreV1 where half of the score comes from judgements
+// and the other half from bonus which drops on bad judgements and slowly recovers on good ones
+type scoreProcessor struct {
+	score *Score
+
+	notes         int
+	modMultiplier float64
+	hidden        bool
+
+	baseScore  float64
+	bonusScore float64
+	bonus      float64
+}
+
+func newScoreProcessor(notes int, mods difficulty.Modifier) *scoreProcessor {
+	multiplier := 1.0
+
+	// Only difficulty reducing mods change the score in osu!mania
+	for _, mod := range []difficulty.Modifier{difficulty.Easy, difficulty.NoFail, difficulty.HalfTime} {
+		if mods.Active(mod) {
+			multiplier *= 0.5
+		}
+	}
+
+	return &scoreProcessor{
+		score:         &Score{Accuracy: 100, Grade: osu.NONE},
+		notes:         mutils.Max(notes, 1),
+		modMultiplier: multiplier,
+		hidden:        mods.Active(difficulty.Hidden | difficulty.Flashlight | difficulty.FadeIn),
+		bonus:         100,
+	}
+}
+
+func (s *scoreProcessor) addResult(result HitResult, breakCombo bool) {
+	switch result {
+	case HitMax:
+		s.score.CountMax++
+	case Hit300:
+		s.score.Count300++
+	case Hit200:
+		s.score.Count200++
+	case Hit100:
+		s.score.Count100++
+	case Hit50:
+		s.score.Count50++
+	default:
+		s.score.CountMiss++
+	}
+
+	if result == Miss || breakCombo {
+		s.score.Combo = 0
+	} else {
+		s.score.Combo++
+		s.score.MaxCombo = mutils.Max(s.score.MaxCombo, s.score.Combo)
+	}
+
+	noteValue := maxScore * 0.5 / float64(s.notes)
+
+	s.bonus = mutils.ClampF(s.bonus+result.bonusChange(), 0, 100)
+
+	s.baseScore += noteValue * float64(result.ScoreValue()) / 320
+	s.bonusScore += noteValue * result.bonusValue() * math.Sqrt(s.bonus) / 320
+
+	s.score.Score = int64(math.Round((s.baseScore + s.bonusScore) * s.modMultiplier))
+
+	s.updateAccuracy()
+}
+
+func (s *scoreProcessor) updateAccuracy() {
+	sc := s.score
+
+	hits := sc.CountMax + sc.Count300 + sc.Count200 + sc.Count100 + sc.Count50 + sc.CountMiss
+	if hits == 0 {
+		return
+	}
+
+	value := 300*(sc.CountMax+sc.Count300) + 200*sc.Count200 + 100*sc.Count100 + 50*sc.Count50
+
+	sc.Accuracy = 100 * float64(value) / float64(300*hits)
+
+	switch {
+	case sc.Accuracy >= 100:
+		sc.Grade = osu.SS
+	case sc.Accuracy > 95:
+		sc.Grade = osu.S
+	case sc.Accuracy > 90:
+		sc.Grade = osu.A
+	case sc.Accuracy > 80:
+		sc.Grade = osu.B
+	case sc.Accuracy > 70:
+		sc.Grade = osu.C
+	default:
+		sc.Grade = osu.D
+	}
+
+	if s.hidden {
+		if sc.Grade == osu.SS {
+			sc.Grade = osu.SSH
+		} else if sc.Grade == osu.S {
+			sc.Grade = osu.SH
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package osu
+
+import (
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/framework/math/vector"
+)
+
+// MissCause explains why a click was shaken or why an object or slider tick was missed
+type MissCause uint8
+
+const (
+	Notelock = MissCause(iota)
+	TooEarly
+	TooLate
+	TickMissed
+	FollowCircleLeft
+	SliderEndMissed
+)
+
+var missCauseNames = map[MissCause]string{
+	Notelock:         "Notelock",
+	TooEarly:         "Too early",
+	TooLate:          "Too late",
+	TickMissed:       "Tick missed",
+	FollowCircleLeft: "Followcircle left",
+	SliderEndMissed:  "Slider end missed",
+}
+
+func (cause MissCause) String() string {
+	return missCauseNames[cause]
+}
+
+// Annotation describes a single miss or shake
+type Annotation struct {
+	Cause    MissCause
+	Time     int64
+	Number   int64 // object which was clicked or missed
+	Blocker  int64 // object that had to be hit first in case of a notelock, -1 otherwise
+	Shake    bool  // click was rejected without judging the object
+	Position vector.Vector2f
+}
+
+type annotationListener func(cursor *graphics.Cursor, annotation Annotation)
+
+// AddAnnotationListener registers a function receiving causes of every miss and shake
+func (set *OsuRuleSet) AddAnnotationListener(listener annotationListener) {
+	set.annotationListeners = append(set.annotationListeners, listener)
+}
+
+func (set *OsuRuleSet) annotate(player *difficultyPlayer, annotation Annotation) {
+	for _, listener := range set.annotationListeners {
+		listener(player.cursor, annotation)
+	}
+}
+
+func (set *OsuRuleSet) annotateShake(time int64, src HitObject, blocker HitObject, player *difficultyPlayer, position vector.Vector2f) {
+	if len(set.annotationListeners) == 0 {
+		return
+	}
+
+	annotation := Annotation{
+		Cause:    Notelock,
+		Time:     time,
+		Number:   src.GetNumber(),
+		Blocker:  -1,
+		Shake:    true,
+		Position: position,
+	}
+
+	if blocker != nil {
+		annotation.Blocker = blocker.GetNumber()
+	} else {
+		annotation.Cause = timingCause(time, set.beatMap.HitObjects[src.GetNumber()].GetStartTime())
+	}
+
+	set.annotate(player, annotation)
+}
+
+func (set *OsuRuleSet) annotateMiss(time int64, src HitObject, cause MissCause, player *difficultyPlayer, position vector.Vector2f) {
+	if len(set.annotationListeners) == 0 {
+		return
+	}
+
+	set.annotate(player, Annotation{
+		Cause:    cause,
+		Time:     time,
+		Number:   src.GetNumber(),
+		Blocker:  -1,
+		Position: position,
+	})
+}
+
+func timingCause(time int64, startTime float64) MissCause {
+	if float64(time) < startTime {
+		return TooEarly
+	}
+
+	return TooLate
+}
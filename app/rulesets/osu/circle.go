@@ -56,7 +56,7 @@ func (circle *Circle) UpdateClickFor(player *difficultyPlayer, time int64) bool
 		inRange := player.cursor.RawPosition.Dst(position) <= radius
 
 		if clicked {
-			action := circle.ruleSet.CanBeHit(time, circle, player)
+			action, blocker := circle.ruleSet.canBeHit(time, circle, player)
 
 			if inRange {
 				if action == Click {
@@ -81,13 +81,15 @@ func (circle *Circle) UpdateClickFor(player *difficultyPlayer, time int64) bool
 						combo := Increase
 						if hit == Miss {
 							combo = Reset
+
+							circle.ruleSet.annotateMiss(time, circle, timingCause(time, circle.hitCircle.GetStartTime()), player, position)
 						} else {
-							if len(circle.players) == 1 {
+							if circle.ruleSet.showFeedback(circle.players) {
 								circle.hitCircle.PlaySound()
 							}
 						}
 
-						if len(circle.players) == 1 {
+						if circle.ruleSet.showFeedback(circle.players) {
 							circle.hitCircle.Arm(hit != Miss, float64(time))
 						}
 
@@ -99,8 +101,12 @@ func (circle *Circle) UpdateClickFor(player *difficultyPlayer, time int64) bool
 					player.leftCondE = false
 					player.rightCondE = false
 
-					if action == Shake && len(circle.players) == 1 {
-						circle.hitCircle.Shake(float64(time))
+					if action == Shake {
+						if circle.ruleSet.showFeedback(circle.players) {
+							circle.hitCircle.Shake(float64(time))
+						}
+
+						circle.ruleSet.annotateShake(time, circle, blocker, player, position)
 					}
 				}
 			} else if action == Click {
@@ -117,9 +123,10 @@ func (circle *Circle) UpdatePostFor(player *difficultyPlayer, time int64, _ bool
 
 	if time > int64(circle.hitCircle.GetEndTime())+player.diff.Hit50 && !state.isHit {
 		position := circle.hitCircle.GetStackedPositionAtMod(float64(time), player.diff.Mods)
+		circle.ruleSet.annotateMiss(time, circle, TooLate, player, position)
 		circle.ruleSet.SendResult(time, player.cursor, circle, position.X, position.Y, Miss, Reset)
 
-		if len(circle.players) == 1 {
+		if circle.ruleSet.showFeedback(circle.players) {
 			circle.hitCircle.Arm(false, float64(time))
 		}
 
@@ -150,3 +157,23 @@ func (circle *Circle) IsHit(player *difficultyPlayer) bool {
 func (circle *Circle) GetFadeTime() int64 {
 	return int64(circle.hitCircle.GetStartTime() - circle.fadeStartRelative)
 }
+
+func (circle *Circle) saveState() interface{} {
+	states := make(map[*difficultyPlayer]objstate, len(circle.state))
+
+	for player, state := range circle.state {
+		states[player] = *state
+	}
+
+	return states
+}
+
+func (circle *Circle) restoreState(state interface{}) {
+	for player, s := range state.(map[*difficultyPlayer]objstate) {
+		*circle.state[player] = s
+	}
+}
+
+func (circle *Circle) resetState() {
+	circle.Init(circle.ruleSet, circle.hitCircle, circle.players)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/wieku/danser-go/app/beatmap/objects"
 	"github.com/wieku/danser-go/framework/math/mutils"
 	"math"
+	"sort"
 )
 
 const (
@@ -24,6 +25,9 @@ const (
 	HpSpinnerBonus = 2.0
 
 	MaxHp = 200.0
+
+	// HpLazerDefault is osu!lazer's default health increase for a perfect judgement, relative to max health
+	HpLazerDefault = 0.05
 )
 
 type FailListener func()
@@ -52,6 +56,9 @@ type HealthProcessor struct {
 
 	playing bool
 
+	// lazer uses osu!lazer's drain rate calculation and health increases
+	lazer bool
+
 	failListeners []FailListener
 }
 
@@ -71,7 +78,20 @@ func NewHealthProcessor(beatMap *beatmap.BeatMap, diff *difficulty.Difficulty, l
 	return proc
 }
 
+// NewLazerHealthProcessor creates HealthProcessor that follows osu!lazer's rules
+func NewLazerHealthProcessor(beatMap *beatmap.BeatMap, diff *difficulty.Difficulty) *HealthProcessor {
+	proc := NewHealthProcessor(beatMap, diff, false)
+	proc.lazer = true
+
+	return proc
+}
+
 func (hp *HealthProcessor) CalculateRate() { //nolint:gocyclo
+	if hp.lazer {
+		hp.calculateRateLazer()
+		return
+	}
+
 	lowestHpEver := difficulty.DifficultyRate(hp.diff.HPMod, 195, 160, 60)
 	lowestHpComboEnd := difficulty.DifficultyRate(hp.diff.HPMod, 198, 170, 80)
 	lowestHpEnd := difficulty.DifficultyRate(hp.diff.HPMod, 198, 180, 80)
@@ -178,6 +198,15 @@ func (hp *HealthProcessor) CalculateRate() { //nolint:gocyclo
 		}
 	}
 
+	hp.calculateDrains()
+
+	hp.ResetHp()
+	hp.playing = true
+}
+
+func (hp *HealthProcessor) calculateDrains() {
+	breakCount := len(hp.beatMap.Pauses)
+
 	breakNumber := 0
 	lastDrainStart := int64(hp.beatMap.HitObjects[0].GetStartTime()) - int64(hp.diff.Preempt)
 	lastDrainEnd := int64(hp.beatMap.HitObjects[0].GetStartTime()) - int64(hp.diff.Preempt)
@@ -202,11 +231,90 @@ func (hp *HealthProcessor) CalculateRate() { //nolint:gocyclo
 	}
 
 	hp.drains = append(hp.drains, drain{lastDrainStart, lastDrainEnd})
+}
+
+type healthIncrease struct {
+	time   int64
+	amount float64
+}
+
+// calculateRateLazer finds the drain rate with which perfect play reaches minimum health defined by HP, the same way as osu!lazer does
+func (hp *HealthProcessor) calculateRateLazer() {
+	hp.HpMultiplierNormal = 1.0
+	hp.HpMultiplierComboEnd = 1.0
+
+	hp.calculateDrains()
+
+	var increases []healthIncrease
+
+	for _, o := range hp.beatMap.HitObjects {
+		if s, ok := o.(*objects.Slider); ok {
+			increases = append(increases, healthIncrease{int64(s.GetStartTime()), lazerHealthIncrease(Hit300)})
+
+			// Legacy last tick is used only by stable, osu!lazer judges the tail at the end of the slider
+			legacyLastTick := math.Max(s.StartTime+(s.EndTimeLazer-s.StartTime)/2, s.EndTimeLazer-36)
+
+			for _, p := range s.ScorePointsLazer {
+				if p.IsReverse {
+					increases = append(increases, healthIncrease{int64(p.Time), lazerHealthIncrease(SliderRepeat)})
+				} else if p.Time != legacyLastTick {
+					increases = append(increases, healthIncrease{int64(p.Time), lazerHealthIncrease(SliderPoint)})
+				}
+			}
+
+			increases = append(increases, healthIncrease{int64(s.EndTimeLazer), lazerHealthIncrease(SliderEnd)})
+		} else {
+			increases = append(increases, healthIncrease{int64(o.GetEndTime()), lazerHealthIncrease(Hit300)})
+		}
+	}
+
+	sort.SliceStable(increases, func(i, j int) bool {
+		return increases[i].time < increases[j].time
+	})
+
+	targetMinimumHealth := difficulty.DifficultyRate(hp.diff.HPMod, 0.95, 0.70, 0.30)
+
+	adjustment := 1.0
+	rate := 1.0
+
+	for i := 0; i < 50; i++ {
+		currentHealth := 1.0
+		lowestHealth := 1.0
+
+		// osu!lazer starts draining at the first object
+		lastTime := int64(hp.beatMap.HitObjects[0].GetStartTime())
+
+		for _, inc := range increases {
+			currentHealth -= float64(hp.drainTimeBetween(lastTime, inc.time)) * rate
+			lowestHealth = math.Min(lowestHealth, currentHealth)
+			currentHealth = math.Min(1, currentHealth+inc.amount)
+
+			lastTime = inc.time
+		}
+
+		if math.Abs(lowestHealth-targetMinimumHealth) <= 0.01 {
+			break
+		}
+
+		adjustment *= 2
+		rate += 1.0 / adjustment * float64(mutils.Compare(lowestHealth, targetMinimumHealth))
+	}
+
+	hp.PassiveDrain = rate * MaxHp
 
 	hp.ResetHp()
 	hp.playing = true
 }
 
+// drainTimeBetween returns how much of the time between start and end is drain time
+func (hp *HealthProcessor) drainTimeBetween(start, end int64) (time int64) {
+	for _, d := range hp.drains {
+		time += mutils.Max(0, mutils.Min(end, d.end)-mutils.Max(start, d.start))
+	}
+
+	return
+}
+
 func (hp *HealthProcessor) ResetHp() {
 	hp.Health = MaxHp
 	hp.HealthUncapped = MaxHp
@@ -216,6 +324,11 @@ func (hp *HealthProcessor) AddResult(result HitResult) {
 	normal := result & (^Additions)
 	addition := result & Additions
 
+	if hp.lazer {
+		hp.Increase(lazerHealthIncrease(normal)*MaxHp, true)
+		return
+	}
+
 	hpAdd := 0.0
 
 	switch normal {
@@ -303,3 +416,21 @@ func (hp *HealthProcessor) Update(time int64) {
 func (hp *HealthProcessor) AddFailListener(listener FailListener) {
 	hp.failListeners = append(hp.failListeners, listener)
 }
+
+// lazerHealthIncrease returns osu!lazer's health increase for the given result, relative to max health
+func lazerHealthIncrease(result HitResult) float64 {
+	switch result {
+	case SliderMiss, Miss:
+		return -HpLazerDefault
+	case Hit50:
+		return -HpLazerDefault * 0.05
+	case Hit100:
+		return HpLazerDefault * 0.5
+	case Hit300, SliderStart, SliderPoint, SliderRepeat, SliderEnd, SpinnerBonus:
+		return HpLazerDefault
+	case SpinnerSpin, SpinnerPoints:
+		return HpLazerDefault * 0.5
+	}
+
+	return 0
+}
@@ -0,0 +1,258 @@
+package osu
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
+	"github.com/wieku/danser-go/framework/files"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var resultNames = []struct {
+	result HitResult
+	name   string
+}{
+	{PositionalMiss, "PositionalMiss"},
+	{SliderMiss, "SliderMiss"},
+	{Miss, "Miss"},
+	{Hit50, "Hit50"},
+	{Hit100, "Hit100"},
+	{Hit300, "Hit300"},
+	{SliderStart, "SliderStart"},
+	{SliderPoint, "SliderPoint"},
+	{SliderRepeat, "SliderRepeat"},
+	{SliderEnd, "SliderEnd"},
+	{SpinnerSpin, "SpinnerSpin"},
+	{SpinnerPoints, "SpinnerPoints"},
+	{SpinnerBonus, "SpinnerBonus"},
+}
+
+var comboResultNames = map[ComboResult]string{
+	Reset:    "Reset",
+	Hold:     "Hold",
+	Increase: "Increase",
+}
+
+var judgementLogHeader = []string{"time", "object", "x", "y", "result", "comboResult", "offset", "combo", "score", "pp", "hp", "keys"}
+
+type Judgement struct {
+	Time        int64    `json:"time"`
+	Object      int64    `json:"object"`
+	X           float64  `json:"x"`
+	Y           float64  `json:"y"`
+	Result      string   `json:"result"`
+	ComboResult string   `json:"comboResult"`
+	Offset      *float64 `json:"offset"`
+	Combo       int64    `json:"combo"`
+	Score       int64    `json:"score"`
+	PP          float64  `json:"pp"`
+	HP          float64  `json:"hp"`
+	Keys        string   `json:"keys"`
+}
+
+type playerJudgements struct {
+	Player     string       `json:"player"`
+	Mods       string       `json:"mods"`
+	Judgements []*Judgement `json:"judgements"`
+}
+
+// JudgementLog collects every judgement made by the ruleset so it can be exported for external analysis
+type JudgementLog struct {
+	ruleset *OsuRuleSet
+	cursors []*graphics.Cursor
+	players map[*graphics.Cursor]*playerJudgements
+}
+
+func NewJudgementLog(ruleset *OsuRuleSet, cursors []*graphics.Cursor) *JudgementLog {
+	judgementLog := &JudgementLog{
+		ruleset: ruleset,
+		cursors: cursors,
+		players: make(map[*graphics.Cursor]*playerJudgements),
+	}
+
+	for _, cursor := range cursors {
+		judgementLog.players[cursor] = &playerJudgements{
+			Player:     cursor.Name,
+			Mods:       ruleset.GetPlayer(cursor).diff.GetModString(),
+			Judgements: make([]*Judgement, 0),
+		}
+	}
+
+	ruleset.AddListener(judgementLog.hitReceived)
+	ruleset.AddRewindListener(judgementLog.rewind)
+
+	return judgementLog
+}
+
+func (judgementLog *JudgementLog) hitReceived(cursor *graphics.Cursor, time int64, number int64, position vector.Vector2d, result HitResult, comboResult ComboResult, ppResults api.PPv2Results, score int64) {
+	player, ok := judgementLog.players[cursor]
+	if !ok {
+		return
+	}
+
+	judgement := &Judgement{
+		Time:        time,
+		Object:      number,
+		X:           position.X,
+		Y:           position.Y,
+		Result:      resultName(result),
+		ComboResult: comboResultNames[comboResult],
+		Combo:       judgementLog.ruleset.cursors[cursor].scoreProcessor.GetCombo(),
+		Score:       score,
+		PP:          ppResults.Total,
+		HP:          judgementLog.ruleset.GetHP(cursor),
+		Keys:        pressedKeys(cursor),
+	}
+
+	// Offsets are meaningful only for judgements made by a click
+	object := judgementLog.ruleset.GetBeatMap().HitObjects[number]
+
+	_, isCircle := object.(*objects.Circle)
+
+	if (isCircle && result&BaseHits > 0) || result&(SliderStart|PositionalMiss) > 0 {
+		offset := float64(time) - object.GetStartTime()
+		judgement.Offset = &offset
+	}
+
+	player.Judgements = append(player.Judgements, judgement)
+}
+
+// rewind drops judgements which were undone by restoring the ruleset from a snapshot
+func (judgementLog *JudgementLog) rewind() {
+	for cursor, player := range judgementLog.players {
+		count := judgementLog.ruleset.GetNumJudgements(cursor)
+
+		if count < len(player.Judgements) {
+			player.Judgements = player.Judgements[:count]
+		}
+	}
+}
+
+// Save writes judgements of every player to separate files, format can be either "json" or "csv"
+func (judgementLog *JudgementLog) Save(basePath, format string) {
+	format = strings.ToLower(format)
+
+	for i, cursor := range judgementLog.cursors {
+		player := judgementLog.players[cursor]
+
+		path := fmt.Sprintf("%s_judgements_%d_%s.%s", basePath, i+1, files.FixName(cleanName(player.Player)), format)
+
+		var err error
+
+		switch format {
+		case "json":
+			err = player.saveJSON(path)
+		case "csv":
+			err = player.saveCSV(path)
+		default:
+			log.Println("JudgementLog: Unknown format:", format)
+			return
+		}
+
+		if err != nil {
+			log.Println("JudgementLog: Failed to save judgements:", err)
+			continue
+		}
+
+		log.Println("JudgementLog: Judgements saved to:", path)
+	}
+}
+
+func (player *playerJudgements) saveJSON(path string) error {
+	data, err := json.MarshalIndent(player, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+func (player *playerJudgements) saveCSV(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	_ = writer.Write(judgementLogHeader)
+
+	for _, j := range player.Judgements {
+		offset := ""
+		if j.Offset != nil {
+			offset = strconv.FormatFloat(*j.Offset, 'f', -1, 64)
+		}
+
+		_ = writer.Write([]string{
+			strconv.FormatInt(j.Time, 10),
+			strconv.FormatInt(j.Object, 10),
+			strconv.FormatFloat(j.X, 'f', 2, 64),
+			strconv.FormatFloat(j.Y, 'f', 2, 64),
+			j.Result,
+			j.ComboResult,
+			offset,
+			strconv.FormatInt(j.Combo, 10),
+			strconv.FormatInt(j.Score, 10),
+			strconv.FormatFloat(j.PP, 'f', 2, 64),
+			strconv.FormatFloat(j.HP, 'f', 4, 64),
+			j.Keys,
+		})
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func resultName(result HitResult) string {
+	for _, r := range resultNames {
+		if result&r.result > 0 {
+			return r.name
+		}
+	}
+
+	return "Ignore"
+}
+
+func pressedKeys(cursor *graphics.Cursor) string {
+	keys := make([]string, 0, 2)
+
+	if cursor.LeftButton {
+		if cursor.LeftKey {
+			keys = append(keys, "K1")
+		} else {
+			keys = append(keys, "M1")
+		}
+	}
+
+	if cursor.RightButton {
+		if cursor.RightKey {
+			keys = append(keys, "K2")
+		} else {
+			keys = append(keys, "M2")
+		}
+	}
+
+	return strings.Join(keys, "+")
+}
+
+// cleanName removes invisible characters used to keep knockout names unique
+func cleanName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsGraphic(r) {
+			return -1
+		}
+
+		return r
+	}, name)
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+)
+
+// IDifficultyCalculator calculates star rating of osu!standard beatmaps
+type IDifficultyCalculator interface {
+	// CalculateSingle calculates the final difficulty attributes of a map
+	CalculateSingle(objects []objects.IHitObject, diff *difficulty.Difficulty) Attributes
+
+	// CalculateStep calculates successive star ratings for every part of a beatmap
+	CalculateStep(objects []objects.IHitObject, diff *difficulty.Difficulty) []Attributes
+
+	// CalculateStrainPeaks calculates strain peaks of every section of a beatmap
+	CalculateStrainPeaks(objects []objects.IHitObject, diff *difficulty.Difficulty) StrainPeaks
+}
+
+// IPerformanceCalculator calculates performance points of osu!standard scores
+type IPerformanceCalculator interface {
+	Calculate(attribs Attributes, combo, n300, n100, n50, nmiss int, diff *difficulty.Difficulty) PPv2Results
+}
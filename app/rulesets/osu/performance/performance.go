@@ -0,0 +1,44 @@
+package performance
+
+import (
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/pp211112"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/pp241007"
+	"github.com/wieku/danser-go/app/settings"
+)
+
+const (
+	Version20211112 = 20211112
+	Version20241007 = 20241007
+
+	// CurrentVersion is the newest version of star rating and pp algorithms
+	CurrentVersion = Version20241007
+)
+
+// GetVersion returns the version of star rating and pp algorithms selected in settings, unknown versions fall back to the newest one
+func GetVersion() int {
+	switch settings.Gameplay.PPVersion {
+	case Version20211112:
+		return Version20211112
+	default:
+		return CurrentVersion
+	}
+}
+
+// GetDifficultyCalculator returns star rating calculator of selected version
+func GetDifficultyCalculator() api.IDifficultyCalculator {
+	if GetVersion() == Version20211112 {
+		return pp211112.NewDifficultyCalculator(settings.Gameplay.UseLazerPP)
+	}
+
+	return pp241007.NewDifficultyCalculator()
+}
+
+// GetPPCalculator returns pp calculator of selected version
+func GetPPCalculator() api.IPerformanceCalculator {
+	if GetVersion() == Version20211112 {
+		return pp211112.NewPPCalculator(settings.Gameplay.UseLazerPP)
+	}
+
+	return pp241007.NewPPCalculator()
+}
@@ -1,7 +1,8 @@
-package performance
+package pp211112
 
 import (
 	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
 	"github.com/wieku/danser-go/framework/math/mutils"
 	"math"
 )
@@ -15,15 +16,26 @@ func ppBase(stars float64) float64 {
 		100000.0
 }
 
-type PPv2Results struct {
-	Aim, Speed, Acc, Flashlight, Total float64
+// PPCalculator implements performance points released on 2021-11-12, experimental variant includes changes available in osu!lazer on 2022-01-23
+type PPCalculator struct {
+	experimental bool
+}
+
+func NewPPCalculator(experimental bool) *PPCalculator {
+	return &PPCalculator{experimental: experimental}
+}
+
+func (calculator *PPCalculator) Calculate(attribs api.Attributes, combo, n300, n100, n50, nmiss int, diff *difficulty.Difficulty) api.PPv2Results {
+	pp := &ppv2{experimental: calculator.experimental}
+
+	return pp.calculate(attribs, combo, n300, n100, n50, nmiss, diff)
 }
 
-// PPv2 : structure to store ppv2 values
-type PPv2 struct {
-	Results PPv2Results
+// ppv2 : structure to store ppv2 values
+type ppv2 struct {
+	Results api.PPv2Results
 
-	attribs Attributes
+	attribs api.Attributes
 
 	experimental bool
 
@@ -41,7 +53,7 @@ type PPv2 struct {
 	amountHitObjectsWithAccuracy int
 }
 
-func (pp *PPv2) PPv2x(attribs Attributes, combo, n300, n100, n50, nmiss int, diff *difficulty.Difficulty, experimental bool) PPv2 {
+func (pp *ppv2) calculate(attribs api.Attributes, combo, n300, n100, n50, nmiss int, diff *difficulty.Difficulty) api.PPv2Results {
 	attribs.MaxCombo = mutils.Max(1, attribs.MaxCombo)
 
 	if combo < 0 {
@@ -55,7 +67,6 @@ func (pp *PPv2) PPv2x(attribs Attributes, combo, n300, n100, n50, nmiss int, dif
 	totalhits := n300 + n100 + n50 + nmiss
 
 	pp.attribs = attribs
-	pp.experimental = experimental
 	pp.diff = diff
 	pp.totalHits = totalhits
 	pp.scoreMaxCombo = combo
@@ -113,10 +124,10 @@ func (pp *PPv2) PPv2x(attribs Attributes, combo, n300, n100, n50, nmiss int, dif
 			math.Pow(pp.Results.Flashlight, 1.1),
 		1.0/1.1) * finalMultiplier
 
-	return *pp
+	return pp.Results
 }
 
-func (pp *PPv2) computeAimValue() float64 {
+func (pp *ppv2) computeAimValue() float64 {
 	rawAim := pp.attribs.Aim
 
 	if pp.diff.Mods.Active(difficulty.TouchDevice) {
@@ -177,7 +188,7 @@ func (pp *PPv2) computeAimValue() float64 {
 	return aimValue
 }
 
-func (pp *PPv2) computeSpeedValue() float64 {
+func (pp *ppv2) computeSpeedValue() float64 {
 	speedValue := ppBase(pp.attribs.Speed)
 
 	// Longer maps are worth more
@@ -227,7 +238,7 @@ func (pp *PPv2) computeSpeedValue() float64 {
 	return speedValue
 }
 
-func (pp *PPv2) computeAccuracyValue() float64 {
+func (pp *ppv2) computeAccuracyValue() float64 {
 	if pp.diff.Mods.Active(difficulty.Relax) {
 		return 0.0
 	}
@@ -262,7 +273,7 @@ func (pp *PPv2) computeAccuracyValue() float64 {
 	return accuracyValue
 }
 
-func (pp *PPv2) computeFlashlightValue() float64 {
+func (pp *ppv2) computeFlashlightValue() float64 {
 	if !pp.diff.CheckModActive(difficulty.Flashlight) {
 		return 0
 	}
@@ -306,7 +317,7 @@ func (pp *PPv2) computeFlashlightValue() float64 {
 	return flashlightValue
 }
 
-func (pp *PPv2) calculateEffectiveMissCount() float64 {
+func (pp *ppv2) calculateEffectiveMissCount() float64 {
 	// guess the number of misses + slider breaks from combo
 	comboBasedMissCount := 0.0
 
@@ -327,11 +338,11 @@ func (pp *PPv2) calculateEffectiveMissCount() float64 {
 	}
 }
 
-func (pp *PPv2) calculateMissPenalty(missCount, difficultStrainCount float64) float64 {
+func (pp *ppv2) calculateMissPenalty(missCount, difficultStrainCount float64) float64 {
 	return 0.95 / ((missCount / (3 * math.Sqrt(difficultStrainCount))) + 1)
 }
 
-func (pp *PPv2) getComboScalingFactor() float64 {
+func (pp *ppv2) getComboScalingFactor() float64 {
 	if pp.attribs.MaxCombo <= 0 {
 		return 1.0
 	} else {
@@ -0,0 +1,219 @@
+package pp241007
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/pp241007/preprocessing"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/pp241007/skills"
+	"log"
+	"math"
+)
+
+// StarScalingFactor is a global stars multiplier
+const StarScalingFactor float64 = 0.0675
+
+// DifficultyCalculator implements star rating released on 2024-10-07
+type DifficultyCalculator struct{}
+
+func NewDifficultyCalculator() *DifficultyCalculator {
+	return &DifficultyCalculator{}
+}
+
+// getStarsFromRawValues converts raw skill values to Attributes
+func getStarsFromRawValues(rawAim, rawAimNoSliders, rawSpeed, rawFlashlight float64, diff *difficulty.Difficulty, attr api.Attributes) api.Attributes {
+	aimRating := math.Sqrt(rawAim) * StarScalingFactor
+	aimRatingNoSliders := math.Sqrt(rawAimNoSliders) * StarScalingFactor
+	speedRating := math.Sqrt(rawSpeed) * StarScalingFactor
+
+	flashlightRating := 0.0
+	if diff.CheckModActive(difficulty.Flashlight) {
+		flashlightRating = math.Sqrt(rawFlashlight) * StarScalingFactor
+	}
+
+	sliderFactor := 1.0
+	if aimRating > 0 {
+		sliderFactor = aimRatingNoSliders / aimRating
+	}
+
+	if diff.CheckModActive(difficulty.TouchDevice) {
+		aimRating = math.Pow(aimRating, 0.8)
+		flashlightRating = math.Pow(flashlightRating, 0.8)
+	}
+
+	if diff.CheckModActive(difficulty.Relax) {
+		aimRating *= 0.9
+		speedRating = 0.0
+		flashlightRating *= 0.7
+	} else if diff.CheckModActive(difficulty.Relax2) {
+		speedRating *= 0.5
+		aimRating = 0.0
+		flashlightRating *= 0.4
+	}
+
+	baseAimPerformance := ppBase(aimRating)
+	baseSpeedPerformance := ppBase(speedRating)
+	baseFlashlightPerformance := 0.0
+
+	if diff.CheckModActive(difficulty.Flashlight) {
+		baseFlashlightPerformance = math.Pow(flashlightRating, 2.0) * 25.0
+	}
+
+	basePerformance := math.Pow(
+		math.Pow(baseAimPerformance, 1.1)+
+			math.Pow(baseSpeedPerformance, 1.1)+
+			math.Pow(baseFlashlightPerformance, 1.1),
+		1.0/1.1,
+	)
+
+	total := 0.0
+
+	if basePerformance > 0.00001 {
+		total = math.Cbrt(PerformanceBaseMultiplier) * 0.027 * (math.Cbrt(100000/math.Pow(2, 1/1.1)*basePerformance) + 4)
+	}
+
+	attr.Total = total
+	attr.Aim = aimRating
+	attr.SliderFactor = sliderFactor
+	attr.Speed = speedRating
+	attr.Flashlight = flashlightRating
+
+	return attr
+}
+
+// Retrieves skill values and converts to Attributes
+func getStars(aim *skills.AimSkill, aimNoSliders *skills.AimSkill, speed *skills.SpeedSkill, flashlight *skills.Flashlight, diff *difficulty.Difficulty, attr api.Attributes) api.Attributes {
+	attr = getStarsFromRawValues(
+		aim.DifficultyValue(),
+		aimNoSliders.DifficultyValue(),
+		speed.DifficultyValue(),
+		flashlight.DifficultyValue(),
+		diff,
+		attr,
+	)
+
+	attr.AimDifficultStrainCount = aim.CountDifficultStrains()
+	attr.SpeedDifficultStrainCount = speed.CountDifficultStrains()
+	attr.SpeedNoteCount = speed.RelevantNoteCount()
+
+	return attr
+}
+
+func addObjectToAttribs(o objects.IHitObject, attr *api.Attributes) {
+	if s, ok := o.(*objects.Slider); ok {
+		attr.Sliders++
+		attr.MaxCombo += len(s.ScorePoints)
+	} else if _, ok := o.(*objects.Circle); ok {
+		attr.Circles++
+	} else if _, ok := o.(*objects.Spinner); ok {
+		attr.Spinners++
+	}
+
+	attr.MaxCombo++
+	attr.ObjectCount++
+}
+
+// CalculateSingle calculates the final difficulty attributes of a map
+func (calculator *DifficultyCalculator) CalculateSingle(objects []objects.IHitObject, diff *difficulty.Difficulty) api.Attributes {
+	diffObjects := preprocessing.CreateDifficultyObjects(objects, diff)
+
+	aimSkill := skills.NewAimSkill(diff, true)
+	aimNoSlidersSkill := skills.NewAimSkill(diff, false)
+	speedSkill := skills.NewSpeedSkill(diff)
+	flashlightSkill := skills.NewFlashlightSkill(diff)
+
+	attr := api.Attributes{}
+
+	addObjectToAttribs(objects[0], &attr)
+
+	for i, o := range diffObjects {
+		addObjectToAttribs(objects[i+1], &attr)
+
+		aimSkill.Process(o)
+		aimNoSlidersSkill.Process(o)
+		speedSkill.Process(o)
+		flashlightSkill.Process(o)
+	}
+
+	return getStars(aimSkill, aimNoSlidersSkill, speedSkill, flashlightSkill, diff, attr)
+}
+
+// CalculateStep calculates successive star ratings for every part of a beatmap
+func (calculator *DifficultyCalculator) CalculateStep(objects []objects.IHitObject, diff *difficulty.Difficulty) []api.Attributes {
+	modString := (diff.Mods & difficulty.DifficultyAdjustMask).String()
+	if modString == "" {
+		modString = "NM"
+	}
+
+	log.Println("Calculating step SR for mods:", modString)
+
+	diffObjects := preprocessing.CreateDifficultyObjects(objects, diff)
+
+	aimSkill := skills.NewAimSkill(diff, true)
+	aimNoSlidersSkill := skills.NewAimSkill(diff, false)
+	speedSkill := skills.NewSpeedSkill(diff)
+	flashlightSkill := skills.NewFlashlightSkill(diff)
+
+	stars := make([]api.Attributes, 1, len(objects))
+
+	addObjectToAttribs(objects[0], &stars[0])
+
+	lastProgress := -1
+
+	for i, o := range diffObjects {
+		attr := stars[i]
+		addObjectToAttribs(objects[i+1], &attr)
+
+		aimSkill.Process(o)
+		aimNoSlidersSkill.Process(o)
+		speedSkill.Process(o)
+		flashlightSkill.Process(o)
+
+		stars = append(stars, getStars(aimSkill, aimNoSlidersSkill, speedSkill, flashlightSkill, diff, attr))
+
+		if len(diffObjects) > 2500 {
+			progress := (100 * i) / (len(diffObjects) - 1)
+
+			if progress != lastProgress && progress%5 == 0 {
+				log.Println(fmt.Sprintf("Progress: %d%%", progress))
+			}
+
+			lastProgress = progress
+		}
+	}
+
+	log.Println("Calculations finished!")
+
+	return stars
+}
+
+// CalculateStrainPeaks calculates strain peaks of every section of a beatmap
+func (calculator *DifficultyCalculator) CalculateStrainPeaks(objects []objects.IHitObject, diff *difficulty.Difficulty) api.StrainPeaks {
+	diffObjects := preprocessing.CreateDifficultyObjects(objects, diff)
+
+	aimSkill := skills.NewAimSkill(diff, true)
+	speedSkill := skills.NewSpeedSkill(diff)
+	flashlightSkill := skills.NewFlashlightSkill(diff)
+
+	for _, o := range diffObjects {
+		aimSkill.Process(o)
+		speedSkill.Process(o)
+		flashlightSkill.Process(o)
+	}
+
+	peaks := api.StrainPeaks{
+		Aim:        aimSkill.GetCurrentStrainPeaks(),
+		Speed:      speedSkill.GetCurrentStrainPeaks(),
+		Flashlight: flashlightSkill.GetCurrentStrainPeaks(),
+	}
+
+	peaks.Total = make([]float64, len(peaks.Aim))
+
+	for i := 0; i < len(peaks.Aim); i++ {
+		stars := getStarsFromRawValues(peaks.Aim[i], peaks.Aim[i], peaks.Speed[i], peaks.Flashlight[i], diff, api.Attributes{})
+		peaks.Total[i] = stars.Total
+	}
+
+	return peaks
+}
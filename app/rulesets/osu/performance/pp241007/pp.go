@@ -0,0 +1,349 @@
+package pp241007
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+// PerformanceBaseMultiplier is a final pp multiplier, it also scales star rating
+const PerformanceBaseMultiplier float64 = 1.15
+
+/* ------------------------------------------------------------- */
+/* pp calc                                                       */
+
+/* base pp value for stars, used internally by ppv2 */
+func ppBase(stars float64) float64 {
+	return math.Pow(5.0*math.Max(1.0, stars/StarScalingFactor)-4.0, 3.0) /
+		100000.0
+}
+
+// PPCalculator implements performance points released on 2024-10-07
+type PPCalculator struct{}
+
+func NewPPCalculator() *PPCalculator {
+	return &PPCalculator{}
+}
+
+func (calculator *PPCalculator) Calculate(attribs api.Attributes, combo, n300, n100, n50, nmiss int, diff *difficulty.Difficulty) api.PPv2Results {
+	pp := &ppv2{}
+
+	return pp.calculate(attribs, combo, n300, n100, n50, nmiss, diff)
+}
+
+// ppv2 : structure to store ppv2 values
+type ppv2 struct {
+	Results api.PPv2Results
+
+	attribs api.Attributes
+
+	scoreMaxCombo      int
+	countGreat         int
+	countOk            int
+	countMeh           int
+	countMiss          int
+	effectiveMissCount float64
+
+	diff *difficulty.Difficulty
+
+	totalHits                    int
+	accuracy                     float64
+	amountHitObjectsWithAccuracy int
+}
+
+func (pp *ppv2) calculate(attribs api.Attributes, combo, n300, n100, n50, nmiss int, diff *difficulty.Difficulty) api.PPv2Results {
+	attribs.MaxCombo = mutils.Max(1, attribs.MaxCombo)
+
+	if combo < 0 {
+		combo = attribs.MaxCombo
+	}
+
+	if n300 < 0 {
+		n300 = attribs.ObjectCount - n100 - n50 - nmiss
+	}
+
+	totalhits := n300 + n100 + n50 + nmiss
+
+	pp.attribs = attribs
+	pp.diff = diff
+	pp.totalHits = totalhits
+	pp.scoreMaxCombo = combo
+	pp.countGreat = n300
+	pp.countOk = n100
+	pp.countMeh = n50
+	pp.countMiss = nmiss
+	pp.effectiveMissCount = pp.calculateEffectiveMissCount()
+
+	// accuracy
+
+	if totalhits == 0 {
+		pp.accuracy = 0.0
+	} else {
+		acc := (float64(n50)*50 +
+			float64(n100)*100 +
+			float64(n300)*300) /
+			(float64(totalhits) * 300)
+
+		pp.accuracy = mutils.ClampF(acc, 0, 1)
+	}
+
+	pp.amountHitObjectsWithAccuracy = attribs.Circles
+
+	if diff.CheckModActive(difficulty.ScoreV2) {
+		pp.amountHitObjectsWithAccuracy += attribs.Sliders
+	}
+
+	// total pp
+
+	finalMultiplier := PerformanceBaseMultiplier
+
+	if diff.Mods.Active(difficulty.NoFail) {
+		finalMultiplier *= math.Max(0.90, 1.0-0.02*pp.effectiveMissCount)
+	}
+
+	if totalhits > 0 && diff.Mods.Active(difficulty.SpunOut) {
+		finalMultiplier *= 1.0 - math.Pow(float64(attribs.Spinners)/float64(totalhits), 0.85)
+	}
+
+	if diff.Mods.Active(difficulty.Relax) {
+		// https://www.desmos.com/calculator/bc9eybdthb
+		// we use OD13.3 as maximum since it's the value at which great hitwidow becomes 0
+		// this is well beyond currently maximum achievable OD which is 12.17 (DTx2 + DA with OD11)
+		okMultiplier := 1.0
+		mehMultiplier := 1.0
+
+		if pp.diff.ODReal > 0 {
+			okMultiplier = math.Max(0.0, 1-math.Pow(pp.diff.ODReal/13.33, 1.8))
+			mehMultiplier = math.Max(0.0, 1-math.Pow(pp.diff.ODReal/13.33, 5))
+		}
+
+		// As we're adding Oks and Mehs to an approximated number of combo breaks the result can be higher than total hits in specific scenarios (which breaks some calculations) so we need to clamp it.
+		pp.effectiveMissCount = math.Min(pp.effectiveMissCount+float64(pp.countOk)*okMultiplier+float64(pp.countMeh)*mehMultiplier, float64(pp.totalHits))
+	}
+
+	pp.Results.Aim = pp.computeAimValue()
+	pp.Results.Speed = pp.computeSpeedValue()
+	pp.Results.Acc = pp.computeAccuracyValue()
+	pp.Results.Flashlight = pp.computeFlashlightValue()
+
+	pp.Results.Total = math.Pow(
+		math.Pow(pp.Results.Aim, 1.1)+
+			math.Pow(pp.Results.Speed, 1.1)+
+			math.Pow(pp.Results.Acc, 1.1)+
+			math.Pow(pp.Results.Flashlight, 1.1),
+		1.0/1.1) * finalMultiplier
+
+	return pp.Results
+}
+
+func (pp *ppv2) computeAimValue() float64 {
+	if pp.diff.Mods.Active(difficulty.Relax2) {
+		return 0
+	}
+
+	aimValue := ppBase(pp.attribs.Aim)
+
+	// Longer maps are worth more
+	lengthBonus := 0.95 + 0.4*math.Min(1.0, float64(pp.totalHits)/2000.0)
+	if pp.totalHits > 2000 {
+		lengthBonus += math.Log10(float64(pp.totalHits)/2000.0) * 0.5
+	}
+
+	aimValue *= lengthBonus
+
+	if pp.effectiveMissCount > 0 {
+		aimValue *= pp.calculateMissPenalty(pp.effectiveMissCount, pp.attribs.AimDifficultStrainCount)
+	}
+
+	approachRateFactor := 0.0
+	if pp.diff.ARReal > 10.33 {
+		approachRateFactor = 0.3 * (pp.diff.ARReal - 10.33)
+	} else if pp.diff.ARReal < 8.0 {
+		approachRateFactor = 0.05 * (8.0 - pp.diff.ARReal)
+	}
+
+	if pp.diff.Mods.Active(difficulty.Relax) {
+		approachRateFactor = 0.0
+	}
+
+	aimValue *= 1.0 + approachRateFactor*lengthBonus
+
+	// We want to give more reward for lower AR when it comes to aim and HD. This nerfs high AR and buffs lower AR.
+	if pp.diff.Mods.Active(difficulty.Hidden) {
+		aimValue *= 1.0 + 0.04*(12.0-pp.diff.ARReal)
+	}
+
+	// We assume 15% of sliders in a map are difficult since there's no way to tell from the performance calculator.
+	estimateDifficultSliders := float64(pp.attribs.Sliders) * 0.15
+
+	if pp.attribs.Sliders > 0 {
+		estimateSliderEndsDropped := mutils.ClampF(float64(mutils.Min(pp.countOk+pp.countMeh+pp.countMiss, pp.attribs.MaxCombo-pp.scoreMaxCombo)), 0, estimateDifficultSliders)
+		sliderNerfFactor := (1-pp.attribs.SliderFactor)*math.Pow(1-estimateSliderEndsDropped/estimateDifficultSliders, 3) + pp.attribs.SliderFactor
+		aimValue *= sliderNerfFactor
+	}
+
+	aimValue *= pp.accuracy
+	// It is important to also consider accuracy difficulty when doing that
+	aimValue *= 0.98 + math.Pow(pp.diff.ODReal, 2)/2500
+
+	return aimValue
+}
+
+func (pp *ppv2) computeSpeedValue() float64 {
+	if pp.diff.Mods.Active(difficulty.Relax) {
+		return 0
+	}
+
+	speedValue := ppBase(pp.attribs.Speed)
+
+	// Longer maps are worth more
+	lengthBonus := 0.95 + 0.4*math.Min(1.0, float64(pp.totalHits)/2000.0)
+	if pp.totalHits > 2000 {
+		lengthBonus += math.Log10(float64(pp.totalHits)/2000.0) * 0.5
+	}
+
+	speedValue *= lengthBonus
+
+	if pp.effectiveMissCount > 0 {
+		speedValue *= pp.calculateMissPenalty(pp.effectiveMissCount, pp.attribs.SpeedDifficultStrainCount)
+	}
+
+	approachRateFactor := 0.0
+	if pp.diff.ARReal > 10.33 {
+		approachRateFactor = 0.3 * (pp.diff.ARReal - 10.33)
+	}
+
+	if pp.diff.Mods.Active(difficulty.Relax2) {
+		approachRateFactor = 0.0
+	}
+
+	speedValue *= 1.0 + approachRateFactor*lengthBonus
+
+	if pp.diff.Mods.Active(difficulty.Hidden) {
+		speedValue *= 1.0 + 0.04*(12.0-pp.diff.ARReal)
+	}
+
+	// Calculate accuracy assuming the worst case scenario
+	relevantTotalDiff := float64(pp.totalHits) - pp.attribs.SpeedNoteCount
+	relevantCountGreat := math.Max(0, float64(pp.countGreat)-relevantTotalDiff)
+	relevantCountOk := math.Max(0, float64(pp.countOk)-math.Max(0, relevantTotalDiff-float64(pp.countGreat)))
+	relevantCountMeh := math.Max(0, float64(pp.countMeh)-math.Max(0, relevantTotalDiff-float64(pp.countGreat)-float64(pp.countOk)))
+
+	relevantAccuracy := 0.0
+	if pp.attribs.SpeedNoteCount > 0 {
+		relevantAccuracy = (relevantCountGreat*6.0 + relevantCountOk*2.0 + relevantCountMeh) / (pp.attribs.SpeedNoteCount * 6.0)
+	}
+
+	// Scale the speed value with accuracy and OD.
+	speedValue *= (0.95 + math.Pow(pp.diff.ODReal, 2)/750) * math.Pow((pp.accuracy+relevantAccuracy)/2.0, (14.5-pp.diff.ODReal)/2)
+
+	// Scale the speed value with # of 50s to punish doubletapping.
+	mehMult := 0.0
+	if float64(pp.countMeh) >= float64(pp.totalHits)/500 {
+		mehMult = float64(pp.countMeh) - float64(pp.totalHits)/500.0
+	}
+
+	speedValue *= math.Pow(0.99, mehMult)
+
+	return speedValue
+}
+
+func (pp *ppv2) computeAccuracyValue() float64 {
+	if pp.diff.Mods.Active(difficulty.Relax) {
+		return 0.0
+	}
+
+	// This percentage only considers HitCircles of any value - in this part of the calculation we focus on hitting the timing hit window
+	betterAccuracyPercentage := 0.0
+
+	if pp.amountHitObjectsWithAccuracy > 0 {
+		betterAccuracyPercentage = float64((pp.countGreat-(pp.totalHits-pp.amountHitObjectsWithAccuracy))*6+pp.countOk*2+pp.countMeh) / (float64(pp.amountHitObjectsWithAccuracy) * 6)
+	}
+
+	// It is possible to reach a negative accuracy with this formula. Cap it at zero - zero points
+	if betterAccuracyPercentage < 0 {
+		betterAccuracyPercentage = 0
+	}
+
+	// Lots of arbitrary values from testing.
+	// Considering to use derivation from perfect accuracy in a probabilistic manner - assume normal distribution
+	accuracyValue := math.Pow(1.52163, pp.diff.ODReal) * math.Pow(betterAccuracyPercentage, 24) * 2.83
+
+	// Bonus for many hitcircles - it's harder to keep good accuracy up for longer
+	accuracyValue *= math.Min(1.15, math.Pow(float64(pp.amountHitObjectsWithAccuracy)/1000.0, 0.3))
+
+	if pp.diff.Mods.Active(difficulty.Hidden) {
+		accuracyValue *= 1.08
+	}
+
+	if pp.diff.Mods.Active(difficulty.Flashlight) {
+		accuracyValue *= 1.02
+	}
+
+	return accuracyValue
+}
+
+func (pp *ppv2) computeFlashlightValue() float64 {
+	if !pp.diff.CheckModActive(difficulty.Flashlight) {
+		return 0
+	}
+
+	flashlightValue := math.Pow(pp.attribs.Flashlight, 2.0) * 25.0
+
+	// Penalize misses by assessing # of misses relative to the total # of objects. Default a 3% reduction for any # of misses.
+	if pp.effectiveMissCount > 0 {
+		flashlightValue *= 0.97 * math.Pow(1-math.Pow(pp.effectiveMissCount/float64(pp.totalHits), 0.775), math.Pow(pp.effectiveMissCount, 0.875))
+	}
+
+	flashlightValue *= pp.getComboScalingFactor()
+
+	// Account for shorter maps having a higher ratio of 0 combo/100 combo flashlight radius.
+	scale := 0.7 + 0.1*math.Min(1.0, float64(pp.totalHits)/200.0)
+	if pp.totalHits > 200 {
+		scale += 0.2 * math.Min(1.0, float64(pp.totalHits-200)/200.0)
+	}
+
+	flashlightValue *= scale
+
+	// Scale the flashlight value with accuracy _slightly_.
+	flashlightValue *= 0.5 + pp.accuracy/2.0
+	// It is important to also consider accuracy difficulty when doing that.
+	flashlightValue *= 0.98 + math.Pow(pp.diff.ODReal, 2)/2500
+
+	return flashlightValue
+}
+
+func (pp *ppv2) calculateEffectiveMissCount() float64 {
+	// guess the number of misses + slider breaks from combo
+	comboBasedMissCount := 0.0
+
+	if pp.attribs.Sliders > 0 {
+		fullComboThreshold := float64(pp.attribs.MaxCombo) - 0.1*float64(pp.attribs.Sliders)
+		if float64(pp.scoreMaxCombo) < fullComboThreshold {
+			comboBasedMissCount = fullComboThreshold / math.Max(1.0, float64(pp.scoreMaxCombo))
+		}
+	}
+
+	// Clamp miss count to maximum amount of possible breaks
+	comboBasedMissCount = math.Min(comboBasedMissCount, float64(pp.countOk+pp.countMeh+pp.countMiss))
+
+	return math.Max(float64(pp.countMiss), comboBasedMissCount)
+}
+
+// calculateMissPenalty returns a miss penalty that scales with the number of difficult strains, so misses on consistently hard maps are penalized less
+func (pp *ppv2) calculateMissPenalty(missCount, difficultStrainCount float64) float64 {
+	if difficultStrainCount <= 1 {
+		return 0
+	}
+
+	return 0.96 / ((missCount / (4 * math.Pow(math.Log(difficultStrainCount), 0.94))) + 1)
+}
+
+func (pp *ppv2) getComboScalingFactor() float64 {
+	if pp.attribs.MaxCombo <= 0 {
+		return 1.0
+	} else {
+		return math.Min(math.Pow(float64(pp.scoreMaxCombo), 0.8)/math.Pow(float64(pp.attribs.MaxCombo), 0.8), 1.0)
+	}
+}
@@ -0,0 +1,137 @@
+package skills
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/pp241007/preprocessing"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+const (
+	flashlightSkillMultiplier float64 = 0.05512
+
+	maxOpacityBonus    float64 = 0.4
+	hiddenBonus        float64 = 0.2
+	minVelocity        float64 = 0.5
+	flSliderMultiplier float64 = 1.3
+	minAngleMultiplier float64 = 0.2
+)
+
+type Flashlight struct {
+	*Skill
+
+	hidden bool
+}
+
+func NewFlashlightSkill(d *difficulty.Difficulty) *Flashlight {
+	skill := &Flashlight{
+		Skill:  NewSkill(d),
+		hidden: d.CheckModActive(difficulty.Hidden),
+	}
+
+	skill.StrainDecayBase = 0.15
+	skill.StrainValueAt = skill.flashlightStrainValue
+
+	return skill
+}
+
+// DifficultyValue sums all strain peaks, unlike other skills flashlight doesn't decay weights of lower sections
+func (s *Flashlight) DifficultyValue() float64 {
+	sum := 0.0
+
+	for _, peak := range s.GetCurrentStrainPeaks() {
+		sum += peak
+	}
+
+	return sum
+}
+
+func (s *Flashlight) flashlightStrainValue(current *preprocessing.DifficultyObject) float64 {
+	s.CurrentStrain *= s.strainDecay(current.DeltaTime)
+	s.CurrentStrain += s.evaluateFlashlight(current) * flashlightSkillMultiplier
+
+	return s.CurrentStrain
+}
+
+func (s *Flashlight) evaluateFlashlight(current *preprocessing.DifficultyObject) float64 {
+	if _, ok := current.BaseObject.(*objects.Spinner); ok {
+		return 0
+	}
+
+	scalingFactor := 52.0 / s.diff.CircleRadiusU
+	smallDistNerf := 1.0
+	cumulativeStrainTime := 0.0
+
+	result := 0.0
+
+	lastObj := current
+
+	angleRepeatCount := 0.0
+
+	// This is iterating backwards in time from the current object.
+	for i := 0; i < mutils.Min(current.Index, 10); i++ {
+		currentObj := current.Previous(i)
+
+		cumulativeStrainTime += lastObj.StrainTime
+
+		if _, ok := currentObj.BaseObject.(*objects.Spinner); !ok {
+			jumpDistance := float64(current.BaseObject.GetStackedStartPositionMod(s.diff.Mods).Dst(currentObj.BaseObject.GetStackedEndPositionMod(s.diff.Mods)))
+
+			// We want to nerf objects that can be easily seen within the Flashlight circle radius.
+			if i == 0 {
+				smallDistNerf = math.Min(1.0, jumpDistance/75.0)
+			}
+
+			// We also want to nerf stacks so that only the first object of the stack is accounted for.
+			stackNerf := math.Min(1.0, (currentObj.JumpDistance/scalingFactor)/25.0)
+
+			// Bonus based on how visible the object is.
+			opacityBonus := 1.0 + maxOpacityBonus*(1.0-current.OpacityAt(currentObj.BaseObject.GetStartTime(), s.hidden))
+
+			result += stackNerf * opacityBonus * scalingFactor * jumpDistance / cumulativeStrainTime
+
+			if !math.IsNaN(currentObj.Angle) && !math.IsNaN(current.Angle) {
+				// Objects further back in time should count less for the nerf.
+				if math.Abs(currentObj.Angle-current.Angle) < 0.02 {
+					angleRepeatCount += math.Max(1.0-0.1*float64(i), 0.0)
+				}
+			}
+		}
+
+		lastObj = currentObj
+	}
+
+	result = math.Pow(smallDistNerf*result, 2.0)
+
+	// Additional bonus for Hidden due to there being no approach circles.
+	if s.hidden {
+		result *= 1.0 + hiddenBonus
+	}
+
+	// Nerf patterns with repeated angles.
+	result *= minAngleMultiplier + (1.0-minAngleMultiplier)/(angleRepeatCount+1.0)
+
+	sliderBonus := 0.0
+
+	if slider, ok := current.BaseObject.(*preprocessing.LazySlider); ok {
+		// Invert the scaling factor to determine the true travel distance independent of circle size.
+		pixelTravelDistance := float64(slider.LazyTravelDistance) / scalingFactor
+		travelTime := math.Max(slider.LazyTravelTime/s.diff.Speed, preprocessing.MinDeltaTime)
+
+		// Reward sliders based on velocity.
+		sliderBonus = math.Pow(math.Max(0.0, pixelTravelDistance/travelTime-minVelocity), 0.5)
+
+		// Longer sliders require more memorisation.
+		sliderBonus *= pixelTravelDistance
+
+		// Nerf sliders with repeats, as less memorisation is required.
+		if slider.RepeatCount > 1 {
+			sliderBonus /= float64(slider.RepeatCount)
+		}
+	}
+
+	result += sliderBonus * flSliderMultiplier
+
+	return result
+}
@@ -0,0 +1,230 @@
+package skills
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/pp241007/preprocessing"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+const (
+	historyTimeMax          float64 = 5000 // 5 seconds
+	historyObjectsMax       int     = 32
+	rhythmOverallMultiplier float64 = 0.95
+	rhythmRatioMultiplier   float64 = 12.0
+)
+
+// island is a group of consecutive objects with similar delta times
+type island struct {
+	deltaDifferenceEpsilon float64
+
+	delta      int
+	deltaCount int
+}
+
+func newIsland(delta int, epsilon float64) *island {
+	return &island{
+		deltaDifferenceEpsilon: epsilon,
+		delta:                  mutils.Max(delta, preprocessing.MinDeltaTime),
+		deltaCount:             1,
+	}
+}
+
+func newEmptyIsland(epsilon float64) *island {
+	return &island{
+		deltaDifferenceEpsilon: epsilon,
+		delta:                  math.MaxInt,
+	}
+}
+
+func (isl *island) addDelta(delta int) {
+	if isl.delta == math.MaxInt {
+		isl.delta = mutils.Max(delta, preprocessing.MinDeltaTime)
+	}
+
+	isl.deltaCount++
+}
+
+func (isl *island) isSimilarPolarity(other *island) bool {
+	// TODO: consider islands to be of similar polarity only if they're having the same average delta (we don't want to consider 3 singletaps similar to a triple)
+	// naively adding delta check here breaks _a lot_ of maps because of the flawed ratio calculation
+	return isl.deltaCount%2 == other.deltaCount%2
+}
+
+func (isl *island) equals(other *island) bool {
+	return math.Abs(float64(isl.delta-other.delta)) < isl.deltaDifferenceEpsilon && isl.deltaCount == other.deltaCount
+}
+
+type islandCount struct {
+	island *island
+	count  int
+}
+
+// evaluateRhythm calculates a rhythm multiplier for the difficulty of the tap associated with historic data of the current DifficultyObject
+func evaluateRhythm(current *preprocessing.DifficultyObject) float64 {
+	if _, ok := current.BaseObject.(*objects.Spinner); ok {
+		return 0
+	}
+
+	rhythmComplexitySum := 0.0
+
+	deltaDifferenceEpsilon := current.HitWindowGreat * 0.3
+
+	isl := newEmptyIsland(deltaDifferenceEpsilon)
+	previousIsland := newEmptyIsland(deltaDifferenceEpsilon)
+
+	// we can't use dictionary here because we need to compare island with a tolerance
+	// which is impossible to pass into the hash comparer
+	var islandCounts []*islandCount
+
+	startRatio := 0.0 // store the ratio of the current start of an island to buff for tighter rhythms
+
+	firstDeltaSwitch := false
+
+	historicalNoteCount := mutils.Min(current.Index, historyObjectsMax)
+
+	rhythmStart := 0
+
+	for rhythmStart < historicalNoteCount-2 && current.StartTime-current.Previous(rhythmStart).StartTime < historyTimeMax {
+		rhythmStart++
+	}
+
+	prevObj := current.Previous(rhythmStart)
+	lastObj := current.Previous(rhythmStart + 1)
+
+	if prevObj == nil || lastObj == nil {
+		return 1
+	}
+
+	// we go from the furthest object back to the current one
+	for i := rhythmStart; i > 0; i-- {
+		currObj := current.Previous(i - 1)
+
+		// scales note 0 to 1 from history to now
+		timeDecay := (historyTimeMax - (current.StartTime - currObj.StartTime)) / historyTimeMax
+		noteDecay := float64(historicalNoteCount-i) / float64(historicalNoteCount)
+
+		currHistoricalDecay := math.Min(noteDecay, timeDecay) // either we're limited by time or limited by object count.
+
+		currDelta := currObj.StrainTime
+		prevDelta := prevObj.StrainTime
+		lastDelta := lastObj.StrainTime
+
+		// calculate how much current delta difference deserves a rhythm bonus
+		// this function is meant to reduce rhythm bonus for deltas that are multiples of each other (i.e 100 and 200)
+		deltaDifferenceRatio := math.Min(prevDelta, currDelta) / math.Max(prevDelta, currDelta)
+		currRatio := 1.0 + rhythmRatioMultiplier*math.Min(0.5, math.Pow(math.Sin(math.Pi/deltaDifferenceRatio), 2))
+
+		// reduce ratio bonus if delta difference is too big
+		fraction := math.Max(prevDelta/currDelta, currDelta/prevDelta)
+		fractionMultiplier := mutils.ClampF(2.0-fraction/8.0, 0.0, 1.0)
+
+		windowPenalty := math.Min(1, math.Max(0, math.Abs(prevDelta-currDelta)-deltaDifferenceEpsilon)/deltaDifferenceEpsilon)
+
+		effectiveRatio := windowPenalty * currRatio * fractionMultiplier
+
+		_, currSlider := currObj.BaseObject.(*preprocessing.LazySlider)
+		_, prevSlider := prevObj.BaseObject.(*preprocessing.LazySlider)
+
+		if firstDeltaSwitch {
+			if math.Abs(prevDelta-currDelta) < deltaDifferenceEpsilon {
+				// island is still progressing
+				isl.addDelta(int(currDelta))
+			} else {
+				// bpm change is into slider, this is easy acc window
+				if currSlider {
+					effectiveRatio *= 0.125
+				}
+
+				// bpm change was from a slider, this is easier typically than circle -> circle
+				// unintentional side effect is that bursts with kicksliders at the ends might have lower difficulty than bursts without sliders
+				if prevSlider {
+					effectiveRatio *= 0.3
+				}
+
+				// repeated island polarity (2 -> 4, 3 -> 5)
+				if isl.isSimilarPolarity(previousIsland) {
+					effectiveRatio *= 0.5
+				}
+
+				// previous increase happened a note ago, 1/1->1/2-1/4, dont want to buff this.
+				if lastDelta > prevDelta+deltaDifferenceEpsilon && prevDelta > currDelta+deltaDifferenceEpsilon {
+					effectiveRatio *= 0.125
+				}
+
+				// repeated island size (ex: triplet -> triplet)
+				// TODO: remove this nerf since its staying here only for balancing purposes because of the flawed ratio calculation
+				if previousIsland.deltaCount == isl.deltaCount {
+					effectiveRatio *= 0.5
+				}
+
+				var found *islandCount
+
+				for _, c := range islandCounts {
+					if c.island.equals(isl) {
+						found = c
+						break
+					}
+				}
+
+				if found != nil {
+					// only add island to island counts if they're going one after another
+					if previousIsland.equals(isl) {
+						found.count++
+					}
+
+					// repeated island (ex: triplet -> triplet)
+					power := logistic(float64(isl.delta), 58.33, 0.24, 2.75)
+					effectiveRatio *= math.Min(3.0/float64(found.count), math.Pow(1.0/float64(found.count), power))
+				} else {
+					islandCounts = append(islandCounts, &islandCount{island: isl, count: 1})
+				}
+
+				// scale down the difficulty if the object is doubletappable
+				doubletapness := prevObj.GetDoubletapness(currObj)
+				effectiveRatio *= 1 - doubletapness*0.75
+
+				rhythmComplexitySum += math.Sqrt(effectiveRatio*startRatio) * currHistoricalDecay
+
+				startRatio = effectiveRatio
+
+				previousIsland = isl
+
+				// we're slowing down, stop counting
+				if prevDelta+deltaDifferenceEpsilon < currDelta {
+					// if we're speeding up, this stays true and we keep counting island size.
+					firstDeltaSwitch = false
+				}
+
+				isl = newIsland(int(currDelta), deltaDifferenceEpsilon)
+			}
+		} else if prevDelta > currDelta+deltaDifferenceEpsilon { // we're speeding up.
+			// Begin counting island until we change speed again.
+			firstDeltaSwitch = true
+
+			// bpm change is into slider, this is easy acc window
+			if currSlider {
+				effectiveRatio *= 0.6
+			}
+
+			// bpm change was from a slider, this is easier typically than circle -> circle
+			// unintentional side effect is that bursts with kicksliders at the ends might have lower difficulty than bursts without sliders
+			if prevSlider {
+				effectiveRatio *= 0.6
+			}
+
+			startRatio = effectiveRatio
+
+			isl = newIsland(int(currDelta), deltaDifferenceEpsilon)
+		}
+
+		lastObj = prevObj
+		prevObj = currObj
+	}
+
+	return math.Sqrt(4+rhythmComplexitySum*rhythmOverallMultiplier) / 2.0 // produces multiplier that can be applied to strain. range [1, infinity) (not really though)
+}
+
+func logistic(x, midpointOffset, multiplier, maxValue float64) float64 {
+	return maxValue / (1 + math.Exp(multiplier*(midpointOffset-x)))
+}
@@ -0,0 +1,169 @@
+package skills
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/pp241007/preprocessing"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+	"sort"
+)
+
+type Skill struct {
+	// Determines how quickly strain decays for the given skill.
+	// For example a value of 0.15 indicates that strain decays to 15% of its original value in one second.
+	StrainDecayBase float64
+
+	// The weight by which each strain value decays.
+	DecayWeight float64
+
+	// The length of each strain section.
+	SectionLength float64
+
+	// Number of sections which strain value will be reduced.
+	ReducedSectionCount int
+
+	// Multiplier applied to the section with the biggest strain.
+	ReducedStrainBaseline float64
+
+	// Strain values of every processed DifficultyObject
+	ObjectStrains []float64
+
+	// The current strain level
+	CurrentStrain float64
+
+	// Delegate to calculate strain of skill at given DifficultyObject, it has to update CurrentStrain
+	StrainValueAt func(obj *preprocessing.DifficultyObject) float64
+
+	// Delegate to calculate strain at the start of new section
+	CalculateInitialStrain func(time float64, current *preprocessing.DifficultyObject) float64
+
+	currentSectionPeak float64
+	currentSectionEnd  float64
+
+	strainPeaks []float64
+
+	difficulty float64
+
+	diff *difficulty.Difficulty
+}
+
+func NewSkill(d *difficulty.Difficulty) *Skill {
+	skill := &Skill{
+		DecayWeight:           0.9,
+		SectionLength:         400,
+		ReducedSectionCount:   10,
+		ReducedStrainBaseline: 0.75,
+		diff:                  d,
+	}
+
+	skill.CalculateInitialStrain = func(time float64, current *preprocessing.DifficultyObject) float64 {
+		return skill.CurrentStrain * skill.strainDecay(time-current.Previous(0).StartTime)
+	}
+
+	return skill
+}
+
+// Process processes given DifficultyObject
+func (skill *Skill) Process(current *preprocessing.DifficultyObject) {
+	// The first object doesn't generate a strain, so we begin with an incremented section end
+	if current.Index == 0 {
+		skill.currentSectionEnd = math.Ceil(current.StartTime/skill.SectionLength) * skill.SectionLength
+	}
+
+	for current.StartTime > skill.currentSectionEnd {
+		skill.saveCurrentPeak()
+		skill.startNewSectionFrom(skill.currentSectionEnd, current)
+
+		skill.currentSectionEnd += skill.SectionLength
+	}
+
+	strain := skill.StrainValueAt(current)
+
+	skill.currentSectionPeak = math.Max(strain, skill.currentSectionPeak)
+
+	skill.ObjectStrains = append(skill.ObjectStrains, strain)
+}
+
+func (skill *Skill) GetCurrentStrainPeaks() []float64 {
+	peaks := make([]float64, len(skill.strainPeaks)+1)
+	copy(peaks, skill.strainPeaks)
+	peaks[len(peaks)-1] = skill.currentSectionPeak
+
+	return peaks
+}
+
+func (skill *Skill) DifficultyValue() float64 {
+	diff := 0.0
+	weight := 1.0
+
+	strains := make([]float64, 0, len(skill.strainPeaks)+1)
+
+	// Sections with 0 strain are excluded to avoid worst-case time complexity of the following sort (e.g. /b/2351871).
+	// These sections will not contribute to the difficulty.
+	for _, peak := range skill.GetCurrentStrainPeaks() {
+		if peak > 0 {
+			strains = append(strains, peak)
+		}
+	}
+
+	reverseSortFloat64s(strains)
+
+	numReduced := mutils.Min(len(strains), skill.ReducedSectionCount)
+
+	for i := 0; i < numReduced; i++ {
+		scale := math.Log10(mutils.Lerp(1.0, 10.0, mutils.ClampF(float64(i)/float64(skill.ReducedSectionCount), 0, 1)))
+		strains[i] *= mutils.Lerp(skill.ReducedStrainBaseline, 1.0, scale)
+	}
+
+	reverseSortFloat64s(strains)
+
+	for _, strain := range strains {
+		diff += strain * weight
+		weight *= skill.DecayWeight
+	}
+
+	skill.difficulty = diff
+
+	return diff
+}
+
+// CountDifficultStrains returns the number of object strains weighted against the top strain, DifficultyValue has to be called first
+func (skill *Skill) CountDifficultStrains() float64 {
+	if skill.difficulty == 0 {
+		return 0
+	}
+
+	// What would the top strain be if all strain values were identical
+	consistentTopStrain := skill.difficulty / 10
+
+	sum := 0.0
+
+	// Use a weighted sum of all strains. Constants are arbitrary and give nice values
+	for _, strain := range skill.ObjectStrains {
+		sum += 1.1 / (1 + math.Exp(-10*(strain/consistentTopStrain-0.88)))
+	}
+
+	return sum
+}
+
+func (skill *Skill) strainDecay(ms float64) float64 {
+	return math.Pow(skill.StrainDecayBase, ms/1000)
+}
+
+func (skill *Skill) saveCurrentPeak() {
+	skill.strainPeaks = append(skill.strainPeaks, skill.currentSectionPeak)
+}
+
+func (skill *Skill) startNewSectionFrom(end float64, current *preprocessing.DifficultyObject) {
+	skill.currentSectionPeak = skill.CalculateInitialStrain(end, current)
+}
+
+func reverseSortFloat64s(arr []float64) {
+	sort.Float64s(arr)
+
+	n := len(arr)
+	for i := 0; i < n/2; i++ {
+		j := n - i - 1
+		arr[i], arr[j] = arr[j], arr[i]
+	}
+}
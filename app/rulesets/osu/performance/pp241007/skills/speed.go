@@ -0,0 +1,105 @@
+package skills
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/pp241007/preprocessing"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+const (
+	speedSkillMultiplier float64 = 1.430
+
+	singleSpacingThreshold float64 = 125.0 // 1.25 circles distance between centers
+	minSpeedBonus          float64 = 75.0  // ~200BPM
+	speedBalancingFactor   float64 = 40
+	distanceMultiplier     float64 = 0.94
+)
+
+type SpeedSkill struct {
+	*Skill
+
+	CurrentRhythm float64
+}
+
+func NewSpeedSkill(d *difficulty.Difficulty) *SpeedSkill {
+	skill := &SpeedSkill{
+		Skill: NewSkill(d),
+	}
+
+	skill.StrainDecayBase = 0.3
+	skill.ReducedSectionCount = 5
+	skill.StrainValueAt = skill.speedStrainValue
+	skill.CalculateInitialStrain = skill.speedInitialStrain
+
+	return skill
+}
+
+func (s *SpeedSkill) speedStrainValue(current *preprocessing.DifficultyObject) float64 {
+	s.CurrentStrain *= s.strainDecay(current.StrainTime)
+	s.CurrentStrain += s.evaluateSpeed(current) * speedSkillMultiplier
+
+	s.CurrentRhythm = evaluateRhythm(current)
+
+	return s.CurrentStrain * s.CurrentRhythm
+}
+
+func (s *SpeedSkill) speedInitialStrain(time float64, current *preprocessing.DifficultyObject) float64 {
+	return (s.CurrentStrain * s.CurrentRhythm) * s.strainDecay(time-current.Previous(0).StartTime)
+}
+
+// RelevantNoteCount returns the number of notes weighted against the top strain
+func (s *SpeedSkill) RelevantNoteCount() float64 {
+	maxStrain := 0.0
+
+	for _, strain := range s.ObjectStrains {
+		maxStrain = math.Max(maxStrain, strain)
+	}
+
+	if maxStrain == 0 {
+		return 0
+	}
+
+	sum := 0.0
+
+	for _, strain := range s.ObjectStrains {
+		sum += 1.0 / (1.0 + math.Exp(-(strain/maxStrain*12.0 - 6.0)))
+	}
+
+	return sum
+}
+
+func (s *SpeedSkill) evaluateSpeed(current *preprocessing.DifficultyObject) float64 {
+	if _, ok := current.BaseObject.(*objects.Spinner); ok {
+		return 0
+	}
+
+	strainTime := current.StrainTime
+	doubletapness := 1.0 - current.GetDoubletapness(current.Next(0))
+
+	// Cap deltatime to the OD 300 hitwindow.
+	// 0.93 is derived from making sure 260bpm OD8 streams aren't nerfed harshly, whilst 0.92 limits the effect of the cap.
+	strainTime /= mutils.ClampF((strainTime/current.HitWindowGreat)/0.93, 0.92, 1)
+
+	// speedBonus will be 0.0 for BPM < 200
+	speedBonus := 0.0
+
+	// Add additional scaling bonus for streams/bursts higher than 200bpm
+	if strainTime < minSpeedBonus {
+		speedBonus = 0.75 * math.Pow((minSpeedBonus-strainTime)/speedBalancingFactor, 2)
+	}
+
+	// Speed considers only the shortest movement, travel distance of a previous slider is added to it
+	distance := math.Min(singleSpacingThreshold, current.TravelDistance+current.MovementDistance)
+
+	// Max distance bonus is 1 * `distance_multiplier` at single_spacing_threshold
+	distanceBonus := math.Pow(distance/singleSpacingThreshold, 3.95) * distanceMultiplier
+
+	if s.diff.CheckModActive(difficulty.Relax2) {
+		distanceBonus = 0
+	}
+
+	// Base difficulty with all bonuses
+	return (1 + speedBonus + distanceBonus) * 1000 / strainTime * doubletapness
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/wieku/danser-go/app/beatmap/objects"
 	"github.com/wieku/danser-go/app/graphics"
 	"github.com/wieku/danser-go/app/rulesets/osu/performance"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/app/utils"
 	"github.com/wieku/danser-go/framework/math/mutils"
@@ -53,11 +54,16 @@ type HitObject interface {
 	IsHit(player *difficultyPlayer) bool
 	GetFadeTime() int64
 	GetNumber() int64
+
+	saveState() interface{}
+	restoreState(state interface{})
+	resetState()
 }
 
 type difficultyPlayer struct {
 	cursor          *graphics.Cursor
 	diff            *difficulty.Difficulty
+	lazer           bool
 	DoubleClick     bool
 	alreadyStolen   bool
 	buttons         buttonState
@@ -77,6 +83,7 @@ type scoreProcessor interface {
 	ModifyResult(result HitResult, src HitObject) HitResult
 	GetScore() int64
 	GetCombo() int64
+	clone() scoreProcessor
 }
 
 type Score struct {
@@ -92,7 +99,7 @@ type Score struct {
 	Count50      uint
 	CountMiss    uint
 	CountSB      uint
-	PP           performance.PPv2Results
+	PP           api.PPv2Results
 }
 
 type subSet struct {
@@ -107,14 +114,14 @@ type subSet struct {
 
 	numObjects uint
 
-	ppv2 *performance.PPv2
+	ppResults api.PPv2Results
 
 	recoveries int
 	failed     bool
 	sdpfFail   bool
 }
 
-type hitListener func(cursor *graphics.Cursor, time int64, number int64, position vector.Vector2d, result HitResult, comboResult ComboResult, ppResults performance.PPv2Results, score int64)
+type hitListener func(cursor *graphics.Cursor, time int64, number int64, position vector.Vector2d, result HitResult, comboResult ComboResult, ppResults api.PPv2Results, score int64)
 
 type endListener func(time int64, number int64)
 
@@ -126,7 +133,7 @@ type OsuRuleSet struct {
 
 	ended bool
 
-	oppDiffs map[difficulty.Modifier][]performance.Attributes
+	oppDiffs map[difficulty.Modifier][]api.Attributes
 
 	queue        []HitObject
 	processed    []HitObject
@@ -134,7 +141,17 @@ type OsuRuleSet struct {
 	endListener  endListener
 	failListener failListener
 
-	experimentalPP bool
+	additionalListeners []hitListener
+
+	ppCalculator api.IPerformanceCalculator
+
+	visualFeedback bool
+
+	rewindable      bool
+	history         []hitEvent
+	rewindListeners []rewindListener
+
+	annotationListeners []annotationListener
 }
 
 func NewOsuRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []difficulty.Modifier) *OsuRuleSet {
@@ -142,18 +159,25 @@ func NewOsuRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []
 
 	ruleset := new(OsuRuleSet)
 	ruleset.beatMap = beatMap
-	ruleset.oppDiffs = make(map[difficulty.Modifier][]performance.Attributes)
-
-	if settings.Gameplay.UseLazerPP {
-		log.Println("Using pp calc version 2022-01-23:")
-		log.Println("\tRemove decay factor in Flashlight skill: https://github.com/ppy/osu/pull/15728")
-		log.Println("\tMake speed skill consider only the shortest movement distance: https://github.com/ppy/osu/pull/15758")
-		log.Println("\tFix cumulative strain time calculation in Flashlight skill: https://github.com/ppy/osu/pull/15867")
-		log.Println("\tRemove combo scaling from Aim and Speed from osu! performance calculation: https://github.com/ppy/osu/pull/16280")
-		log.Println("\tDon't floor effectiveMissCount: https://github.com/ppy/osu/pull/16331")
-		ruleset.experimentalPP = true
+	ruleset.visualFeedback = true
+	ruleset.oppDiffs = make(map[difficulty.Modifier][]api.Attributes)
+
+	diffCalculator := performance.GetDifficultyCalculator()
+	ruleset.ppCalculator = performance.GetPPCalculator()
+
+	if performance.GetVersion() == performance.Version20211112 {
+		if settings.Gameplay.UseLazerPP {
+			log.Println("Using pp calc version 2022-01-23:")
+			log.Println("\tRemove decay factor in Flashlight skill: https://github.com/ppy/osu/pull/15728")
+			log.Println("\tMake speed skill consider only the shortest movement distance: https://github.com/ppy/osu/pull/15758")
+			log.Println("\tFix cumulative strain time calculation in Flashlight skill: https://github.com/ppy/osu/pull/15867")
+			log.Println("\tRemove combo scaling from Aim and Speed from osu! performance calculation: https://github.com/ppy/osu/pull/16280")
+			log.Println("\tDon't floor effectiveMissCount: https://github.com/ppy/osu/pull/16331")
+		} else {
+			log.Println("Using pp calc version 2021-11-09 with hotfix: https://osu.ppy.sh/home/news/2021-11-09-performance-points-star-rating-updates")
+		}
 	} else {
-		log.Println("Using pp calc version 2021-11-09 with hotfix: https://osu.ppy.sh/home/news/2021-11-09-performance-points-star-rating-updates")
+		log.Println("Using pp calc version 2024-10-07")
 	}
 
 	ruleset.cursors = make(map[*graphics.Cursor]*subSet)
@@ -170,12 +194,15 @@ func NewOsuRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []
 
 		diff.SetMods(mods[i] | (beatMap.Diff.Mods & difficulty.ScoreV2)) // if beatmap has ScoreV2 mod, force it for all players
 		diff.SetCustomSpeed(beatMap.Diff.CustomSpeed)
+		diff.LazerMods = beatMap.Diff.LazerMods
+
+		lazer := settings.Gameplay.Ruleset == "lazer" || (settings.Gameplay.Ruleset == "auto" && cursor.LazerReplay)
 
-		player := &difficultyPlayer{cursor: cursor, diff: diff}
+		player := &difficultyPlayer{cursor: cursor, diff: diff, lazer: lazer}
 		diffPlayers = append(diffPlayers, player)
 
 		if ruleset.oppDiffs[mods[i]&difficulty.DifficultyAdjustMask] == nil {
-			ruleset.oppDiffs[mods[i]&difficulty.DifficultyAdjustMask] = performance.CalculateStep(ruleset.beatMap.HitObjects, diff, ruleset.experimentalPP)
+			ruleset.oppDiffs[mods[i]&difficulty.DifficultyAdjustMask] = diffCalculator.CalculateStep(ruleset.beatMap.HitObjects, diff)
 
 			star := ruleset.oppDiffs[mods[i]&difficulty.DifficultyAdjustMask][len(ruleset.oppDiffs[mods[i]&difficulty.DifficultyAdjustMask])-1]
 
@@ -183,30 +210,37 @@ func NewOsuRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []
 			log.Println("\tAim:  ", star.Aim)
 			log.Println("\tSpeed:", star.Speed)
 
-			if ruleset.experimentalPP && mods[i].Active(difficulty.Flashlight) {
+			if mods[i].Active(difficulty.Flashlight) {
 				log.Println("\tFlash:", star.Flashlight)
 			}
 
 			log.Println("\tTotal:", star.Total)
 
-			pp := &performance.PPv2{}
-			pp.PPv2x(star, -1, -1, 0, 0, 0, diff, false)
+			pp := ruleset.ppCalculator.Calculate(star, -1, -1, 0, 0, 0, diff)
 
 			log.Println("SS PP:")
-			log.Println("\tAim:  ", pp.Results.Aim)
-			log.Println("\tTap:  ", pp.Results.Speed)
+			log.Println("\tAim:  ", pp.Aim)
+			log.Println("\tTap:  ", pp.Speed)
 
-			if ruleset.experimentalPP && mods[i].Active(difficulty.Flashlight) {
-				log.Println("\tFlash:", star.Flashlight)
+			if mods[i].Active(difficulty.Flashlight) {
+				log.Println("\tFlash:", pp.Flashlight)
 			}
 
-			log.Println("\tAcc:  ", pp.Results.Acc)
-			log.Println("\tTotal:", pp.Results.Total)
+			log.Println("\tAcc:  ", pp.Acc)
+			log.Println("\tTotal:", pp.Total)
 		}
 
 		log.Println(fmt.Sprintf("Calculating HP rates for \"%s\"...", cursor.Name))
 
-		hp := NewHealthProcessor(beatMap, diff, !cursor.OldSpinnerScoring)
+		var hp *HealthProcessor
+		if lazer {
+			log.Println(fmt.Sprintf("Using osu!lazer judgement rules for \"%s\"", cursor.Name))
+
+			hp = NewLazerHealthProcessor(beatMap, diff)
+		} else {
+			hp = NewHealthProcessor(beatMap, diff, !cursor.OldSpinnerScoring)
+		}
+
 		hp.CalculateRate()
 		hp.ResetHp()
 
@@ -225,7 +259,9 @@ func NewOsuRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []
 
 		var sc scoreProcessor
 
-		if diff.CheckModActive(difficulty.ScoreV2) {
+		if lazer {
+			sc = newScoreLazerProcessor()
+		} else if diff.CheckModActive(difficulty.ScoreV2) {
 			sc = newScoreV2Processor()
 		} else {
 			sc = newScoreV1Processor()
@@ -238,7 +274,6 @@ func NewOsuRuleset(beatMap *beatmap.BeatMap, cursors []*graphics.Cursor, mods []
 			score: &Score{
 				Accuracy: 100,
 			},
-			ppv2:           &performance.PPv2{},
 			hp:             hp,
 			recoveries:     recoveries,
 			scoreProcessor: sc,
@@ -332,7 +367,7 @@ func (set *OsuRuleSet) Update(time int64) {
 			data = append(data, utils.Humanize(set.cursors[c].scoreProcessor.GetCombo()))
 			data = append(data, utils.Humanize(set.cursors[c].score.Combo))
 			data = append(data, set.cursors[c].player.diff.GetModString())
-			data = append(data, fmt.Sprintf("%.2f", set.cursors[c].ppv2.Results.Total))
+			data = append(data, fmt.Sprintf("%.2f", set.cursors[c].ppResults.Total))
 			table.Append(data)
 		}
 
@@ -432,8 +467,8 @@ func (set *OsuRuleSet) SendResult(time int64, cursor *graphics.Cursor, src HitOb
 	subSet := set.cursors[cursor]
 
 	if result == Ignore || result == PositionalMiss {
-		if result == PositionalMiss && set.hitListener != nil && !subSet.player.diff.Mods.Active(difficulty.Relax) {
-			set.hitListener(cursor, time, number, vector.NewVec2f(x, y).Copy64(), result, comboResult, subSet.ppv2.Results, subSet.scoreProcessor.GetScore())
+		if result == PositionalMiss && !subSet.player.diff.Mods.Active(difficulty.Relax) {
+			set.notifyListeners(cursor, time, number, vector.NewVec2f(x, y).Copy64(), result, comboResult, subSet.ppResults, subSet.scoreProcessor.GetScore())
 		}
 
 		return
@@ -511,15 +546,20 @@ func (set *OsuRuleSet) SendResult(time int64, cursor *graphics.Cursor, src HitOb
 		subSet.score.Grade = D
 	}
 
+	if lazerScore, ok := subSet.scoreProcessor.(*scoreLazerProcessor); ok {
+		subSet.score.Accuracy = 100 * lazerScore.GetAccuracy()
+		subSet.score.Grade = lazerScore.GetGrade()
+	}
+
 	index := mutils.Max(1, subSet.numObjects) - 1
 
 	diff := set.oppDiffs[subSet.player.diff.Mods&difficulty.DifficultyAdjustMask][index]
 
 	subSet.score.PerfectCombo = uint(diff.MaxCombo) == subSet.score.Combo
 
-	subSet.ppv2.PPv2x(diff, int(subSet.score.Combo), int(subSet.score.Count300), int(subSet.score.Count100), int(subSet.score.Count50), int(subSet.score.CountMiss), subSet.player.diff, set.experimentalPP)
+	subSet.ppResults = set.ppCalculator.Calculate(diff, int(subSet.score.Combo), int(subSet.score.Count300), int(subSet.score.Count100), int(subSet.score.Count50), int(subSet.score.CountMiss), subSet.player.diff)
 
-	subSet.score.PP = subSet.ppv2.Results
+	subSet.score.PP = subSet.ppResults
 
 	switch result {
 	case Hit100:
@@ -567,13 +607,11 @@ func (set *OsuRuleSet) SendResult(time int64, cursor *graphics.Cursor, src HitOb
 
 	if subSet.sdpfFail {
 		subSet.hp.Increase(-100000, true)
-	} else {
+	} else if !subSet.player.lazer || result != SliderMiss || !isTailMiss(src, subSet.player) { // osu!lazer's slider tail miss doesn't affect health
 		subSet.hp.AddResult(result)
 	}
 
-	if set.hitListener != nil {
-		set.hitListener(cursor, time, number, vector.NewVec2f(x, y).Copy64(), result, comboResult, subSet.ppv2.Results, subSet.scoreProcessor.GetScore())
-	}
+	set.notifyListeners(cursor, time, number, vector.NewVec2f(x, y).Copy64(), result, comboResult, subSet.ppResults, subSet.scoreProcessor.GetScore())
 
 	if len(set.cursors) == 1 && !settings.RECORD {
 		log.Println(fmt.Sprintf(
@@ -591,12 +629,18 @@ func (set *OsuRuleSet) SendResult(time int64, cursor *graphics.Cursor, src HitOb
 			time,
 			x,
 			y,
-			subSet.ppv2.Results.Total,
+			subSet.ppResults.Total,
 		))
 	}
 }
 
 func (set *OsuRuleSet) CanBeHit(time int64, object HitObject, player *difficultyPlayer) ClickAction {
+	action, _ := set.canBeHit(time, object, player)
+	return action
+}
+
+// canBeHit additionally returns the object which has to be hit first if the click is notelocked
+func (set *OsuRuleSet) canBeHit(time int64, object HitObject, player *difficultyPlayer) (ClickAction, HitObject) {
 	if _, ok := object.(*Circle); ok {
 		index := -1
 
@@ -607,7 +651,7 @@ func (set *OsuRuleSet) CanBeHit(time int64, object HitObject, player *difficulty
 		}
 
 		if index > 0 && set.beatMap.HitObjects[set.processed[index-1].GetNumber()].GetStackIndex(player.diff.Mods) > 0 && !set.processed[index-1].IsHit(player) {
-			return Ignored //don't shake the stacks
+			return Ignored, nil //don't shake the stacks
 		}
 	}
 
@@ -615,7 +659,7 @@ func (set *OsuRuleSet) CanBeHit(time int64, object HitObject, player *difficulty
 		if !g.IsHit(player) {
 			if g.GetNumber() != object.GetNumber() {
 				if set.beatMap.HitObjects[g.GetNumber()].GetEndTime()+Tolerance2B < set.beatMap.HitObjects[object.GetNumber()].GetStartTime() {
-					return Shake
+					return Shake, g
 				}
 			} else {
 				break
@@ -629,10 +673,10 @@ func (set *OsuRuleSet) CanBeHit(time int64, object HitObject, player *difficulty
 	}
 
 	if math.Abs(float64(time-int64(set.beatMap.HitObjects[object.GetNumber()].GetStartTime()))) >= hitRange {
-		return Shake
+		return Shake, nil
 	}
 
-	return Click
+	return Click, nil
 }
 
 func (set *OsuRuleSet) failInternal(player *difficultyPlayer) {
@@ -671,6 +715,23 @@ func (set *OsuRuleSet) SetListener(listener hitListener) {
 	set.hitListener = listener
 }
 
+// AddListener registers additional judgement listener that works alongside the one set by SetListener
+func (set *OsuRuleSet) AddListener(listener hitListener) {
+	set.additionalListeners = append(set.additionalListeners, listener)
+}
+
+func (set *OsuRuleSet) notifyListeners(cursor *graphics.Cursor, time int64, number int64, position vector.Vector2d, result HitResult, comboResult ComboResult, ppResults api.PPv2Results, score int64) {
+	set.recordEvent(cursor, time, number, position, result, comboResult, ppResults, score)
+
+	if set.hitListener != nil {
+		set.hitListener(cursor, time, number, position, result, comboResult, ppResults, score)
+	}
+
+	for _, listener := range set.additionalListeners {
+		listener(cursor, time, number, position, result, comboResult, ppResults, score)
+	}
+}
+
 func (set *OsuRuleSet) SetEndListener(listener endListener) {
 	set.endListener = listener
 }
@@ -693,10 +754,42 @@ func (set *OsuRuleSet) GetPlayer(cursor *graphics.Cursor) *difficultyPlayer {
 	return subSet.player
 }
 
+func (set *OsuRuleSet) GetDifficulty(cursor *graphics.Cursor) *difficulty.Difficulty {
+	return set.cursors[cursor].player.diff
+}
+
 func (set *OsuRuleSet) GetProcessed() []HitObject {
 	return set.processed
 }
 
+// GetCurrentObject returns the earliest object not hit yet by the cursor, clicks on later objects are shaken (notelocked) until it's hit.
+// Returns nil if there are no objects in hittable range.
+func (set *OsuRuleSet) GetCurrentObject(cursor *graphics.Cursor) HitObject {
+	player := set.cursors[cursor].player
+
+	for _, g := range set.processed {
+		if !g.IsHit(player) {
+			return g
+		}
+	}
+
+	return nil
+}
+
 func (set *OsuRuleSet) GetBeatMap() *beatmap.BeatMap {
 	return set.beatMap
 }
+
+func (set *OsuRuleSet) IsEnded() bool {
+	return set.ended
+}
+
+// SetVisualFeedback controls whether hit objects should animate and play hitsounds on judgements.
+// It should be disabled if ruleset is not the one driving the visible beatmap or if it runs without graphics and audio.
+func (set *OsuRuleSet) SetVisualFeedback(value bool) {
+	set.visualFeedback = value
+}
+
+func (set *OsuRuleSet) showFeedback(players []*difficultyPlayer) bool {
+	return set.visualFeedback && len(players) == 1
+}
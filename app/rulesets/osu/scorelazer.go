@@ -0,0 +1,226 @@
+package osu
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/settings"
+	"math"
+)
+
+const lazerMaxScore = 1000000.0
+
+// scoreLazerProcessor implements osu!lazer's standardised scoring.
+// Slider heads are judged like circles, ticks, repeats and tails only add to accuracy and combo portion.
+type scoreLazerProcessor struct {
+	score         int64
+	combo         int64
+	modMultiplier float64
+
+	comboPortion    float64
+	comboPortionMax float64
+
+	baseScore    float64
+	baseScoreMax float64
+
+	// basicJudgements counts judgements of circles, slider heads and spinners, only they make accuracy progress
+	basicJudgements    int64
+	basicJudgementsMax int64
+
+	bonus float64
+
+	basicObjects int64
+	misses       int64
+
+	// missedType is the judgement SliderMiss replaces, set by ModifyResult
+	missedType HitResult
+
+	player *difficultyPlayer
+}
+
+func newScoreLazerProcessor() *scoreLazerProcessor {
+	return &scoreLazerProcessor{}
+}
+
+func (s *scoreLazerProcessor) Init(beatMap *beatmap.BeatMap, player *difficultyPlayer) {
+	s.player = player
+	s.modMultiplier = player.diff.GetScoreMultiplier()
+
+	for _, o := range beatMap.HitObjects {
+		if o.GetType() == objects.CIRCLE || o.GetType() == objects.SPINNER {
+			s.AddResult(Hit300, Increase)
+		} else if slider, ok := o.(*objects.Slider); ok {
+			s.AddResult(Hit300, Increase)
+
+			for j := 0; j < len(slider.TickReverse)-1; j++ {
+				s.AddResult(SliderRepeat, Increase)
+			}
+
+			for j := 0; j < len(slider.TickPoints); j++ {
+				s.AddResult(SliderPoint, Increase)
+			}
+
+			s.AddResult(SliderEnd, Increase)
+		}
+	}
+
+	s.comboPortionMax = s.comboPortion
+	s.basicJudgementsMax = s.basicJudgements
+
+	s.basicObjects = int64(len(beatMap.HitObjects))
+
+	s.combo = 0
+	s.score = 0
+	s.comboPortion = 0
+	s.baseScore = 0
+	s.baseScoreMax = 0
+	s.basicJudgements = 0
+	s.bonus = 0
+	s.misses = 0
+}
+
+func (s *scoreLazerProcessor) AddResult(result HitResult, comboResult ComboResult) {
+	if comboResult == Reset || result == Miss {
+		s.combo = 0
+	} else if comboResult == Increase {
+		s.combo++
+	}
+
+	result &= ^Additions
+
+	switch {
+	case result&(SpinnerPoints|SpinnerBonus) > 0:
+		s.bonus += lazerScoreValue(result)
+	case result&(BaseHitsM|SliderHits|SliderMiss) > 0:
+		maxType := result
+		if result&BaseHitsM > 0 {
+			maxType = Hit300
+		} else if result == SliderMiss {
+			maxType = s.missedType
+			if maxType == Ignore {
+				maxType = SliderPoint
+			}
+		}
+
+		value := lazerScoreValue(result)
+
+		s.baseScore += value
+		s.baseScoreMax += lazerScoreValue(maxType)
+		s.comboPortion += value * math.Sqrt(float64(s.combo))
+
+		if result&BaseHitsM > 0 {
+			s.basicJudgements++
+		}
+
+		if result == Miss {
+			s.misses++
+		}
+	}
+
+	s.missedType = Ignore
+
+	if s.basicJudgementsMax > 0 {
+		comboProgress := 0.0
+		if s.comboPortionMax > 0 {
+			comboProgress = s.comboPortion / s.comboPortionMax
+		}
+
+		accuracyProgress := float64(s.basicJudgements) / float64(s.basicJudgementsMax)
+
+		acc := s.GetAccuracy()
+
+		s.score = int64(math.Round((500000*acc*comboProgress + 500000*math.Pow(acc, 5)*accuracyProgress + s.bonus) * s.modMultiplier))
+	}
+}
+
+func (s *scoreLazerProcessor) ModifyResult(result HitResult, src HitObject) HitResult {
+	if result == SliderMiss {
+		if slider, ok := src.(*Slider); ok {
+			state := slider.state[s.player]
+
+			if index := state.scored + state.missed - 1; index >= 0 && index < len(state.points) {
+				s.missedType = state.points[index].scoreGiven
+			}
+		}
+	}
+
+	return result
+}
+
+func (s *scoreLazerProcessor) GetScore() int64 {
+	if settings.Gameplay.LazerClassicScore {
+		return int64(math.Round((float64(s.basicObjects*s.basicObjects)*32.57 + 100000) * float64(s.score) / lazerMaxScore))
+	}
+
+	return s.score
+}
+
+func (s *scoreLazerProcessor) GetCombo() int64 {
+	return s.combo
+}
+
+// GetAccuracy returns accuracy in 0-1 range, ticks and slider tails are included
+func (s *scoreLazerProcessor) GetAccuracy() float64 {
+	if s.baseScoreMax == 0 {
+		return 1
+	}
+
+	return s.baseScore / s.baseScoreMax
+}
+
+func (s *scoreLazerProcessor) GetGrade() Grade {
+	acc := s.GetAccuracy()
+
+	var grade Grade
+
+	switch {
+	case acc == 1:
+		grade = SS
+	case acc >= 0.95 && s.misses == 0:
+		grade = S
+	case acc >= 0.9:
+		grade = A
+	case acc >= 0.8:
+		grade = B
+	case acc >= 0.7:
+		grade = C
+	default:
+		grade = D
+	}
+
+	if s.player.diff.Mods&(difficulty.Hidden|difficulty.Flashlight) > 0 {
+		if grade == SS {
+			grade = SSH
+		} else if grade == S {
+			grade = SH
+		}
+	}
+
+	return grade
+}
+
+func (s *scoreLazerProcessor) clone() scoreProcessor {
+	c := *s
+	return &c
+}
+
+func lazerScoreValue(result HitResult) float64 {
+	switch result {
+	case Hit50:
+		return 50
+	case Hit100:
+		return 100
+	case Hit300:
+		return 300
+	case SliderStart, SliderPoint, SliderRepeat:
+		return 30
+	case SliderEnd:
+		return 150
+	case SpinnerPoints:
+		return 10
+	case SpinnerBonus:
+		return 50
+	}
+
+	return 0
+}
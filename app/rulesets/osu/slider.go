@@ -107,7 +107,7 @@ func (slider *Slider) UpdateClickFor(player *difficultyPlayer, time int64) bool
 	inRadius := player.cursor.RawPosition.Dst(position) <= radius
 
 	if clicked && !state.isStartHit && !state.isHit {
-		action := slider.ruleSet.CanBeHit(time, slider, player)
+		action, blocker := slider.ruleSet.canBeHit(time, slider, player)
 
 		if inRadius {
 			if action == Click {
@@ -143,11 +143,17 @@ func (slider *Slider) UpdateClickFor(player *difficultyPlayer, time int64) bool
 				if state.startResult != Miss {
 					hit = SliderStart
 					combo = Increase
+				} else {
+					slider.ruleSet.annotateMiss(time, slider, timingCause(time, slider.hitSlider.GetStartTime()), player, position)
+				}
+
+				if player.lazer { // osu!lazer judges slider heads like circles
+					hit = state.startResult
 				}
 
 				if hit != Ignore {
-					if len(slider.players) == 1 {
-						slider.hitSlider.HitEdge(0, float64(time), hit != SliderMiss)
+					if slider.ruleSet.showFeedback(slider.players) {
+						slider.hitSlider.HitEdge(0, float64(time), hit != SliderMiss && hit != Miss)
 					}
 
 					slider.ruleSet.SendResult(time, player.cursor, slider, position.X, position.Y, hit, combo)
@@ -157,6 +163,10 @@ func (slider *Slider) UpdateClickFor(player *difficultyPlayer, time int64) bool
 			} else {
 				player.leftCondE = false
 				player.rightCondE = false
+
+				if action == Shake {
+					slider.ruleSet.annotateShake(time, slider, blocker, player, position)
+				}
 			}
 		} else if action == Click {
 			slider.ruleSet.SendResult(time, player.cursor, slider, position.X, position.Y, PositionalMiss, Hold)
@@ -233,7 +243,7 @@ func (slider *Slider) UpdateFor(player *difficultyPlayer, time int64, processSli
 			state.sliding = true
 			state.slideStart = time
 
-			if len(slider.players) == 1 {
+			if slider.ruleSet.showFeedback(slider.players) {
 				slider.hitSlider.InitSlide(float64(time))
 			}
 		}
@@ -252,7 +262,13 @@ func (slider *Slider) UpdateFor(player *difficultyPlayer, time int64, processSli
 			index := state.scored + state.missed
 			point := state.points[index]
 
-			if allowable && state.slideStart <= point.time {
+			// osu!lazer accepts the tail if the slider is followed at any moment of its leniency window
+			lenientTail := player.lazer && index == len(state.points)-1 && !allowable &&
+				time < int64(slider.hitSlider.GetEndTime()) && !(processSliderEndsAhead && int64(slider.hitSlider.GetEndTime())-time == 1)
+
+			if lenientTail {
+				// wait for the end of the window
+			} else if allowable && (state.slideStart <= point.time || (player.lazer && index == len(state.points)-1)) {
 				state.scored++
 
 				var scoreGiven HitResult
@@ -273,12 +289,21 @@ func (slider *Slider) UpdateFor(player *difficultyPlayer, time int64, processSli
 					combo = Hold
 				}
 
+				cause := FollowCircleLeft
+				if state.scored+state.missed == len(state.points) {
+					cause = SliderEndMissed
+				} else if !mouseDownAcceptable {
+					cause = TickMissed
+				}
+
+				slider.ruleSet.annotateMiss(time, slider, cause, player, sliderPosition)
+
 				slider.ruleSet.SendResult(time, player.cursor, slider, sliderPosition.X, sliderPosition.Y, SliderMiss, combo)
 			}
 		}
 
 		if !allowable && state.sliding && state.scored+state.missed < len(state.points) {
-			if len(slider.players) == 1 {
+			if slider.ruleSet.showFeedback(slider.players) {
 				slider.hitSlider.KillSlide(float64(time))
 			}
 
@@ -293,13 +318,19 @@ func (slider *Slider) UpdatePostFor(player *difficultyPlayer, time int64, proces
 	state := slider.state[player]
 
 	if time > int64(slider.hitSlider.GetStartTime())+player.diff.Hit50 && !state.isStartHit {
-		if len(slider.players) == 1 {
+		if slider.ruleSet.showFeedback(slider.players) {
 			slider.hitSlider.ArmStart(false, float64(time))
 		}
 
 		position := slider.hitSlider.GetStackedEndPositionMod(player.diff.Mods)
 
-		slider.ruleSet.SendResult(time, player.cursor, slider, position.X, position.Y, SliderMiss, Reset)
+		hit := SliderMiss
+		if player.lazer {
+			hit = Miss
+		}
+
+		slider.ruleSet.annotateMiss(time, slider, TooLate, player, slider.hitSlider.GetStackedStartPositionMod(player.diff.Mods))
+		slider.ruleSet.SendResult(time, player.cursor, slider, position.X, position.Y, hit, Reset)
 
 		if player.leftCond {
 			state.downButton = Left
@@ -323,10 +354,15 @@ func (slider *Slider) UpdatePostFor(player *difficultyPlayer, time int64, proces
 
 		rate := float64(state.scored) / float64(len(state.points)+1)
 
-		if rate > 0 && len(slider.players) == 1 {
+		if rate > 0 && slider.ruleSet.showFeedback(slider.players) {
 			slider.hitSlider.HitEdge(len(slider.hitSlider.TickReverse), float64(time), true)
 		}
 
+		if player.lazer { // head, ticks and tail were already judged separately
+			state.isHit = true
+			return true
+		}
+
 		if rate == 1.0 {
 			hit = Hit300
 		} else if rate >= 0.5 {
@@ -378,3 +414,36 @@ func (slider *Slider) GetStartResult(pl *difficultyPlayer) HitResult {
 func (slider *Slider) GetFadeTime() int64 {
 	return int64(slider.hitSlider.GetStartTime() - slider.fadeStartRelative)
 }
+
+func (slider *Slider) saveState() interface{} {
+	states := make(map[*difficultyPlayer]sliderstate, len(slider.state))
+
+	// Tick points are never modified after Init, so sharing them between copies is safe
+	for player, state := range slider.state {
+		states[player] = *state
+	}
+
+	return states
+}
+
+func (slider *Slider) restoreState(state interface{}) {
+	for player, s := range state.(map[*difficultyPlayer]sliderstate) {
+		*slider.state[player] = s
+	}
+}
+
+func (slider *Slider) resetState() {
+	slider.Init(slider.ruleSet, slider.hitSlider, slider.players)
+}
+
+// isTailMiss returns true if the last judged point of the slider is its missed tail
+func isTailMiss(src HitObject, player *difficultyPlayer) bool {
+	slider, ok := src.(*Slider)
+	if !ok {
+		return false
+	}
+
+	state := slider.state[player]
+
+	return state.missed > 0 && state.scored+state.missed == len(state.points)
+}
@@ -0,0 +1,169 @@
+package osu
+
+import (
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
+	"github.com/wieku/danser-go/framework/math/vector"
+)
+
+type rewindListener func()
+
+type hitEvent struct {
+	cursor      *graphics.Cursor
+	time        int64
+	number      int64
+	position    vector.Vector2d
+	result      HitResult
+	comboResult ComboResult
+	ppResults   api.PPv2Results
+	score       int64
+}
+
+type subSetSnapshot struct {
+	player         difficultyPlayer
+	score          Score
+	hp             HealthProcessor
+	scoreProcessor scoreProcessor
+	rawScore       int64
+	currentKatu    int
+	currentBad     int
+	numObjects     uint
+	ppResults      api.PPv2Results
+	recoveries     int
+	failed         bool
+	sdpfFail       bool
+}
+
+// Snapshot holds complete state of the ruleset needed to move it back in time
+type Snapshot struct {
+	ended     bool
+	queue     []HitObject
+	processed []HitObject
+	states    map[HitObject]interface{}
+	players   map[*graphics.Cursor]*subSetSnapshot
+	numEvents int
+}
+
+// EnableRewinding makes ruleset keep the history of judgements, so listeners can rebuild their state after RestoreSnapshot
+func (set *OsuRuleSet) EnableRewinding() {
+	set.rewindable = true
+}
+
+func (set *OsuRuleSet) IsRewindable() bool {
+	return set.rewindable
+}
+
+func (set *OsuRuleSet) CreateSnapshot() *Snapshot {
+	snapshot := &Snapshot{
+		ended:     set.ended,
+		queue:     make([]HitObject, len(set.queue)),
+		processed: make([]HitObject, len(set.processed)),
+		states:    make(map[HitObject]interface{}),
+		players:   make(map[*graphics.Cursor]*subSetSnapshot),
+		numEvents: len(set.history),
+	}
+
+	copy(snapshot.queue, set.queue)
+	copy(snapshot.processed, set.processed)
+
+	// Objects in the queue weren't touched yet, so we need to save only the processed ones
+	for _, o := range set.processed {
+		snapshot.states[o] = o.saveState()
+	}
+
+	for cursor, subSet := range set.cursors {
+		snapshot.players[cursor] = &subSetSnapshot{
+			player:         *subSet.player,
+			score:          *subSet.score,
+			hp:             *subSet.hp,
+			scoreProcessor: subSet.scoreProcessor.clone(),
+			rawScore:       subSet.rawScore,
+			currentKatu:    subSet.currentKatu,
+			currentBad:     subSet.currentBad,
+			numObjects:     subSet.numObjects,
+			ppResults:      subSet.ppResults,
+			recoveries:     subSet.recoveries,
+			failed:         subSet.failed,
+			sdpfFail:       subSet.sdpfFail,
+		}
+	}
+
+	return snapshot
+}
+
+// RestoreSnapshot moves the ruleset to the state saved in the snapshot and notifies rewind listeners.
+// Snapshot stays intact, so it can be restored multiple times.
+func (set *OsuRuleSet) RestoreSnapshot(snapshot *Snapshot) {
+	// Queue only shrinks from the front, so objects missing from the current one have been processed after the snapshot was taken
+	if moved := len(snapshot.queue) - len(set.queue); moved > 0 {
+		for _, o := range snapshot.queue[:moved] {
+			o.resetState()
+		}
+	}
+
+	set.queue = append(set.queue[:0:0], snapshot.queue...)
+	set.processed = append(set.processed[:0:0], snapshot.processed...)
+
+	for _, o := range set.processed {
+		o.restoreState(snapshot.states[o])
+	}
+
+	for cursor, subSet := range set.cursors {
+		saved := snapshot.players[cursor]
+
+		*subSet.player = saved.player
+		*subSet.score = saved.score
+		*subSet.hp = saved.hp
+		subSet.ppResults = saved.ppResults
+
+		subSet.scoreProcessor = saved.scoreProcessor.clone()
+		subSet.rawScore = saved.rawScore
+		subSet.currentKatu = saved.currentKatu
+		subSet.currentBad = saved.currentBad
+		subSet.numObjects = saved.numObjects
+		subSet.recoveries = saved.recoveries
+		subSet.failed = saved.failed
+		subSet.sdpfFail = saved.sdpfFail
+	}
+
+	set.ended = snapshot.ended
+
+	if len(set.history) > snapshot.numEvents {
+		set.history = set.history[:snapshot.numEvents]
+	}
+
+	for _, listener := range set.rewindListeners {
+		listener()
+	}
+}
+
+// AddRewindListener registers a function called after ruleset was restored from a snapshot
+func (set *OsuRuleSet) AddRewindListener(listener rewindListener) {
+	set.rewindListeners = append(set.rewindListeners, listener)
+}
+
+// ReplayHistory sends all judgements made up to this point to the given listener, works only if ruleset is rewindable
+func (set *OsuRuleSet) ReplayHistory(listener hitListener) {
+	for _, e := range set.history {
+		listener(e.cursor, e.time, e.number, e.position, e.result, e.comboResult, e.ppResults, e.score)
+	}
+}
+
+// GetNumJudgements returns the number of judgements in history made for the given cursor
+func (set *OsuRuleSet) GetNumJudgements(cursor *graphics.Cursor) (count int) {
+	for _, e := range set.history {
+		if e.cursor == cursor {
+			count++
+		}
+	}
+
+	return
+}
+
+func (set *OsuRuleSet) recordEvent(cursor *graphics.Cursor, time int64, number int64, position vector.Vector2d, result HitResult, comboResult ComboResult, ppResults api.PPv2Results, score int64) {
+	if !set.rewindable {
+		return
+	}
+
+	set.history = append(set.history, hitEvent{cursor, time, number, position, result, comboResult, ppResults, score})
+}
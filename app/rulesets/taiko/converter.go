@@ -0,0 +1,126 @@
+package taiko
+
+import (
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"math"
+)
+
+const (
+	legacyVelocityMultiplier = 1.4
+	swellHitMultiplier       = 1.65
+	baseScoringDistance      = 100.0
+)
+
+const (
+	sampleWhistle = 2
+	sampleFinish  = 4
+	sampleClap    = 8
+)
+
+// ConvertBeatMap creates osu!taiko objects from beatmap's hit objects.
+// osu!standard maps are converted using stable's rules, native osu!taiko maps differ only in sliders always becoming drumrolls.
+func ConvertBeatMap(beatMap *beatmap.BeatMap, diff *difficulty.Difficulty) []*Object {
+	native := beatMap.Mode == beatmap.ModeTaiko
+
+	taikoObjects := make([]*Object, 0, len(beatMap.HitObjects))
+
+	for _, o := range beatMap.HitObjects {
+		switch obj := o.(type) {
+		case *objects.Circle:
+			taikoObjects = append(taikoObjects, newHit(obj.StartTime, obj.GetSample()))
+		case *objects.Slider:
+			taikoObjects = append(taikoObjects, convertSlider(beatMap, obj, native)...)
+		case *objects.Spinner:
+			taikoObjects = append(taikoObjects, newSwell(obj.StartTime, obj.EndTime, diff))
+		}
+	}
+
+	return taikoObjects
+}
+
+func newHit(time float64, sample int) *Object {
+	return &Object{
+		Type:      Hit,
+		StartTime: time,
+		EndTime:   time,
+		Kat:       sample&(sampleWhistle|sampleClap) > 0,
+		Strong:    sample&sampleFinish > 0,
+	}
+}
+
+func newSwell(startTime, endTime float64, diff *difficulty.Difficulty) *Object {
+	hitMultiplier := difficulty.DifficultyRate(getOD(diff), 3, 5, 7.5) * swellHitMultiplier
+
+	return &Object{
+		Type:         Swell,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		RequiredHits: int(math.Max(1, (endTime-startTime)/1000*hitMultiplier)),
+	}
+}
+
+func convertSlider(beatMap *beatmap.BeatMap, slider *objects.Slider, native bool) []*Object {
+	timings := beatMap.Timings
+
+	spans := float64(slider.RepeatCount)
+
+	// The true distance, accounting for repeats, it's also the distance of the drumroll
+	distance := slider.GetPixelLength() * spans * legacyVelocityMultiplier
+
+	point := timings.GetPointAt(slider.StartTime)
+
+	beatLength := point.GetBeatLength()
+
+	taikoVelocity := baseScoringDistance * timings.SliderMult * legacyVelocityMultiplier
+	taikoDuration := float64(int(distance / taikoVelocity * beatLength))
+
+	if !native {
+		osuVelocity := taikoVelocity * (1000 / beatLength)
+
+		// Stable uses SV-adjusted beat length to decide on conversion only on old beatmap versions
+		if beatMap.Version >= 8 {
+			beatLength = point.GetBaseBeatLength()
+		}
+
+		// If slider is converted to notes, they are placed at ticks within its duration
+		tickSpacing := math.Min(beatLength/timings.TickRate, taikoDuration/spans)
+
+		if tickSpacing > 0 && distance/osuVelocity*1000 < 2*beatLength {
+			samples := slider.GetEdgeSamples()
+
+			hits := make([]*Object, 0)
+
+			for i, t := 0, slider.StartTime; t <= slider.StartTime+taikoDuration+tickSpacing/8; t += tickSpacing {
+				hits = append(hits, newHit(t, samples[i]))
+
+				i = (i + 1) % len(samples)
+			}
+
+			return hits
+		}
+	}
+
+	drumRoll := &Object{
+		Type:      DrumRoll,
+		StartTime: slider.StartTime,
+		EndTime:   slider.StartTime + taikoDuration,
+		Strong:    slider.GetBaseSample()&sampleFinish > 0,
+	}
+
+	tickRate := 4.0
+	if timings.TickRate == 3 {
+		tickRate = 3
+	}
+
+	drumRoll.tickSpacing = point.GetBaseBeatLength() / tickRate
+
+	if drumRoll.tickSpacing > 0 {
+		for t := drumRoll.StartTime; t < drumRoll.EndTime+drumRoll.tickSpacing/2; t += drumRoll.tickSpacing {
+			drumRoll.Ticks = append(drumRoll.Ticks, &Tick{Time: t})
+		}
+	}
+
+	return []*Object{drumRoll}
+}
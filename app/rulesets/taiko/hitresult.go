@@ -0,0 +1,44 @@
+package taiko
+
+type HitResult uint8
+
+const (
+	Miss = HitResult(iota)
+	Ok   // Called 100 or good in stable
+	Great
+)
+
+// ScoreValue returns the base score of a judgement in stable's ScoreV1, hitting big notes with both keys doubles it
+func (r HitResult) ScoreValue() int64 {
+	switch r {
+	case Ok:
+		return 150
+	case Great:
+		return 300
+	}
+
+	return 0
+}
+
+// healthIncrease returns the base change of health, it's later scaled by the amount of notes and map's HP
+func (r HitResult) healthIncrease() float64 {
+	switch r {
+	case Ok:
+		return 1.1
+	case Great:
+		return 3.0
+	}
+
+	return -1.0
+}
+
+func (r HitResult) String() string {
+	switch r {
+	case Ok:
+		return "100"
+	case Great:
+		return "300"
+	}
+
+	return "Miss"
+}
@@ -0,0 +1,58 @@
+package taiko
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"math"
+)
+
+// strongHitWindow is the maximum time between both presses of a big note to count it as a strong hit
+const strongHitWindow = 30.0
+
+// HitWindows holds osu!taiko judgement windows in beatmap time, every window is the maximum absolute offset for its judgement
+type HitWindows struct {
+	Great float64
+	Ok    float64
+	Miss  float64
+}
+
+// NewHitWindows calculates stable's hit windows, rate changing mods don't change windows in real time, so they are scaled by playback speed
+func NewHitWindows(diff *difficulty.Difficulty) HitWindows {
+	od := getOD(diff)
+
+	window := func(min, mid, max float64) float64 {
+		return math.Floor(difficulty.DifficultyRate(od, min, mid, max)) * diff.Speed
+	}
+
+	return HitWindows{
+		Great: window(50, 35, 20),
+		Ok:    window(120, 80, 50),
+		Miss:  window(135, 95, 70),
+	}
+}
+
+// ResultFor returns judgement for the given offset, Miss is returned for early presses outside of 100's window
+func (w HitWindows) ResultFor(offset float64) HitResult {
+	offset = math.Abs(offset)
+
+	switch {
+	case offset <= w.Great:
+		return Great
+	case offset <= w.Ok:
+		return Ok
+	}
+
+	return Miss
+}
+
+// getOD returns OD with HR and EZ applied
+func getOD(diff *difficulty.Difficulty) float64 {
+	od := diff.GetOD()
+
+	if diff.CheckModActive(difficulty.HardRock) {
+		od = math.Min(od*1.4, 10)
+	} else if diff.CheckModActive(difficulty.Easy) {
+		od /= 2
+	}
+
+	return od
+}
@@ -0,0 +1,135 @@
+package taiko
+
+import (
+	"github.com/wieku/rplpa"
+	"math"
+	"sort"
+)
+
+// autoReleaseTime is the maximum time autoplay holds a key
+const autoReleaseTime = 20.0
+
+// KeyFrame is the state of taiko keys at the given time
+type KeyFrame struct {
+	Time float64
+	Keys uint32
+}
+
+// ConvertKeys converts keys of an .osr frame to taiko keys
+func ConvertKeys(pressed *rplpa.KeyPressed) uint32 {
+	keys := uint32(0)
+
+	if pressed == nil {
+		return keys
+	}
+
+	if pressed.LeftClick {
+		keys |= LeftCentre
+	}
+
+	if pressed.RightClick {
+		keys |= LeftRim
+	}
+
+	if pressed.Key1 {
+		keys |= RightCentre
+	}
+
+	if pressed.Key2 {
+		keys |= RightRim
+	}
+
+	return keys
+}
+
+// GenerateAutoplay creates key frames that hit every note perfectly, alternating hands like stable's autoplay.
+// Big notes are hit with both keys, drumrolls on every tick and swells with evenly spaced alternating hits.
+func GenerateAutoplay(taikoObjects []*Object, windows HitWindows) []KeyFrame {
+	type press struct {
+		time float64
+		keys uint32
+		note bool
+	}
+
+	presses := make([]press, 0, len(taikoObjects))
+
+	leftHand := true
+
+	hand := func(centre bool) uint32 {
+		leftHand = !leftHand
+
+		switch {
+		case centre && !leftHand:
+			return LeftCentre
+		case centre:
+			return RightCentre
+		case !leftHand:
+			return LeftRim
+		}
+
+		return RightRim
+	}
+
+	for _, o := range taikoObjects {
+		switch o.Type {
+		case Hit:
+			keys := hand(!o.Kat)
+			if o.Strong {
+				keys = centreActions
+				if o.Kat {
+					keys = rimActions
+				}
+			}
+
+			presses = append(presses, press{o.StartTime, keys, true})
+		case DrumRoll:
+			for _, tick := range o.Ticks {
+				presses = append(presses, press{tick.Time, hand(true), false})
+			}
+		case Swell:
+			spacing := (o.EndTime - o.StartTime) / float64(o.RequiredHits)
+
+			for i := 0; i < o.RequiredHits; i++ {
+				presses = append(presses, press{o.StartTime + float64(i)*spacing, hand(i%2 == 0), false})
+			}
+		}
+	}
+
+	sort.SliceStable(presses, func(i, j int) bool {
+		return presses[i].time < presses[j].time
+	})
+
+	// Drumroll and swell hits too close to the next note would be judged as its early hit
+	filtered := make([]press, 0, len(presses))
+
+	nextNote := math.Inf(1)
+
+	for i := len(presses) - 1; i >= 0; i-- {
+		if p := presses[i]; p.note {
+			nextNote = p.time
+		} else if nextNote-p.time <= windows.Ok {
+			continue
+		}
+
+		filtered = append(filtered, presses[i])
+	}
+
+	presses = presses[:0]
+
+	for i := len(filtered) - 1; i >= 0; i-- {
+		presses = append(presses, filtered[i])
+	}
+
+	keyFrames := make([]KeyFrame, 0, len(presses)*2)
+
+	for i, p := range presses {
+		releaseTime := p.time + autoReleaseTime
+		if i < len(presses)-1 {
+			releaseTime = math.Min(releaseTime, (p.time+presses[i+1].time)/2)
+		}
+
+		keyFrames = append(keyFrames, KeyFrame{p.time, p.keys}, KeyFrame{releaseTime, 0})
+	}
+
+	return keyFrames
+}
@@ -0,0 +1,51 @@
+package taiko
+
+type Type uint8
+
+const (
+	Hit = Type(iota)
+	DrumRoll
+	Swell
+)
+
+// Tick is a single hittable point of a drumroll
+type Tick struct {
+	Time float64
+	Hit  bool
+}
+
+// Object is a single osu!taiko hit object: a don or kat note, a drumroll or a swell
+type Object struct {
+	Type      Type
+	StartTime float64
+	EndTime   float64
+
+	// Kat is true for rim notes, false for centre (don) notes, used only by Hit
+	Kat bool
+	// Strong is true for big notes and big drumrolls
+	Strong bool
+
+	// Ticks are drumroll's ticks
+	Ticks []*Tick
+	// RequiredHits is the number of alternating don and kat presses needed to clear a swell
+	RequiredHits int
+	// Hits counts swell presses or drumroll ticks that were hit
+	Hits int
+
+	// Judged is true if object received its final judgement
+	Judged bool
+	Result HitResult
+	// StrongHit is true if big note was hit with both keys
+	StrongHit bool
+	// JudgeTime is the time of judgement
+	JudgeTime float64
+
+	tickSpacing  float64
+	strongAction uint32
+	lastSwellKat bool
+}
+
+// IsActive returns true if drumroll or swell can be hit at the given time
+func (object *Object) IsActive(time float64) bool {
+	return object.Type != Hit && !object.Judged && time >= object.StartTime && time <= object.EndTime
+}
@@ -0,0 +1,331 @@
+package taiko
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/audio"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"log"
+)
+
+// Key state bits, their order matches how stable stores osu!taiko keys in replays
+const (
+	LeftCentre = uint32(1 << iota)
+	LeftRim
+	RightCentre
+	RightRim
+)
+
+const (
+	centreActions = LeftCentre | RightCentre
+	rimActions    = LeftRim | RightRim
+)
+
+type hitListener func(time float64, object *Object, result HitResult)
+
+type TaikoRuleset struct {
+	beatMap *beatmap.BeatMap
+	diff    *difficulty.Difficulty
+
+	windows HitWindows
+
+	objects []*Object
+
+	// notes and long objects (drumrolls and swells) are judged separately
+	hits       []*Object
+	long       []*Object
+	hitIndex   int
+	longIndex  int
+	lastStrong *Object
+
+	keyState uint32
+
+	score *scoreProcessor
+
+	judged int
+	ended  bool
+	muted  bool
+
+	hitListener hitListener
+}
+
+func NewTaikoRuleset(beatMap *beatmap.BeatMap, mods difficulty.Modifier) *TaikoRuleset {
+	diff := difficulty.NewDifficulty(beatMap.Diff.GetHP(), beatMap.Diff.GetCS(), beatMap.Diff.GetOD(), beatMap.Diff.GetAR())
+	diff.SetMods(mods)
+	diff.SetCustomSpeed(beatMap.Diff.CustomSpeed)
+
+	ruleset := &TaikoRuleset{
+		beatMap: beatMap,
+		diff:    diff,
+		windows: NewHitWindows(diff),
+		objects: ConvertBeatMap(beatMap, diff),
+	}
+
+	for _, o := range ruleset.objects {
+		if o.Type == Hit {
+			ruleset.hits = append(ruleset.hits, o)
+		} else {
+			ruleset.long = append(ruleset.long, o)
+		}
+	}
+
+	ruleset.score = newScoreProcessor(len(ruleset.hits), diff)
+
+	mode := "native"
+	if beatMap.Mode != beatmap.ModeTaiko {
+		mode = "converted"
+	}
+
+	log.Println(fmt.Sprintf("osu!taiko ruleset (%s): %d notes, %d drumrolls and swells, OD %.1f, windows: 300 %.0fms, 100 %.0fms, miss %.0fms", mode, len(ruleset.hits), len(ruleset.long), getOD(diff), ruleset.windows.Great, ruleset.windows.Ok, ruleset.windows.Miss))
+
+	return ruleset
+}
+
+// UpdateKeys processes key state changes at the given time
+func (ruleset *TaikoRuleset) UpdateKeys(time float64, keys uint32) {
+	ruleset.Update(time)
+
+	pressed := keys &^ ruleset.keyState
+
+	ruleset.keyState = keys
+
+	for action := LeftCentre; action <= RightRim; action <<= 1 {
+		if pressed&action > 0 {
+			ruleset.press(time, action)
+		}
+	}
+}
+
+func (ruleset *TaikoRuleset) press(time float64, action uint32) {
+	kat := action&rimActions > 0
+
+	ruleset.playSound(time, kat)
+
+	// Second press of a big note hit earlier with a key of the same colour
+	if note := ruleset.lastStrong; note != nil && !note.StrongHit && note.strongAction != action && note.Kat == kat {
+		if time-note.JudgeTime <= strongHitWindow*ruleset.diff.Speed {
+			note.StrongHit = true
+
+			ruleset.score.addStrongBonus(note.Result)
+
+			return
+		}
+	}
+
+	note := ruleset.nextHit()
+	if note == nil || time-note.StartTime < -ruleset.windows.Miss {
+		ruleset.hitLong(time, kat)
+		return
+	}
+
+	offset := time - note.StartTime
+
+	// Presses that would cause an early miss go to drumroll or swell if there's one in progress
+	if offset < -ruleset.windows.Ok && ruleset.hitLong(time, kat) {
+		return
+	}
+
+	result := ruleset.windows.ResultFor(offset)
+	if note.Kat != kat {
+		result = Miss
+	}
+
+	ruleset.judge(time, note, result)
+
+	if note.Strong && result != Miss {
+		note.strongAction = action
+		ruleset.lastStrong = note
+	}
+}
+
+// hitLong hits drumroll or swell in progress, returns false if there's none
+func (ruleset *TaikoRuleset) hitLong(time float64, kat bool) bool {
+	for i := ruleset.longIndex; i < len(ruleset.long) && ruleset.long[i].StartTime <= time; i++ {
+		if object := ruleset.long[i]; object.IsActive(time) {
+			if object.Type == DrumRoll {
+				ruleset.hitDrumRoll(object, time)
+			} else {
+				ruleset.hitSwell(object, time, kat)
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (ruleset *TaikoRuleset) hitDrumRoll(drumRoll *Object, time float64) {
+	for _, tick := range drumRoll.Ticks {
+		if tick.Hit || tick.Time < time-drumRoll.tickSpacing/2 {
+			continue
+		}
+
+		if tick.Time-time <= drumRoll.tickSpacing/2 {
+			tick.Hit = true
+			drumRoll.Hits++
+
+			ruleset.score.addTick(drumRoll.Strong)
+		}
+
+		return
+	}
+}
+
+func (ruleset *TaikoRuleset) hitSwell(swell *Object, time float64, kat bool) {
+	// Swells have to be hit with alternating colours
+	if swell.Hits > 0 && swell.lastSwellKat == kat {
+		return
+	}
+
+	swell.lastSwellKat = kat
+	swell.Hits++
+
+	if swell.Hits < swell.RequiredHits {
+		ruleset.score.addSwellHit(false)
+		return
+	}
+
+	ruleset.score.addSwellHit(true)
+
+	ruleset.finishLong(time, swell, Great)
+}
+
+// Update judges notes that weren't hit in time and finishes drumrolls and swells
+func (ruleset *TaikoRuleset) Update(time float64) {
+	for note := ruleset.nextHit(); note != nil && time > note.StartTime+ruleset.windows.Ok; note = ruleset.nextHit() {
+		ruleset.judge(note.StartTime+ruleset.windows.Ok, note, Miss)
+	}
+
+	for i := ruleset.longIndex; i < len(ruleset.long) && ruleset.long[i].StartTime <= time; i++ {
+		object := ruleset.long[i]
+
+		if object.Judged || time <= object.EndTime {
+			continue
+		}
+
+		result := Miss
+
+		if object.Type == DrumRoll {
+			if object.Hits > 0 {
+				result = Great
+			}
+		} else if object.Hits > object.RequiredHits/2 {
+			result = Ok
+		}
+
+		ruleset.finishLong(object.EndTime, object, result)
+	}
+
+	for ruleset.longIndex < len(ruleset.long) && ruleset.long[ruleset.longIndex].Judged {
+		ruleset.longIndex++
+	}
+}
+
+func (ruleset *TaikoRuleset) judge(time float64, note *Object, result HitResult) {
+	note.Judged = true
+	note.Result = result
+	note.JudgeTime = time
+
+	ruleset.hitIndex++
+
+	ruleset.score.addResult(result)
+
+	if ruleset.hitListener != nil {
+		ruleset.hitListener(time, note, result)
+	}
+
+	ruleset.objectJudged()
+}
+
+// finishLong ends a drumroll or a swell, they only add score, so result is informational
+func (ruleset *TaikoRuleset) finishLong(time float64, object *Object, result HitResult) {
+	object.Judged = true
+	object.Result = result
+	object.JudgeTime = time
+
+	if ruleset.hitListener != nil && object.Type == Swell {
+		ruleset.hitListener(time, object, result)
+	}
+
+	ruleset.objectJudged()
+}
+
+func (ruleset *TaikoRuleset) objectJudged() {
+	ruleset.judged++
+
+	if ruleset.judged == len(ruleset.objects) && !ruleset.ended {
+		ruleset.ended = true
+
+		sc := ruleset.score.score
+
+		status := "passed"
+		if !ruleset.IsPassing() {
+			status = "failed"
+		}
+
+		log.Println(fmt.Sprintf("osu!taiko play finished: score %d, accuracy %.2f%%, max combo %d, 300: %d, 100: %d, miss: %d, health %.0f%% (%s)", sc.Score, sc.Accuracy, sc.MaxCombo, sc.CountGreat, sc.CountOk, sc.CountMiss, sc.Health*100, status))
+	}
+}
+
+func (ruleset *TaikoRuleset) nextHit() *Object {
+	if ruleset.hitIndex >= len(ruleset.hits) {
+		return nil
+	}
+
+	return ruleset.hits[ruleset.hitIndex]
+}
+
+func (ruleset *TaikoRuleset) playSound(time float64, kat bool) {
+	if ruleset.muted {
+		return
+	}
+
+	point := ruleset.beatMap.Timings.GetPointAt(time)
+
+	sample := 0
+	if kat {
+		sample = sampleClap
+	}
+
+	audio.PlaySample(point.SampleSet, 0, sample, point.SampleIndex, point.SampleVolume, -1, 256)
+}
+
+// Mute disables hitsounds, used when several players are judged at once and only one of them is shown
+func (ruleset *TaikoRuleset) Mute() {
+	ruleset.muted = true
+}
+
+func (ruleset *TaikoRuleset) SetListener(listener hitListener) {
+	ruleset.hitListener = listener
+}
+
+func (ruleset *TaikoRuleset) GetObjects() []*Object {
+	return ruleset.objects
+}
+
+func (ruleset *TaikoRuleset) GetHitWindows() HitWindows {
+	return ruleset.windows
+}
+
+// GetKeyState returns currently held keys
+func (ruleset *TaikoRuleset) GetKeyState() uint32 {
+	return ruleset.keyState
+}
+
+func (ruleset *TaikoRuleset) GetScore() Score {
+	return *ruleset.score.score
+}
+
+// IsPassing returns true if health is high enough to pass the map
+func (ruleset *TaikoRuleset) IsPassing() bool {
+	return ruleset.score.score.Health >= healthToPass
+}
+
+func (ruleset *TaikoRuleset) GetBeatMap() *beatmap.BeatMap {
+	return ruleset.beatMap
+}
+
+func (ruleset *TaikoRuleset) IsEnded() bool {
+	return ruleset.ended
+}
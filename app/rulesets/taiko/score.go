@@ -0,0 +1,145 @@
+package taiko
+
+import (
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"math"
+)
+
+const (
+	tickScore      = 300
+	swellHitScore  = 300
+	swellBonus     = 5000
+	healthToPass   = 0.5
+	comboBonusStep = 10
+)
+
+type Score struct {
+	Score      int64
+	Accuracy   float64
+	Grade      osu.Grade
+	Combo      uint
+	MaxCombo   uint
+	CountGreat uint
+	CountOk    uint
+	CountMiss  uint
+	// Health starts empty in osu!taiko, play is passed if it's at least half full at the end
+	Health float64
+}
+
+// scoreProcessor approximates stable's osu!taiko ScoreV1 where every 10 combo adds 10% of judgement's value up to 100 combo,
+// drumroll ticks and swell hits only add score, and health behaves like in stable: it starts empty and has to be filled by hitting notes
+type scoreProcessor struct {
+	score *Score
+
+	modMultiplier float64
+	hidden        bool
+
+	hpMultiplier     float64
+	hpMissMultiplier float64
+}
+
+func newScoreProcessor(hits int, diff *difficulty.Difficulty) *scoreProcessor {
+	hp := diff.HPMod
+
+	return &scoreProcessor{
+		score:            &Score{Accuracy: 100, Grade: osu.NONE},
+		modMultiplier:    diff.GetScoreMultiplier(),
+		hidden:           diff.CheckModActive(difficulty.Hidden | difficulty.Flashlight),
+		hpMultiplier:     1 / (3 * float64(mutils.Max(hits, 1)) * difficulty.DifficultyRate(hp, 0.5, 0.75, 0.98)),
+		hpMissMultiplier: difficulty.DifficultyRate(hp, 0.0018, 0.0075, 0.0120),
+	}
+}
+
+func (s *scoreProcessor) addResult(result HitResult) {
+	switch result {
+	case Great:
+		s.score.CountGreat++
+	case Ok:
+		s.score.CountOk++
+	default:
+		s.score.CountMiss++
+	}
+
+	if result == Miss {
+		s.score.Combo = 0
+		s.score.Health += result.healthIncrease() * s.hpMissMultiplier
+	} else {
+		value := result.ScoreValue()
+
+		comboBonus := float64(mutils.Min(s.score.Combo/comboBonusStep, 10)) * float64(value) / 10
+
+		s.addScore(float64(value) + comboBonus)
+
+		s.score.Combo++
+		s.score.MaxCombo = mutils.Max(s.score.MaxCombo, s.score.Combo)
+		s.score.Health += result.healthIncrease() * s.hpMultiplier
+	}
+
+	s.score.Health = mutils.ClampF(s.score.Health, 0, 1)
+
+	s.updateAccuracy()
+}
+
+// addStrongBonus doubles base value of a big note hit with both keys
+func (s *scoreProcessor) addStrongBonus(result HitResult) {
+	s.addScore(float64(result.ScoreValue()))
+}
+
+func (s *scoreProcessor) addTick(strong bool) {
+	if strong {
+		s.addScore(tickScore * 2)
+	} else {
+		s.addScore(tickScore)
+	}
+}
+
+func (s *scoreProcessor) addSwellHit(completed bool) {
+	if completed {
+		s.addScore(swellBonus)
+	} else {
+		s.addScore(swellHitScore)
+	}
+}
+
+func (s *scoreProcessor) addScore(value float64) {
+	s.score.Score += int64(math.Round(value * s.modMultiplier))
+}
+
+func (s *scoreProcessor) updateAccuracy() {
+	sc := s.score
+
+	hits := sc.CountGreat + sc.CountOk + sc.CountMiss
+	if hits == 0 {
+		return
+	}
+
+	sc.Accuracy = 100 * (float64(sc.CountGreat) + float64(sc.CountOk)*0.5) / float64(hits)
+
+	// Stable uses osu!standard's rules, but without 50s
+	ratio := float64(sc.CountGreat) / float64(hits)
+
+	switch {
+	case ratio == 1:
+		sc.Grade = osu.SS
+	case ratio > 0.9 && sc.CountMiss == 0:
+		sc.Grade = osu.S
+	case (ratio > 0.8 && sc.CountMiss == 0) || ratio > 0.9:
+		sc.Grade = osu.A
+	case (ratio > 0.7 && sc.CountMiss == 0) || ratio > 0.8:
+		sc.Grade = osu.B
+	case ratio > 0.6:
+		sc.Grade = osu.C
+	default:
+		sc.Grade = osu.D
+	}
+
+	if s.hidden {
+		if sc.Grade == osu.SS {
+			sc.Grade = osu.SSH
+		} else if sc.Grade == osu.S {
+			sc.Grade = osu.SH
+		}
+	}
+}
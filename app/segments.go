@@ -0,0 +1,162 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/wieku/danser-go/app/ffmpeg"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/states"
+	"github.com/wieku/danser-go/framework/goroutines"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// Worker processes get their segment index and the seed of osu!lazer mods through environment variables
+const (
+	segmentEnv  = "DANSER_SEGMENT"
+	modsSeedEnv = "DANSER_MODS_SEED"
+)
+
+// segmentWarmUp is how many milliseconds of frames before segment's start are drawn to fill cursor trails and motion blur history
+const segmentWarmUp = 1000.0
+
+// segment is the index of the part of recording rendered by this process, -1 if it's not a worker process
+var segment = -1
+var segmentCount = 1
+
+func loadSegmentEnv() {
+	if s := os.Getenv(segmentEnv); s != "" {
+		var err error
+
+		segment, err = strconv.Atoi(s)
+		if err != nil {
+			panic(fmt.Sprintf("Invalid %s: %s", segmentEnv, s))
+		}
+	}
+}
+
+// getLogName returns the name of log file, worker processes can't overwrite main process' log
+func getLogName() string {
+	if s := os.Getenv(segmentEnv); s != "" {
+		return "danser-segment" + s + ".log"
+	}
+
+	return "danser.log"
+}
+
+// getModsSeed returns the seed of osu!lazer mods with randomness, worker processes use the one from main process
+func getModsSeed() int64 {
+	if s := os.Getenv(modsSeedEnv); s != "" {
+		seed, err := strconv.ParseInt(s, 10, 64)
+		if err == nil {
+			return seed
+		}
+	}
+
+	return time.Now().UnixNano()
+}
+
+// getSegmentBounds returns the first frame of the segment and the first frame after it, counted from the start of the recording.
+// Every worker process computes the same bounds, so segments don't overlap and there are no gaps between them.
+func getSegmentBounds(p *states.Player, fpsDelta float64) (first, end int64) {
+	total := p.RunningTime / settings.SPEED / fpsDelta
+
+	multiplier := int64(1)
+	if settings.Recording.MotionBlur.Enabled {
+		multiplier = int64(settings.Recording.MotionBlur.OversampleMultiplier)
+	}
+
+	bound := func(index int) int64 {
+		b := int64(total * float64(index) / float64(segmentCount))
+
+		return b - b%multiplier // Motion blur can't be split between segments
+	}
+
+	first, end = bound(segment), math.MaxInt64
+
+	if segment < segmentCount-1 {
+		end = bound(segment + 1)
+	}
+
+	if first >= end {
+		panic("Recording is too short to be split into that many segments")
+	}
+
+	return
+}
+
+// runSegmented renders the recording in parallel worker processes and joins their videos, never returns
+func runSegmented(modsSeed int64) {
+	output = ffmpeg.PrepareSegments(output)
+
+	executable, err := os.Executable()
+	if err != nil {
+		panic(err)
+	}
+
+	args := append(os.Args[1:], "-out="+output, "-noupdatecheck", "-nodbcheck")
+
+	log.Println(fmt.Sprintf("Rendering %d segments in parallel...", segmentCount))
+
+	commands := make([]*exec.Cmd, segmentCount)
+	errs := make(chan error, segmentCount)
+
+	for i := range commands {
+		cmd := exec.Command(executable, args...)
+		cmd.Env = append(os.Environ(), segmentEnv+"="+strconv.Itoa(i), modsSeedEnv+"="+strconv.FormatInt(modsSeed, 10))
+
+		rFile, oFile, err := os.Pipe()
+		if err != nil {
+			panic(err)
+		}
+
+		cmd.Stdout = oFile
+		cmd.Stderr = oFile
+
+		if err = cmd.Start(); err != nil {
+			panic(fmt.Sprintf("Failed to start segment %d: %s", i+1, err))
+		}
+
+		_ = oFile.Close()
+
+		prefix := fmt.Sprintf("[%d/%d] ", i+1, segmentCount)
+
+		goroutines.Run(func() {
+			sc := bufio.NewScanner(rFile)
+
+			for sc.Scan() {
+				_, _ = fmt.Fprintln(log.Writer(), prefix+sc.Text())
+			}
+		})
+
+		goroutines.Run(func() {
+			err := cmd.Wait()
+			if err != nil {
+				err = fmt.Errorf("%s%w", prefix, err)
+			}
+
+			errs <- err
+		})
+
+		commands[i] = cmd
+	}
+
+	for range commands {
+		if err = <-errs; err != nil {
+			for _, cmd := range commands {
+				_ = cmd.Process.Kill()
+			}
+
+			panic(fmt.Sprintf("Segment failed to render, check its log for details: %s", err))
+		}
+	}
+
+	ffmpeg.CombineSegments(segmentCount, getLayerNames())
+
+	closeHandler(nil, nil)
+	os.Exit(0)
+}
@@ -142,3 +142,13 @@ func (d *defaultsFactory) InitPippi() *pippi {
 		SpinnerRadius:    100,
 	}
 }
+
+type script struct {
+	File string `long:"true" label:"Script file" tooltip:"Starlark script defining move(ctx, time) function returning cursor position. Relative paths are resolved against danser's settings directory"`
+}
+
+func (d *defaultsFactory) InitScript() *script {
+	return &script{
+		File: "mover.star",
+	}
+}
@@ -14,6 +14,7 @@ func initCursorDance() *cursorDance {
 		Battle:             false,
 		DoSpinnersTogether: true,
 		TAGSliderDance:     false,
+		TAGAssignment:      "roundrobin",
 		MoverSettings: &moverSettings{
 			Bezier: []*bezier{
 				DefaultsFactory.InitBezier(),
@@ -39,14 +40,18 @@ func initCursorDance() *cursorDance {
 			Pippi: []*pippi{
 				DefaultsFactory.InitPippi(),
 			},
+			Script: []*script{
+				DefaultsFactory.InitScript(),
+			},
 		},
 	}
 }
 
 type mover struct {
-	Mover             string `combo:"spline,bezier,circular,linear,axis,aggressive,flower,momentum,exgon,pippi"`
+	Mover             string `combo:"spline,bezier,circular,linear,axis,aggressive,flower,momentum,exgon,pippi,script"`
 	SliderDance       bool
 	RandomSliderDance bool
+	Rules             []*moverRule `new:"InitMoverRule" tooltip:"Rules are checked in order for every cursor movement, the first matching rule selects the mover. If none matches, the mover above is used"`
 }
 
 func (d *defaultsFactory) InitMover() *mover {
@@ -54,18 +59,42 @@ func (d *defaultsFactory) InitMover() *mover {
 		Mover:             "spline",
 		SliderDance:       false,
 		RandomSliderDance: false,
+		Rules:             []*moverRule{},
+	}
+}
+
+type moverRule struct {
+	Condition string  `combo:"time|Time range,kiai|Kiai time,bpm|BPM range,spacing|Object spacing,stream|Streams,slider|Sliders,circle|Circles" tooltip:"Time range: time of the next object in ms\nBPM range: BPM of the next object, including speed mods\nObject spacing: distance to the next object in osu!pixels\nStreams: next object is 1/4 beat or less away\nSliders/Circles: type of the next object"`
+	rng       string  `vector:"true" label:"Range" left:"From" right:"To" showif:"Condition=time,bpm,spacing"`
+	From      float64 `min:"0" max:"100000000"`
+	To        float64 `min:"0" max:"100000000"`
+	Mover     string  `combo:"spline,bezier,circular,linear,axis,aggressive,flower,momentum,exgon,pippi,script"`
+}
+
+func (d *defaultsFactory) InitMoverRule() *moverRule {
+	return &moverRule{
+		Condition: "stream",
+		From:      0,
+		To:        100000000,
+		Mover:     "circular",
 	}
 }
 
 type spinner struct {
-	Mover  string  `combo:"heart,triangle,square,cube,circle"`
-	Radius float64 `max:"200" format:"%.0fo!px"`
+	Mover       string  `combo:"heart,triangle,square,cube,circle,svg"`
+	Radius      float64 `max:"200" format:"%.0fo!px"`
+	SVG         string  `long:"true" label:"SVG file or path data" showif:"Mover=svg" tooltip:"Path to .svg file or SVG path data, e.g. \"M 0 0 L 10 0 L 5 8 Z\"\nRelative file paths are resolved against danser's data directory"`
+	SVGSpeed    float64 `label:"SVG trace speed" min:"500" max:"20000" format:"%.0fo!px/s" showif:"Mover=svg"`
+	SVGRotation float64 `label:"SVG rotation speed" min:"-120" max:"120" format:"%.0fRPM" showif:"Mover=svg"`
 }
 
 func (d *defaultsFactory) InitSpinner() *spinner {
 	return &spinner{
-		Mover:  "circle",
-		Radius: 100,
+		Mover:       "circle",
+		Radius:      100,
+		SVG:         "M 0 -10 L 2.9 -4 L 9.5 -3.1 L 4.8 1.5 L 5.9 8.1 L 0 5 L -5.9 8.1 L -4.8 1.5 L -9.5 -3.1 L -2.9 -4 Z",
+		SVGSpeed:    5000,
+		SVGRotation: 0,
 	}
 }
 
@@ -75,7 +104,8 @@ type cursorDance struct {
 	ComboTag           bool
 	Battle             bool
 	DoSpinnersTogether bool
-	TAGSliderDance     bool `label:"TAG slider dance"`
+	TAGSliderDance     bool   `label:"TAG slider dance"`
+	TAGAssignment      string `label:"TAG object assignment" combo:"roundrobin|Round robin,optimized|Minimize cursor travel" tooltip:"Round robin: 1st cursor clicks the 1st object, 2nd clicks 2nd...\nMinimize cursor travel: objects are distributed so cursors travel as slowly as possible. Not used with combo TAG or battle"`
 	MoverSettings      *moverSettings
 }
 
@@ -88,4 +118,5 @@ type moverSettings struct {
 	ExGon      []*exgon    `new:"InitExGon"`
 	Linear     []*linear   `new:"InitLinear"`
 	Pippi      []*pippi    `new:"InitPippi"`
+	Script     []*script   `new:"InitScript"`
 }
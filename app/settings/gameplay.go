@@ -205,6 +205,31 @@ func initGameplay() *gameplay {
 			Path:       "",
 			AboveHpBar: false,
 		},
+		JudgementInspector: &judgementInspector{
+			Show:           false,
+			ShowShakes:     true,
+			ShowHitWindows: true,
+			FadeOutTime:    2,
+			TextScale:      1,
+		},
+		Mania: &mania{
+			ScrollSpeed:       20,
+			StageOpacity:      0.8,
+			ShowKeyLights:     true,
+			ShowHitJudgements: true,
+			ShowScoreAndCombo: true,
+		},
+		Taiko: &taiko{
+			ScrollSpeed:       1,
+			ShowHitJudgements: true,
+			ShowScoreAndCombo: true,
+			ShowHealth:        true,
+		},
+		Catch: &catch{
+			ShowHitJudgements: true,
+			ShowScoreAndCombo: true,
+			ShowPlate:         true,
+		},
 		HUDFont:                 "",
 		ShowResultsScreen:       true,
 		ResultsScreenTime:       5,
@@ -213,7 +238,11 @@ func initGameplay() *gameplay {
 		ShowHitLighting:         false,
 		FlashlightDim:           1,
 		PlayUsername:            "Guest",
+		SavePlayReplays:         true,
+		PPVersion:               20241007,
 		UseLazerPP:              false,
+		Ruleset:                 "auto",
+		LazerClassicScore:       false,
 	}
 }
 
@@ -231,6 +260,10 @@ type gameplay struct {
 	Mods                    *mods
 	Boundaries              *boundaries
 	Underlay                *underlay
+	JudgementInspector      *judgementInspector
+	Mania                   *mania `label:"osu!mania"`
+	Taiko                   *taiko `label:"osu!taiko"`
+	Catch                   *catch `label:"osu!catch"`
 	HUDFont                 string `file:"Select HUD font" filter:"TrueType/OpenType Font (*.ttf, *.otf)|ttf,otf"`
 	ShowResultsScreen       bool
 	ResultsScreenTime       float64 `label:"Results screen duration" min:"1" max:"20" format:"%.1fs"`
@@ -239,7 +272,11 @@ type gameplay struct {
 	ShowHitLighting         bool
 	FlashlightDim           float64
 	PlayUsername            string
-	UseLazerPP              bool
+	SavePlayReplays         bool   `label:"Save replays of played maps" tooltip:"Replays are saved to danser's replays directory"`
+	PPVersion               int    `label:"PP calculation version" combo:"20241007|2024-10-07 (current),20211112|2021-11-12" tooltip:"Older versions allow reproducing historic renders. Changing it recalculates star ratings in the database"`
+	UseLazerPP              bool   `tooltip:"Applies changes that were available only in osu!lazer on 2022-01-23. Works only with 2021-11-12 pp calculation version"`
+	Ruleset                 string `label:"osu!standard judgement rules" combo:"auto|Auto (from replay),stable|osu!stable,lazer|osu!lazer" tooltip:"osu!lazer rules use standardised scoring, slider head accuracy and lazer's HP. Auto uses osu!lazer rules only for replays exported from osu!lazer"`
+	LazerClassicScore       bool   `label:"Show classic score with osu!lazer rules" showif:"Ruleset=auto,lazer" tooltip:"Converts standardised score the same way as osu!lazer's classic scoring display mode"`
 }
 
 type boundaries struct {
@@ -369,6 +406,35 @@ type strainGraph struct {
 	FgColor *HSV `label:"Foreground color" short:"true"`
 }
 
+type judgementInspector struct {
+	Show           bool    `tooltip:"Shows causes of misses and shakes on the playfield, useful for explaining notelocks and slider breaks"`
+	ShowShakes     bool    `label:"Show shaken clicks"`
+	ShowHitWindows bool    `tooltip:"Shows hit windows of the next object and where current time lies in them"`
+	FadeOutTime    float64 `max:"10" format:"%.1fs"`
+	TextScale      float64 `min:"0.1" max:"3" scale:"100" format:"%.0f%%"`
+}
+
+type mania struct {
+	ScrollSpeed       float64 `min:"1" max:"40" format:"%.0f" tooltip:"Same scale as in osu!lazer, notes are visible for 11485ms divided by scroll speed"`
+	StageOpacity      float64 `scale:"100.0" format:"%.0f%%"`
+	ShowKeyLights     bool
+	ShowHitJudgements bool
+	ShowScoreAndCombo bool
+}
+
+type taiko struct {
+	ScrollSpeed       float64 `min:"0.5" max:"3" format:"%.1fx" tooltip:"Multiplier of map's scroll speed"`
+	ShowHitJudgements bool
+	ShowScoreAndCombo bool
+	ShowHealth        bool
+}
+
+type catch struct {
+	ShowHitJudgements bool `tooltip:"Shows missed fruits and droplets above the catcher"`
+	ShowScoreAndCombo bool
+	ShowPlate         bool `label:"Show caught fruits on the plate"`
+}
+
 type underlay struct {
 	Path       string `file:"Select underlay image" filter:"PNG file (*.png)|png"`
 	AboveHpBar bool
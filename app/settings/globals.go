@@ -16,4 +16,7 @@ var PITCH = 1.0
 var TAG = 1
 var RECORD = false
 var REPLAY = ""
+var PLAYMODE = int64(0) // Game mode used for osu!standard maps, values are the same as in .osu files
+var EXPORTREPLAY = false
+var EXPORTCURSOR = 1
 var LOCALOFFSET = 0
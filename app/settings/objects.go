@@ -17,6 +17,7 @@ func initObjects() *objects {
 			DrawScorePoints:        true,
 			SliderMerge:            false,
 			SliderDistortions:      true,
+			StablePaths:            false,
 			BorderWidth:            1.0,
 			Quality: &quality{
 				CircleLevelOfDetail: 50,
@@ -114,6 +115,7 @@ type sliders struct {
 	DrawScorePoints        bool //true
 	SliderMerge            bool
 	SliderDistortions      bool    //true, osu!stable slider distortions on aspire maps
+	StablePaths            bool    `label:"Use osu!stable path generation" tooltip:"Generates slider paths the same way as osu!stable, fixes slider ends and ticks being off in replays of maps with broken sliders"`
 	BorderWidth            float64 `max:"9"`
 	Quality                *quality
 	Snaking                *snaking
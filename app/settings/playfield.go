@@ -22,6 +22,7 @@ func initPlayfield() *playfield {
 			LoadStoryboards: true,
 			LoadVideos:      false,
 			FlashToTheBeat:  false,
+			PassFailPlayer:  0,
 			Dim: &dim{
 				Intro:  0,
 				Normal: 0.95,
@@ -105,6 +106,10 @@ type background struct {
 
 	FlashToTheBeat bool
 
+	// Which player's health switches storyboard's Pass/Fail layers when multiple players are present.
+	// 0 - player with the highest score, 1 and above - player with that index
+	PassFailPlayer int `label:"Storyboard Pass/Fail player" string:"true" min:"0" max:"100" tooltip:"0 follows the player with the highest score"`
+
 	// Dim controls
 	Dim *dim
 
@@ -14,6 +14,8 @@ func initRecording() *recording {
 		FrameHeight:    1080,
 		FPS:            60,
 		EncodingFPSCap: 0,
+		OutputType:     "video",
+		Transparent:    false,
 		Encoder:        "libx264",
 		X264Settings: &x264Settings{
 			RateControl:       "crf",
@@ -60,6 +62,10 @@ func initRecording() *recording {
 			Preset:            "slow",
 			AdditionalOptions: "",
 		},
+		ProResSettings: &proresSettings{
+			Profile:           "4444",
+			AdditionalOptions: "",
+		},
 		CustomSettings: &custom{
 			CustomOptions: "",
 		},
@@ -91,6 +97,7 @@ func initRecording() *recording {
 		OutputDir:      "videos",
 		Container:      "mp4",
 		ShowFFmpegLogs: true,
+		JudgementLog:   "none",
 		MotionBlur: &motionblur{
 			Enabled:              false,
 			OversampleMultiplier: 3,
@@ -102,6 +109,19 @@ func initRecording() *recording {
 				GaussWeightsMult: 1.5,
 			},
 		},
+		ImageSequence: &imageSequence{
+			Format:            "png",
+			Alpha:             false,
+			AdditionalOptions: "",
+		},
+		Layers: &layers{
+			Enabled:    false,
+			Background: true,
+			Objects:    true,
+			Cursors:    true,
+			HUD:        true,
+			Knockout:   true,
+		},
 	}
 }
 
@@ -111,15 +131,20 @@ type recording struct {
 	FrameHeight         int                `min:"1" max:"17280"`
 	FPS                 int                `string:"true" min:"1" max:"10727"`
 	EncodingFPSCap      int                `string:"true" min:"0" max:"10727" label:"Max Encoding FPS (Speed)"`
-	Encoder             string             `combo:"libx264|Software x264 (AVC),libx265|Software x265 (HEVC),h264_nvenc|NVIDIA NVENC H.264 (AVC),hevc_nvenc|NVIDIA NVENC H.265 (HEVC),h264_qsv|Intel QuickSync H.264 (AVC),hevc_qsv|Intel QuickSync H.265 (HEVC),libvpx-vp9|VP9"`
+	OutputType          string             `combo:"video|Video file,images|Image sequence" tooltip:"Image sequence saves every frame as a separate image and mixed audio as WAV into a directory, useful for compositing in video editors. Output directory has to be empty or not exist. Video and audio encoder settings are ignored"`
+	ImageSequence       *imageSequence     `json:"imageSequence" label:"Image Sequence Settings" showif:"OutputType=images"`
+	Layers              *layers            `label:"Layer Separation"`
+	Transparent         bool               `label:"Transparent background" tooltip:"Renders over transparent background with background, storyboard and dim disabled. Needs output with alpha: yuva420p in VP9/webm, yuva444p10le in ProRes 4444/mov or image sequence with alpha channel"`
+	Encoder             string             `combo:"libx264|Software x264 (AVC),libx265|Software x265 (HEVC),h264_nvenc|NVIDIA NVENC H.264 (AVC),hevc_nvenc|NVIDIA NVENC H.265 (HEVC),h264_qsv|Intel QuickSync H.264 (AVC),hevc_qsv|Intel QuickSync H.265 (HEVC),libvpx-vp9|VP9,prores_ks|Apple ProRes"`
 	X264Settings        *x264Settings      `json:"libx264" label:"Software x264 (AVC) Settings" showif:"Encoder=libx264"`
 	X265Settings        *x265Settings      `json:"libx265" label:"Software x265 (HEVC) Settings" showif:"Encoder=libx265"`
 	H264NvencSettings   *h264NvencSettings `json:"h264_nvenc" label:"NVIDIA NVENC H.264 (AVC) Settings" showif:"Encoder=h264_nvenc"`
 	HEVCNvencSettings   *hevcNvencSettings `json:"hevc_nvenc" label:"NVIDIA NVENC H.265 (HEVC) Settings" showif:"Encoder=hevc_nvenc"`
 	H264QSVSettings     *h264QSVSettings   `json:"h264_qsv" label:"Intel QuickSync H.264 (AVC) Settings" showif:"Encoder=h264_qsv"`
 	HEVCQSVSettings     *hevcQSVSettings   `json:"hevc_qsv" label:"Intel QuickSync H.265 (HEVC) Settings" showif:"Encoder=hevc_qsv"`
+	ProResSettings      *proresSettings    `json:"prores_ks" label:"Apple ProRes Settings" showif:"Encoder=prores_ks"`
 	CustomSettings      *custom            `json:"custom" label:"Custom Encoder Settings" showif:"Encoder=!"`
-	PixelFormat         string             `combo:"yuv420p|I420,yuv444p|I444,nv12|NV12,nv21|NV21" showif:"Encoder=!h264_qsv,!hevc_qsv"`
+	PixelFormat         string             `combo:"yuv420p|I420,yuv444p|I444,nv12|NV12,nv21|NV21,yuv422p10le|I422 10-bit (ProRes),yuv444p10le|I444 10-bit (ProRes 4444),yuva420p|I420 with alpha (VP9),yuva444p10le|I444 10-bit with alpha (ProRes 4444)" showif:"Encoder=!h264_qsv,!hevc_qsv"`
 	Filters             string             `label:"FFmpeg Video Filters"`
 	AudioCodec          string             `combo:"aac|AAC,libmp3lame|MP3,libopus|OPUS,flac|FLAC"`
 	AACSettings         *aacSettings       `json:"aac" label:"AAC Settings" showif:"AudioCodec=aac"`
@@ -130,8 +155,9 @@ type recording struct {
 	//AudioOptions        string             `label:"Audio Encoder Options"`
 	AudioFilters   string `label:"FFmpeg Audio Filters"`
 	OutputDir      string `path:"Select video output directory"`
-	Container      string `combo:"mp4,mkv,webm"`
+	Container      string `combo:"mp4,mkv,webm,mov"`
 	ShowFFmpegLogs bool
+	JudgementLog   string `combo:"none|Disabled,json|JSON,csv|CSV" label:"Export judgements" tooltip:"Saves every judgement of each player next to the rendered video"`
 	MotionBlur     *motionblur
 
 	outDir *string
@@ -151,6 +177,8 @@ func (g *recording) GetEncoderOptions() EncoderOptions {
 		return g.H264QSVSettings
 	case "hevc_qsv":
 		return g.HEVCQSVSettings
+	case "prores_ks":
+		return g.ProResSettings
 	default:
 		return g.CustomSettings
 	}
@@ -171,6 +199,19 @@ func (g *recording) GetAudioOptions() EncoderOptions {
 	}
 }
 
+func (g *recording) IsImageSequence() bool {
+	return g.OutputType == "images"
+}
+
+// HasAlpha returns true if selected output keeps the alpha channel
+func (g *recording) HasAlpha() bool {
+	if g.IsImageSequence() {
+		return g.ImageSequence.Alpha
+	}
+
+	return strings.HasPrefix(strings.ToLower(g.PixelFormat), "yuva")
+}
+
 func (g *recording) GetOutputDir() string {
 	if g.outDir == nil {
 		dir := filepath.Join(env.DataDir(), g.OutputDir)
@@ -185,6 +226,15 @@ func (g *recording) GetOutputDir() string {
 	return *g.outDir
 }
 
+type layers struct {
+	Enabled    bool `label:"Render layers separately" tooltip:"Renders selected layers to separate files with transparent background in a single pass, so they can be edited independently. Needs output with alpha channel. Bloom is disabled in this mode"`
+	Background bool `label:"Background and storyboard" showif:"Enabled=true"`
+	Objects    bool `label:"Hit objects" showif:"Enabled=true"`
+	Cursors    bool `showif:"Enabled=true"`
+	HUD        bool `label:"HUD and score overlay" showif:"Enabled=true"`
+	Knockout   bool `label:"Knockout overlay" showif:"Enabled=true"`
+}
+
 type motionblur struct {
 	Enabled              bool
 	OversampleMultiplier int `string:"true" min:"1" max:"512"`
@@ -0,0 +1,56 @@
+package settings
+
+import (
+	"strings"
+)
+
+type imageSequence struct {
+	Format            string `combo:"png|PNG (8-bit),tiff|TIFF (16-bit),exr|OpenEXR (16-bit float)"`
+	Alpha             bool   `label:"Alpha channel" tooltip:"Save frames with an alpha channel"`
+	AdditionalOptions string
+}
+
+func (s *imageSequence) GenerateFFmpegArgs() (ret []string, err error) {
+	ret = append(ret, "-c:v", s.GetEncoder(), "-pix_fmt", s.GetPixelFormat())
+
+	switch s.GetEncoder() {
+	case "tiff":
+		ret = append(ret, "-compression_algo", "deflate")
+	case "exr":
+		ret = append(ret, "-format", "half", "-compression", "zip16")
+	}
+
+	ret = parseCustomOptions(ret, s.AdditionalOptions)
+
+	return ret, nil
+}
+
+// GetEncoder returns ffmpeg's image encoder, which is also the extension of saved frames
+func (s *imageSequence) GetEncoder() string {
+	return strings.ToLower(s.Format)
+}
+
+// GetPixelFormat returns pixel format frames are saved in
+func (s *imageSequence) GetPixelFormat() string {
+	switch s.GetEncoder() {
+	case "tiff":
+		return s.alphaFormat("rgb48le", "rgba64le")
+	case "exr":
+		return s.alphaFormat("gbrpf32le", "gbrapf32le")
+	}
+
+	return s.alphaFormat("rgb24", "rgba")
+}
+
+// Is16Bit returns true if frames should be read back with 16 bits per channel
+func (s *imageSequence) Is16Bit() bool {
+	return s.GetEncoder() != "png"
+}
+
+func (s *imageSequence) alphaFormat(noAlpha, alpha string) string {
+	if s.Alpha {
+		return alpha
+	}
+
+	return noAlpha
+}
@@ -0,0 +1,33 @@
+package settings
+
+import (
+	"fmt"
+	"golang.org/x/exp/slices"
+)
+
+var proresProfiles = []string{
+	"proxy",
+	"lt",
+	"standard",
+	"hq",
+	"4444",
+	"4444xq",
+}
+
+type proresSettings struct {
+	Profile           string `combo:"proxy|Proxy,lt|LT,standard|Standard,hq|HQ,4444|4444 (supports alpha),4444xq|4444 XQ (supports alpha)" tooltip:"4444 profiles need yuv444p10le or yuva444p10le pixel format, others yuv422p10le"`
+	AdditionalOptions string
+}
+
+func (s *proresSettings) GenerateFFmpegArgs() (ret []string, err error) {
+	if !slices.Contains(proresProfiles, s.Profile) {
+		return nil, fmt.Errorf("invalid profile: %s", s.Profile)
+	}
+
+	// apl0 vendor makes Apple software treat the file as its own
+	ret = append(ret, "-profile:v", s.Profile, "-vendor", "apl0")
+
+	ret = parseCustomOptions(ret, s.AdditionalOptions)
+
+	return ret, nil
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/wieku/danser-go/framework/assets"
 	"github.com/wieku/danser-go/framework/files"
 	"github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/mutils"
 	"io"
 	"os"
 	"sort"
@@ -67,6 +68,36 @@ type SkinInfo struct {
 	//combo font settings
 	ComboPrefix  string
 	ComboOverlap float64
+
+	// Mania holds [Mania] sections by their key count
+	Mania map[int]*ManiaInfo
+}
+
+// ManiaInfo holds osu!mania layout of a single key count, positions and sizes are in stable's 480px tall space
+type ManiaInfo struct {
+	Keys int
+
+	ColumnWidth     []float64
+	ColumnSpacing   []float64
+	ColumnLineWidth []float64
+
+	HitPosition   float64
+	ScorePosition float64
+	ComboPosition float64
+	JudgementLine bool
+
+	Colours             []color.Color
+	LightColours        []color.Color
+	ColumnLineColour    color.Color
+	JudgementLineColour color.Color
+	HoldColour          *color.Color // nil if hold bodies should use note colours
+
+	NoteImages     []string
+	HoldHeadImages []string
+	HoldBodyImages []string
+	HoldTailImages []string
+	KeyImages      []string
+	KeyDownImages  []string
 }
 
 func newDefaultInfo() *SkinInfo {
@@ -102,9 +133,62 @@ func newDefaultInfo() *SkinInfo {
 		ScoreOverlap:                0,
 		ComboPrefix:                 "score",
 		ComboOverlap:                0,
+		Mania:                       make(map[int]*ManiaInfo),
 	}
 }
 
+func newDefaultManiaInfo(keys int) *ManiaInfo {
+	info := &ManiaInfo{
+		Keys:                keys,
+		HitPosition:         402,
+		ScorePosition:       325,
+		ComboPosition:       111,
+		JudgementLine:       true,
+		ColumnLineColour:    color.NewL(1),
+		JudgementLineColour: color.NewL(1),
+	}
+
+	for i := 0; i < keys; i++ {
+		// Stable's default note style alternates from stage edges towards the middle, odd key counts get a special middle column
+		style := "1"
+		if keys%2 == 1 && i == keys/2 {
+			style = "S"
+		} else if mutils.Min(i, keys-1-i)%2 == 1 {
+			style = "2"
+		}
+
+		info.ColumnWidth = append(info.ColumnWidth, 30)
+		info.Colours = append(info.Colours, color.NewLA(0, 1))
+		info.LightColours = append(info.LightColours, color.NewIRGB(55, 255, 255))
+
+		info.NoteImages = append(info.NoteImages, "mania-note"+style)
+		info.HoldHeadImages = append(info.HoldHeadImages, "mania-note"+style+"H")
+		info.HoldBodyImages = append(info.HoldBodyImages, "mania-note"+style+"L")
+		info.HoldTailImages = append(info.HoldTailImages, "mania-note"+style+"T")
+		info.KeyImages = append(info.KeyImages, "mania-key"+style)
+		info.KeyDownImages = append(info.KeyDownImages, "mania-key"+style+"D")
+	}
+
+	for i := 0; i < keys-1; i++ {
+		info.ColumnSpacing = append(info.ColumnSpacing, 0)
+	}
+
+	for i := 0; i <= keys; i++ {
+		info.ColumnLineWidth = append(info.ColumnLineWidth, 2)
+	}
+
+	return info
+}
+
+// GetManiaInfo returns [Mania] section for the given key count or stable's defaults if skin doesn't have one
+func (info *SkinInfo) GetManiaInfo(keys int) *ManiaInfo {
+	if mInfo, ok := info.Mania[keys]; ok {
+		return mInfo
+	}
+
+	return newDefaultManiaInfo(keys)
+}
+
 func (info *SkinInfo) GetFrameTime(frames int) float64 {
 	if info.AnimationFramerate > 0 {
 		return 1000.0 / info.AnimationFramerate
@@ -165,6 +249,97 @@ func ParseColor(text, errType string) color.Color {
 	return clr
 }
 
+// parseFloatList parses comma separated values into values, missing values are left untouched
+func parseFloatList(text, errType string, values []float64) {
+	for i, v := range strings.Split(text, ",") {
+		if i >= len(values) {
+			break
+		}
+
+		values[i] = ParseFloat(strings.TrimSpace(v), errType)
+	}
+}
+
+// parseManiaLine parses a single line of [Mania] section, every section starts with Keys so lines before it are ignored
+func parseManiaLine(info *SkinInfo, mInfo *ManiaInfo, tokenized []string) *ManiaInfo {
+	if tokenized[0] == "Keys" {
+		keys, err := strconv.Atoi(tokenized[1])
+		if err != nil || keys < 1 || keys > 18 {
+			panic(fmt.Sprintf("Error while parsing Keys: %s", tokenized[1]))
+		}
+
+		mInfo = newDefaultManiaInfo(keys)
+		info.Mania[keys] = mInfo
+
+		return mInfo
+	}
+
+	if mInfo == nil {
+		return nil
+	}
+
+	// Stable accepts windows paths
+	image := strings.ReplaceAll(tokenized[1], "\\", "/")
+
+	// Per-column values, colours are numbered from 1 and images from 0
+	indexed := func(prefix, suffix string, base int) int {
+		if !strings.HasPrefix(tokenized[0], prefix) || !strings.HasSuffix(tokenized[0], suffix) {
+			return -1
+		}
+
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(tokenized[0], prefix), suffix))
+		if err != nil || index-base < 0 || index-base >= mInfo.Keys {
+			return -1
+		}
+
+		return index - base
+	}
+
+	switch tokenized[0] {
+	case "ColumnWidth":
+		parseFloatList(tokenized[1], tokenized[0], mInfo.ColumnWidth)
+	case "ColumnSpacing":
+		parseFloatList(tokenized[1], tokenized[0], mInfo.ColumnSpacing)
+	case "ColumnLineWidth":
+		parseFloatList(tokenized[1], tokenized[0], mInfo.ColumnLineWidth)
+	case "HitPosition":
+		mInfo.HitPosition = ParseFloat(tokenized[1], tokenized[0])
+	case "ScorePosition":
+		mInfo.ScorePosition = ParseFloat(tokenized[1], tokenized[0])
+	case "ComboPosition":
+		mInfo.ComboPosition = ParseFloat(tokenized[1], tokenized[0])
+	case "JudgementLine":
+		mInfo.JudgementLine = tokenized[1] == "1"
+	case "ColourColumnLine":
+		mInfo.ColumnLineColour = ParseColor(tokenized[1], tokenized[0])
+	case "ColourJudgementLine":
+		mInfo.JudgementLineColour = ParseColor(tokenized[1], tokenized[0])
+	case "ColourHold":
+		col := ParseColor(tokenized[1], tokenized[0])
+		mInfo.HoldColour = &col
+	default:
+		if i := indexed("ColourLight", "", 1); i > -1 {
+			mInfo.LightColours[i] = ParseColor(tokenized[1], tokenized[0])
+		} else if i = indexed("Colour", "", 1); i > -1 {
+			mInfo.Colours[i] = ParseColor(tokenized[1], tokenized[0])
+		} else if i = indexed("NoteImage", "H", 0); i > -1 {
+			mInfo.HoldHeadImages[i] = image
+		} else if i = indexed("NoteImage", "L", 0); i > -1 {
+			mInfo.HoldBodyImages[i] = image
+		} else if i = indexed("NoteImage", "T", 0); i > -1 {
+			mInfo.HoldTailImages[i] = image
+		} else if i = indexed("NoteImage", "", 0); i > -1 {
+			mInfo.NoteImages[i] = image
+		} else if i = indexed("KeyImage", "D", 0); i > -1 {
+			mInfo.KeyDownImages[i] = image
+		} else if i = indexed("KeyImage", "", 0); i > -1 {
+			mInfo.KeyImages[i] = image
+		}
+	}
+
+	return mInfo
+}
+
 func LoadInfo(path string, local bool) (*SkinInfo, error) {
 	var file io.ReadCloser
 	var err error
@@ -189,15 +364,30 @@ func LoadInfo(path string, local bool) (*SkinInfo, error) {
 
 	colorsI := make([]colorI, 0)
 
+	var currentSection string
+	var maniaInfo *ManiaInfo
+
 	for scanner.Scan() {
 		line := scanner.Text()
 
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "[") {
+			currentSection = strings.TrimRight(strings.TrimLeft(trimmed, "["), "]")
+			maniaInfo = nil
+
+			continue
+		}
+
 		tokenized := tokenize(line, ":")
 
 		if tokenized == nil {
 			continue
 		}
 
+		if currentSection == "Mania" {
+			maniaInfo = parseManiaLine(info, maniaInfo, tokenized)
+			continue
+		}
+
 		switch tokenized[0] {
 		case "Name":
 			info.Name = tokenized[1]
@@ -343,6 +343,13 @@ func (bg *Background) DrawOverlay(time float64, batch *batch.QuadBatch, bgAlpha
 	batch.ResetTransform()
 }
 
+// Dispose stops the storyboard loaded with the beatmap
+func (bg *Background) Dispose() {
+	if bg.storyboard != nil {
+		bg.storyboard.Dispose()
+	}
+}
+
 func (bg *Background) GetStoryboard() *storyboard.Storyboard {
 	return bg.storyboard
 }
@@ -0,0 +1,209 @@
+package common
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"sync"
+)
+
+const (
+	inspectorTextSize    = 12.0
+	inspectorWindowScale = 0.25 // playfield pixels per millisecond
+	inspectorWindowSize  = 4.0
+)
+
+var (
+	inspectorMissColor  = color2.NewRGBA(1, 0.3, 0.3, 1)
+	inspectorShakeColor = color2.NewRGBA(1, 0.85, 0.3, 1)
+
+	inspectorWindowColors = []color2.Color{color2.NewRGBA(0.85, 0.68, 0.27, 1), color2.NewRGBA(0.44, 0.98, 0.18, 1), color2.NewRGBA(0.2, 0.8, 1, 1)}
+)
+
+type inspectorEntry struct {
+	time     float64
+	position vector.Vector2d
+	shake    bool
+	text     string
+}
+
+// JudgementInspector annotates misses and shaken clicks of a single player with their causes and shows hit windows of the object player has to hit next
+type JudgementInspector struct {
+	ruleset *osu.OsuRuleSet
+	cursor  *graphics.Cursor
+	font    *font.Font
+
+	mutex   sync.Mutex
+	entries []inspectorEntry
+
+	time float64
+}
+
+func NewJudgementInspector(ruleset *osu.OsuRuleSet, cursor *graphics.Cursor) *JudgementInspector {
+	inspector := &JudgementInspector{
+		ruleset: ruleset,
+		cursor:  cursor,
+		font:    font.GetFont("Quicksand Bold"),
+	}
+
+	ruleset.AddAnnotationListener(inspector.annotationReceived)
+	ruleset.AddRewindListener(inspector.clear)
+
+	return inspector
+}
+
+// SetCursor changes the inspected player
+func (inspector *JudgementInspector) SetCursor(cursor *graphics.Cursor) {
+	inspector.cursor = cursor
+
+	inspector.clear()
+}
+
+func (inspector *JudgementInspector) annotationReceived(cursor *graphics.Cursor, annotation osu.Annotation) {
+	if cursor != inspector.cursor || (annotation.Shake && !settings.Gameplay.JudgementInspector.ShowShakes) {
+		return
+	}
+
+	object := inspector.ruleset.GetBeatMap().HitObjects[annotation.Number]
+
+	text := annotation.Cause.String()
+
+	switch annotation.Cause {
+	case osu.Notelock:
+		text = fmt.Sprintf("%s by #%d", text, annotation.Blocker)
+	case osu.TooEarly, osu.TooLate:
+		text = fmt.Sprintf("%s (%+dms)", text, annotation.Time-int64(object.GetStartTime()))
+	}
+
+	if annotation.Shake {
+		text = "Shake: " + text
+	} else {
+		text = "Miss: " + text
+	}
+
+	inspector.mutex.Lock()
+
+	inspector.entries = append(inspector.entries, inspectorEntry{
+		time:     float64(annotation.Time),
+		position: inspector.toDisplay(annotation.Position).Copy64(),
+		shake:    annotation.Shake,
+		text:     text,
+	})
+
+	inspector.mutex.Unlock()
+}
+
+func (inspector *JudgementInspector) clear() {
+	inspector.mutex.Lock()
+	inspector.entries = inspector.entries[:0]
+	inspector.mutex.Unlock()
+}
+
+// toDisplay flips positions of HardRock players if the beatmap is displayed without it
+func (inspector *JudgementInspector) toDisplay(position vector.Vector2f) vector.Vector2f {
+	if inspector.ruleset.GetDifficulty(inspector.cursor).CheckModActive(difficulty.HardRock) != inspector.ruleset.GetBeatMap().Diff.CheckModActive(difficulty.HardRock) {
+		position.Y = 384 - position.Y
+	}
+
+	return position
+}
+
+func (inspector *JudgementInspector) Update(time float64) {
+	inspector.time = time
+
+	fadeOut := settings.Gameplay.JudgementInspector.FadeOutTime * 1000
+
+	inspector.mutex.Lock()
+
+	for i := 0; i < len(inspector.entries); i++ {
+		if time-inspector.entries[i].time > fadeOut {
+			inspector.entries = append(inspector.entries[:i], inspector.entries[i+1:]...)
+			i--
+		}
+	}
+
+	inspector.mutex.Unlock()
+}
+
+func (inspector *JudgementInspector) Draw(batch *batch.QuadBatch, _ []color2.Color, alpha float64) {
+	if alpha < 0.01 {
+		return
+	}
+
+	if settings.Gameplay.JudgementInspector.ShowHitWindows {
+		inspector.drawHitWindows(batch, alpha)
+	}
+
+	fadeOut := settings.Gameplay.JudgementInspector.FadeOutTime * 1000
+	size := inspectorTextSize * settings.Gameplay.JudgementInspector.TextScale
+
+	inspector.mutex.Lock()
+
+	for i, entry := range inspector.entries {
+		entryAlpha := alpha
+		if fadeOut > 0 {
+			entryAlpha *= mutils.ClampF(1-(inspector.time-entry.time)/fadeOut, 0, 1)
+		}
+
+		color := inspectorMissColor
+		if entry.shake {
+			color = inspectorShakeColor
+		}
+
+		color.A = float32(entryAlpha)
+
+		// Stack labels of nearby annotations so they don't overlap
+		offset := 0.0
+		for _, other := range inspector.entries[:i] {
+			if other.position.Dst(entry.position) < size*2 {
+				offset += size
+			}
+		}
+
+		inspector.font.DrawOriginRotationColor(batch, entry.position.X, entry.position.Y-size-offset, vector.BottomCentre, size, 0, false, color, entry.text)
+	}
+
+	inspector.mutex.Unlock()
+
+	batch.SetColor(1, 1, 1, 1)
+}
+
+func (inspector *JudgementInspector) drawHitWindows(batch *batch.QuadBatch, alpha float64) {
+	current := inspector.ruleset.GetCurrentObject(inspector.cursor)
+	if current == nil {
+		return
+	}
+
+	diff := inspector.ruleset.GetDifficulty(inspector.cursor)
+	object := inspector.ruleset.GetBeatMap().HitObjects[current.GetNumber()]
+
+	position := object.GetStackedStartPositionMod(inspector.ruleset.GetBeatMap().Diff.Mods).Copy64()
+	position.Y += inspector.ruleset.GetBeatMap().Diff.CircleRadius + inspectorWindowSize*2
+
+	pixel := graphics.Pixel.GetRegion()
+
+	for i, window := range []int64{diff.Hit50, diff.Hit100, diff.Hit300} {
+		width := float64(window) * 2 * inspectorWindowScale
+
+		color := inspectorWindowColors[i]
+		color.A = float32(alpha * 0.8)
+
+		batch.DrawStObject(position, vector.Centre, vector.NewVec2d(width, inspectorWindowSize), false, false, 0, color, false, pixel)
+	}
+
+	offset := (inspector.time - object.GetStartTime()) * inspectorWindowScale
+	limit := float64(diff.Hit50) * inspectorWindowScale
+
+	if offset < -limit || offset > limit {
+		return
+	}
+
+	batch.DrawStObject(position.AddS(offset, 0), vector.Centre, vector.NewVec2d(1, inspectorWindowSize*2.5), false, false, 0, color2.NewLA(1, float32(alpha)), false, pixel)
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/wieku/danser-go/app/discord"
 	"github.com/wieku/danser-go/app/graphics"
 	"github.com/wieku/danser-go/app/rulesets/osu"
-	"github.com/wieku/danser-go/app/rulesets/osu/performance"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/app/skin"
 	"github.com/wieku/danser-go/app/states/components/common"
@@ -137,6 +137,8 @@ type KnockoutOverlay struct {
 	fade      *animation.Glider
 
 	alivePlayers int
+
+	rewinding bool
 }
 
 func NewKnockoutOverlay(replayController *dance.ReplayController) *KnockoutOverlay {
@@ -189,49 +191,7 @@ func NewKnockoutOverlay(replayController *dance.ReplayController) *KnockoutOverl
 	}
 
 	replayController.GetRuleset().SetListener(overlay.hitReceived)
-
-	sortFunc := func(number int64, instantSort bool) {
-		alive := 0
-		for _, g := range overlay.playersArray {
-			if !g.hasBroken {
-				alive++
-			}
-		}
-
-		if settings.Knockout.LiveSort {
-			cond := strings.ToLower(settings.Knockout.SortBy)
-
-			sort.SliceStable(overlay.playersArray, func(i, j int) bool {
-				mainCond := true
-				switch cond {
-				case "pp":
-					mainCond = overlay.playersArray[i].perObjectStats[number].pp > overlay.playersArray[j].perObjectStats[number].pp
-				case "acc", "accuracy":
-					mainCond = overlay.playersArray[i].perObjectStats[number].accuracy > overlay.playersArray[j].perObjectStats[number].accuracy
-				default:
-					mainCond = overlay.playersArray[i].perObjectStats[number].score > overlay.playersArray[j].perObjectStats[number].score
-				}
-
-				return (!overlay.playersArray[i].hasBroken && overlay.playersArray[j].hasBroken) || ((!overlay.playersArray[i].hasBroken && !overlay.playersArray[j].hasBroken) && mainCond) || ((overlay.playersArray[i].hasBroken && overlay.playersArray[j].hasBroken) && (overlay.playersArray[i].breakTime > overlay.playersArray[j].breakTime || (overlay.playersArray[i].breakTime == overlay.playersArray[j].breakTime && mainCond)))
-			})
-
-			for i, g := range overlay.playersArray {
-				if i != g.currentIndex {
-					g.index.Reset()
-
-					animDuration := 0.0
-					if !instantSort {
-						animDuration = 200 + math.Abs(float64(i-g.currentIndex))*10
-					}
-
-					g.index.AddEvent(overlay.normalTime, overlay.normalTime+animDuration, float64(i))
-					g.currentIndex = i
-				}
-			}
-		}
-
-		discord.UpdateKnockout(alive, len(overlay.playersArray))
-	}
+	replayController.GetRuleset().AddRewindListener(overlay.rewind)
 
 	replayController.GetRuleset().SetEndListener(func(time int64, number int64) {
 		if number == int64(len(replayController.GetBeatMap().HitObjects)-1) && settings.Knockout.RevivePlayersAtEnd {
@@ -247,9 +207,9 @@ func NewKnockoutOverlay(replayController *dance.ReplayController) *KnockoutOverl
 				player.height.AddEvent(overlay.normalTime, overlay.normalTime+200, overlay.ScaledHeight*0.9*1.04/(51))
 			}
 
-			sortFunc(number, true)
+			overlay.sortPlayers(number, true)
 		} else {
-			sortFunc(number, false)
+			overlay.sortPlayers(number, false)
 		}
 	})
 
@@ -261,7 +221,50 @@ func NewKnockoutOverlay(replayController *dance.ReplayController) *KnockoutOverl
 	return overlay
 }
 
-func (overlay *KnockoutOverlay) hitReceived(cursor *graphics.Cursor, time int64, number int64, position vector.Vector2d, result osu.HitResult, comboResult osu.ComboResult, ppResults performance.PPv2Results, score int64) {
+func (overlay *KnockoutOverlay) sortPlayers(number int64, instantSort bool) {
+	alive := 0
+	for _, g := range overlay.playersArray {
+		if !g.hasBroken {
+			alive++
+		}
+	}
+
+	if settings.Knockout.LiveSort {
+		cond := strings.ToLower(settings.Knockout.SortBy)
+
+		sort.SliceStable(overlay.playersArray, func(i, j int) bool {
+			mainCond := true
+			switch cond {
+			case "pp":
+				mainCond = overlay.playersArray[i].perObjectStats[number].pp > overlay.playersArray[j].perObjectStats[number].pp
+			case "acc", "accuracy":
+				mainCond = overlay.playersArray[i].perObjectStats[number].accuracy > overlay.playersArray[j].perObjectStats[number].accuracy
+			default:
+				mainCond = overlay.playersArray[i].perObjectStats[number].score > overlay.playersArray[j].perObjectStats[number].score
+			}
+
+			return (!overlay.playersArray[i].hasBroken && overlay.playersArray[j].hasBroken) || ((!overlay.playersArray[i].hasBroken && !overlay.playersArray[j].hasBroken) && mainCond) || ((overlay.playersArray[i].hasBroken && overlay.playersArray[j].hasBroken) && (overlay.playersArray[i].breakTime > overlay.playersArray[j].breakTime || (overlay.playersArray[i].breakTime == overlay.playersArray[j].breakTime && mainCond)))
+		})
+
+		for i, g := range overlay.playersArray {
+			if i != g.currentIndex {
+				g.index.Reset()
+
+				animDuration := 0.0
+				if !instantSort {
+					animDuration = 200 + math.Abs(float64(i-g.currentIndex))*10
+				}
+
+				g.index.AddEvent(overlay.normalTime, overlay.normalTime+animDuration, float64(i))
+				g.currentIndex = i
+			}
+		}
+	}
+
+	discord.UpdateKnockout(alive, len(overlay.playersArray))
+}
+
+func (overlay *KnockoutOverlay) hitReceived(cursor *graphics.Cursor, time int64, number int64, position vector.Vector2d, result osu.HitResult, comboResult osu.ComboResult, ppResults api.PPv2Results, score int64) {
 	if result == osu.PositionalMiss {
 		return
 	}
@@ -293,7 +296,7 @@ func (overlay *KnockoutOverlay) hitReceived(cursor *graphics.Cursor, time int64,
 	resultClean := result & osu.BaseHitsM
 
 	acceptableHits := resultClean&(osu.Hit100|osu.Hit50|osu.Miss) > 0
-	if acceptableHits {
+	if acceptableHits && !overlay.rewinding {
 		player.fadeHit.Reset()
 		player.fadeHit.AddEventS(overlay.normalTime, overlay.normalTime+300, 0.5, 1)
 		player.fadeHit.AddEventS(overlay.normalTime+600, overlay.normalTime+900, 1, 0)
@@ -308,7 +311,7 @@ func (overlay *KnockoutOverlay) hitReceived(cursor *graphics.Cursor, time int64,
 	if (settings.Knockout.Mode == settings.SSOrQuit && (acceptableHits || comboBreak)) || (comboBreak && number != 0) {
 		if !player.hasBroken {
 			if settings.Knockout.Mode == settings.XReplays {
-				if player.sCombo >= int64(settings.Knockout.BubbleMinimumCombo) {
+				if player.sCombo >= int64(settings.Knockout.BubbleMinimumCombo) && !overlay.rewinding {
 					overlay.deathBubbles = append(overlay.deathBubbles, newBubble(position, overlay.normalTime, overlay.names[cursor], player.sCombo, resultClean, comboResult))
 					log.Println(overlay.names[cursor], "has broken! Combo:", player.sCombo)
 				}
@@ -322,14 +325,17 @@ func (overlay *KnockoutOverlay) hitReceived(cursor *graphics.Cursor, time int64,
 
 				overlay.alivePlayers--
 
-				player.fade.AddEvent(overlay.normalTime, overlay.normalTime+3000, 0)
+				// Knocked out players are hidden instantly after rewinding
+				if !overlay.rewinding {
+					player.fade.AddEvent(overlay.normalTime, overlay.normalTime+3000, 0)
 
-				player.height.SetEasing(easing.OutQuad)
-				player.height.AddEvent(overlay.normalTime+2500, overlay.normalTime+3000, 0)
+					player.height.SetEasing(easing.OutQuad)
+					player.height.AddEvent(overlay.normalTime+2500, overlay.normalTime+3000, 0)
 
-				overlay.deathBubbles = append(overlay.deathBubbles, newBubble(position, overlay.normalTime, overlay.names[cursor], player.sCombo, resultClean, comboResult))
+					overlay.deathBubbles = append(overlay.deathBubbles, newBubble(position, overlay.normalTime, overlay.names[cursor], player.sCombo, resultClean, comboResult))
 
-				log.Println(overlay.names[cursor], "has broken! Max combo:", player.sCombo)
+					log.Println(overlay.names[cursor], "has broken! Max combo:", player.sCombo)
+				}
 			}
 		}
 	}
@@ -339,6 +345,60 @@ func (overlay *KnockoutOverlay) hitReceived(cursor *graphics.Cursor, time int64,
 	}
 }
 
+// rewind rebuilds players' state from judgements left in ruleset's history after it was restored from a snapshot
+func (overlay *KnockoutOverlay) rewind() {
+	for _, player := range overlay.players {
+		player.sCombo = 0
+		player.hasBroken = false
+		player.breakTime = 0
+		player.score = 0
+		player.pp = 0
+		player.lastHit = osu.Hit300
+	}
+
+	overlay.alivePlayers = len(overlay.players)
+
+	lastNumber := int64(-1)
+
+	overlay.rewinding = true
+
+	overlay.controller.GetRuleset().ReplayHistory(func(cursor *graphics.Cursor, time int64, number int64, position vector.Vector2d, result osu.HitResult, comboResult osu.ComboResult, ppResults api.PPv2Results, score int64) {
+		overlay.hitReceived(cursor, time, number, position, result, comboResult, ppResults, score)
+
+		lastNumber = mutils.Max(lastNumber, number)
+	})
+
+	overlay.rewinding = false
+
+	overlay.deathBubbles = overlay.deathBubbles[:0]
+
+	for cursor, name := range overlay.names {
+		player := overlay.players[name]
+
+		fade, height := 1.0, overlay.ScaledHeight*0.9*1.04/(51)
+		if player.hasBroken {
+			fade, height = 0, 0
+		}
+
+		player.fade.Reset()
+		player.fade.SetValue(fade)
+
+		player.height.Reset()
+		player.height.SetValue(height)
+
+		player.fadeHit.Reset()
+		player.fadeHit.SetValue(0)
+
+		player.scoreDisp.SetValue(float64(player.score), true)
+		player.ppDisp.SetValue(player.pp, true)
+		player.accDisp.SetValue(overlay.controller.GetRuleset().GetScore(cursor).Accuracy, true)
+	}
+
+	if lastNumber >= 0 {
+		overlay.sortPlayers(lastNumber, true)
+	}
+}
+
 func (overlay *KnockoutOverlay) Update(time float64) {
 	if overlay.audioTime == 0 {
 		overlay.audioTime = time
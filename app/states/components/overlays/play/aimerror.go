@@ -158,6 +158,25 @@ func (meter *AimErrorMeter) Add(time float64, hitPosition vector.Vector2f, start
 	meter.urGlider.SetValue(meter.unstableRate, settings.Gameplay.AimErrorMeter.StaticUnstableRate)
 }
 
+// Reset removes all errors and resets unstable rate
+func (meter *AimErrorMeter) Reset() {
+	meter.errorDisplay = sprite.NewManager()
+	meter.errorDisplay.Add(meter.errorDot)
+
+	meter.errorCurrent = vector.NewVec2d(0, 0)
+
+	meter.errorDot.ClearTransformations()
+	meter.errorDot.SetPosition(meter.errorCurrent)
+
+	meter.errorDisplayFade.Reset()
+	meter.errorDisplayFade.SetValue(0)
+
+	meter.errors = meter.errors[:0]
+
+	meter.unstableRate = 0
+	meter.urGlider.SetValue(0, true)
+}
+
 func (meter *AimErrorMeter) Update(time float64) {
 	meter.errorDisplayFade.Update(time)
 	meter.errorDisplay.Update(time)
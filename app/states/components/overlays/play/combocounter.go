@@ -102,6 +102,18 @@ func (counter *ComboCounter) Reset() {
 	counter.popCounter.SetText(fmt.Sprintf("%dx", counter.combo))
 }
 
+// SetCombo changes the combo without any animations or sounds
+func (counter *ComboCounter) SetCombo(combo int) {
+	counter.combo = combo
+	counter.nextTransfer = math.MaxFloat64
+
+	counter.popCounter.ClearTransformations()
+	counter.popCounter.SetAlpha(0)
+	counter.popCounter.SetText(fmt.Sprintf("%dx", combo))
+
+	counter.updateMain(combo, false)
+}
+
 func (counter *ComboCounter) GetCombo() int {
 	return counter.combo
 }
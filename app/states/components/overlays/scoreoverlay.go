@@ -12,7 +12,7 @@ import (
 	"github.com/wieku/danser-go/app/graphics"
 	"github.com/wieku/danser-go/app/input"
 	"github.com/wieku/danser-go/app/rulesets/osu"
-	"github.com/wieku/danser-go/app/rulesets/osu/performance"
+	"github.com/wieku/danser-go/app/rulesets/osu/performance/api"
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/app/skin"
 	"github.com/wieku/danser-go/app/states/components/common"
@@ -218,6 +218,7 @@ func NewScoreOverlay(ruleset *osu.OsuRuleSet, cursor *graphics.Cursor) *ScoreOve
 	overlay.circularMetre = skin.GetTextureSource("circularmetre", skin.LOCAL)
 
 	ruleset.SetListener(overlay.hitReceived)
+	ruleset.AddRewindListener(overlay.rewind)
 
 	overlay.camera = camera2.NewCamera()
 	overlay.camera.SetViewportF(0, int(overlay.ScaledHeight), int(overlay.ScaledWidth), 0)
@@ -323,33 +324,14 @@ func (overlay *ScoreOverlay) initUnderlay() {
 	overlay.underlay.SetScale(uScale)
 }
 
-func (overlay *ScoreOverlay) hitReceived(c *graphics.Cursor, time int64, number int64, position vector.Vector2d, result osu.HitResult, comboResult osu.ComboResult, ppResults performance.PPv2Results, _ int64) {
+func (overlay *ScoreOverlay) hitReceived(c *graphics.Cursor, time int64, number int64, position vector.Vector2d, result osu.HitResult, comboResult osu.ComboResult, ppResults api.PPv2Results, _ int64) {
 	object := overlay.ruleset.GetBeatMap().HitObjects[number]
 
 	if result&(osu.BaseHitsM) > 0 {
 		overlay.results.AddResult(time, result, position, object)
 	}
 
-	_, hC := object.(*objects.Circle)
-	allowCircle := hC && (result&(osu.BaseHits|osu.PositionalMiss) > 0)
-	_, sl := object.(*objects.Slider)
-	allowSlider := sl && (result&(osu.SliderStart|osu.PositionalMiss)) > 0
-
-	if allowCircle || allowSlider {
-		timeDiff := float64(time) - object.GetStartTime()
-
-		overlay.hitErrorMeter.Add(float64(time), timeDiff, result == osu.PositionalMiss)
-
-		var startPos *vector.Vector2f
-		if number > 0 {
-			pos := overlay.ruleset.GetBeatMap().HitObjects[number-1].GetStackedEndPositionMod(overlay.ruleset.GetBeatMap().Diff.Mods)
-			startPos = &pos
-		}
-
-		endPos := object.GetStackedStartPositionMod(overlay.ruleset.GetBeatMap().Diff.Mods)
-
-		overlay.aimErrorMeter.Add(float64(time), c.Position, startPos, &endPos)
-	}
+	overlay.addHitError(c, time, number, object, result)
 
 	if result == osu.PositionalMiss {
 		return
@@ -378,17 +360,107 @@ func (overlay *ScoreOverlay) hitReceived(c *graphics.Cursor, time int64, number
 
 	if overlay.oldGrade != sc.Grade {
 		goroutines.Run(func() {
-			var tex *texture.TextureRegion
-			if sc.Grade != osu.NONE {
-				tex = skin.GetTexture("ranking-" + sc.Grade.TextureName() + "-small")
-			}
+			overlay.updateGrade(sc.Grade)
+		})
+	}
+}
 
-			overlay.rankBack.Texture = tex
-			overlay.rankFront.Texture = tex
+func (overlay *ScoreOverlay) addHitError(c *graphics.Cursor, time int64, number int64, object objects.IHitObject, result osu.HitResult) {
+	_, hC := object.(*objects.Circle)
+	allowCircle := hC && (result&(osu.BaseHits|osu.PositionalMiss) > 0)
+	_, sl := object.(*objects.Slider)
+	allowSlider := sl && (result&(osu.SliderStart|osu.PositionalMiss)) > 0
 
-			overlay.oldGrade = sc.Grade
-		})
+	if !allowCircle && !allowSlider {
+		return
+	}
+
+	timeDiff := float64(time) - object.GetStartTime()
+
+	overlay.hitErrorMeter.Add(float64(time), timeDiff, result == osu.PositionalMiss)
+
+	// Cursor positions are not kept in the history, so aim errors can't be rebuilt after rewinding
+	if c == nil {
+		return
+	}
+
+	var startPos *vector.Vector2f
+	if number > 0 {
+		pos := overlay.ruleset.GetBeatMap().HitObjects[number-1].GetStackedEndPositionMod(overlay.ruleset.GetBeatMap().Diff.Mods)
+		startPos = &pos
+	}
+
+	endPos := object.GetStackedStartPositionMod(overlay.ruleset.GetBeatMap().Diff.Mods)
+
+	overlay.aimErrorMeter.Add(float64(time), c.Position, startPos, &endPos)
+}
+
+func (overlay *ScoreOverlay) updateGrade(grade osu.Grade) {
+	var tex *texture.TextureRegion
+	if grade != osu.NONE {
+		tex = skin.GetTexture("ranking-" + grade.TextureName() + "-small")
 	}
+
+	overlay.rankBack.Texture = tex
+	overlay.rankFront.Texture = tex
+
+	overlay.oldGrade = grade
+}
+
+// rewind rebuilds the overlay from judgements left in ruleset's history after it was restored from a snapshot
+func (overlay *ScoreOverlay) rewind() {
+	diff := overlay.ruleset.GetBeatMap().Diff
+
+	overlay.results = play.NewHitResults(diff)
+	overlay.hitErrorMeter = play.NewHitErrorMeter(overlay.ScaledWidth, overlay.ScaledHeight, diff)
+	overlay.aimErrorMeter.Reset()
+
+	combo := 0
+	sections := 0
+
+	var ppResults api.PPv2Results
+
+	overlay.ruleset.ReplayHistory(func(c *graphics.Cursor, time int64, number int64, _ vector.Vector2d, result osu.HitResult, comboResult osu.ComboResult, pp api.PPv2Results, _ int64) {
+		if c != overlay.cursor {
+			return
+		}
+
+		overlay.addHitError(nil, time, number, overlay.ruleset.GetBeatMap().HitObjects[number], result)
+
+		if result == osu.PositionalMiss {
+			return
+		}
+
+		if comboResult == osu.Increase {
+			combo++
+		} else if comboResult == osu.Reset {
+			combo = 0
+		}
+
+		ppResults = pp
+		sections++
+	})
+
+	overlay.comboCounter.SetCombo(combo)
+
+	if overlay.flashlight != nil {
+		overlay.flashlight.UpdateCombo(int64(combo))
+	}
+
+	if sections < len(overlay.hpSections) {
+		overlay.hpSections = overlay.hpSections[:sections]
+	}
+
+	sc := overlay.ruleset.GetScore(overlay.cursor)
+
+	overlay.entry.UpdatePlayer(sc.Score, int64(sc.Combo))
+
+	overlay.scoreGlider.SetValue(float64(sc.Score), true)
+	overlay.accuracyGlider.SetValue(sc.Accuracy, true)
+
+	overlay.ppDisplay.Add(ppResults)
+
+	overlay.updateGrade(sc.Grade)
 }
 
 func (overlay *ScoreOverlay) Update(time float64) {
@@ -413,7 +485,7 @@ func (overlay *ScoreOverlay) Update(time float64) {
 		overlay.initMods()
 	}
 
-	if input.Win.GetKey(glfw.KeySpace) == glfw.Press {
+	if input.Win != nil && input.Win.GetKey(glfw.KeySpace) == glfw.Press {
 		if overlay.skip != nil && overlay.music != nil && overlay.music.GetState() == bass.MusicPlaying {
 			if overlay.audioTime < overlay.skipTo {
 				overlay.music.SetPosition(overlay.skipTo / 1000)
@@ -0,0 +1,504 @@
+package playfields
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/catch"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/skin"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	"github.com/wieku/danser-go/framework/graphics/texture"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+const (
+	catchBaseHeight = 480.0
+
+	// catchPixelScale converts osu!pixels to the 480px tall space, stable's playfield is 512 osu!pixels wide on 640px wide screen
+	catchPixelScale = 640.0 / 512
+
+	// catchPlateY is the height of catcher's plate, stable's catcher stands at 340 osu!pixels
+	catchPlateY = 340 * catchPixelScale
+
+	catchFruitSize       = 64.0
+	catchDropletScale    = 0.6
+	catchTinyScale       = 0.3
+	catchBananaScale     = 0.8
+	catchPlateFruitScale = 0.5
+
+	catchCatcherAspect = 1.3
+
+	catchJudgementTime = 500.0
+	catchPlateDropTime = 1000.0
+	catchGravity       = 0.002
+)
+
+var (
+	catchBananaColor    = color2.NewRGBA(1, 0.9, 0.2, 1)
+	catchHyperDashColor = color2.NewRGBA(1, 0.2, 0.2, 1)
+	catchCatcherColor   = color2.NewRGBA(0.85, 0.85, 0.85, 1)
+	catchMissColor      = color2.NewRGBA(1, 0.3, 0.3, 1)
+)
+
+var catchFruitTextures = []string{"fruit-pear", "fruit-grapes", "fruit-apple", "fruit-orange"}
+
+// plateFruit is a fruit stacked on catcher's plate, position is relative to plate's centre in osu!pixels
+type plateFruit struct {
+	x, y     float64
+	rotation float64
+	texture  int
+	color    color2.Color
+}
+
+// droppedFruit is a fruit that fell or was thrown from the plate, positions are absolute in osu!pixels
+type droppedFruit struct {
+	plateFruit
+	time   float64
+	vx, vy float64
+}
+
+type catchJudgement struct {
+	time float64
+	x    float64
+}
+
+// CatchPlayfield draws osu!catch falling objects, catcher with fruits stacked on its plate and missed judgements of a single player
+type CatchPlayfield struct {
+	ruleset *catch.CatchRuleset
+	font    *font.Font
+
+	scale float64
+	left  float64
+
+	catcherScale float64
+	preempt      float64
+
+	fruits, fruitOverlays []*texture.TextureRegion
+	droplet, banana       *texture.TextureRegion
+	catcherIdle           *texture.TextureRegion
+	catcherFail           *texture.TextureRegion
+	missTexture           *texture.TextureRegion
+
+	colors []color2.Color
+
+	mutex sync.Mutex
+
+	time        float64
+	firstObject int
+
+	catcherX    float64
+	catcherFlip bool
+	failed      bool
+
+	random  *rand.Rand
+	plate   []plateFruit
+	dropped []droppedFruit
+	misses  []catchJudgement
+}
+
+func NewCatchPlayfield(ruleset *catch.CatchRuleset, scaledWidth, scaledHeight float64) *CatchPlayfield {
+	playfield := &CatchPlayfield{
+		ruleset:      ruleset,
+		font:         font.GetFont("Quicksand Bold"),
+		scale:        scaledHeight / catchBaseHeight,
+		catcherScale: catch.GetCatcherScale(ruleset.GetCS()),
+		preempt:      ruleset.GetPreempt(),
+		catcherX:     catch.PlayfieldWidth / 2,
+		colors:       skin.GetColors(),
+		random:       rand.New(rand.NewSource(ruleset.GetBeatMap().TimeAdded)),
+	}
+
+	playfield.left = (scaledWidth/playfield.scale - catch.PlayfieldWidth*catchPixelScale) / 2
+
+	for _, name := range catchFruitTextures {
+		playfield.fruits = append(playfield.fruits, skin.GetTexture(name))
+		playfield.fruitOverlays = append(playfield.fruitOverlays, skin.GetTexture(name+"-overlay"))
+	}
+
+	// Skins without osu!catch elements fall back to osu!standard circles
+	for i, fruit := range playfield.fruits {
+		if fruit == nil {
+			playfield.fruits[i] = skin.GetTexture("hitcircle")
+			playfield.fruitOverlays[i] = skin.GetTexture("hitcircleoverlay")
+		}
+	}
+
+	playfield.droplet = skin.GetTexture("fruit-drop")
+	if playfield.droplet == nil {
+		playfield.droplet = playfield.fruits[0]
+	}
+
+	playfield.banana = skin.GetTexture("fruit-bananas")
+	if playfield.banana == nil {
+		playfield.banana = playfield.fruits[0]
+	}
+
+	playfield.catcherIdle = skin.GetMostSpecific(skin.GetTexture("fruit-catcher-idle"), skin.GetTexture("fruit-ryuuta"))
+	playfield.catcherFail = skin.GetTexture("fruit-catcher-fail")
+	playfield.missTexture = skin.GetTexture("hit0")
+
+	ruleset.SetListener(playfield.objectJudged)
+
+	return playfield
+}
+
+func (playfield *CatchPlayfield) objectJudged(time float64, object *catch.Object, caught bool, catcherX float64) {
+	playfield.mutex.Lock()
+	defer playfield.mutex.Unlock()
+
+	if object.Type == catch.Banana {
+		return
+	}
+
+	if !caught {
+		if object.Type != catch.TinyDroplet {
+			playfield.failed = true
+
+			playfield.misses = append(playfield.misses, catchJudgement{time: time, x: object.X})
+
+			playfield.dropPlate(time, catcherX, false)
+		}
+
+		return
+	}
+
+	if object.Type != catch.TinyDroplet {
+		playfield.failed = false
+	}
+
+	if object.Type == catch.Fruit {
+		playfield.placeOnPlate(object, catcherX)
+	}
+
+	if object.LastInCombo {
+		playfield.dropPlate(time, catcherX, true)
+	}
+}
+
+// placeOnPlate stacks caught fruit on the plate, fruits are randomly moved up until they stop overlapping too much with others
+func (playfield *CatchPlayfield) placeOnPlate(object *catch.Object, catcherX float64) {
+	const allowance = 10.0
+
+	radius := catchFruitSize * catchPlateFruitScale * playfield.catcherScale / 2
+	halfWidth := playfield.ruleset.GetCatchWidth() / 2
+
+	fruit := plateFruit{
+		x:        mutils.ClampF(object.X-catcherX, -halfWidth, halfWidth),
+		rotation: (playfield.random.Float64() - 0.5) * math.Pi / 4,
+		texture:  playfield.fruitIndex(object),
+		color:    playfield.objectColor(object),
+	}
+
+	for tries := 0; tries < 100 && playfield.overlapsPlate(fruit, radius*2/(allowance/2)); tries++ {
+		diff := radius * 2 / allowance
+
+		fruit.x += (playfield.random.Float64() - 0.5) * diff * 2
+		fruit.y -= playfield.random.Float64() * diff
+	}
+
+	fruit.x = mutils.ClampF(fruit.x, -halfWidth, halfWidth)
+
+	playfield.plate = append(playfield.plate, fruit)
+}
+
+func (playfield *CatchPlayfield) overlapsPlate(fruit plateFruit, distance float64) bool {
+	for _, f := range playfield.plate {
+		if math.Hypot(f.x-fruit.x, f.y-fruit.y) < distance {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dropPlate clears the plate, fruits are thrown up at the end of a combo and fall down on miss
+func (playfield *CatchPlayfield) dropPlate(time, catcherX float64, explode bool) {
+	if settings.Gameplay.Catch.ShowPlate {
+		for _, f := range playfield.plate {
+			dropped := droppedFruit{
+				plateFruit: f,
+				time:       time,
+			}
+
+			dropped.x += catcherX
+
+			if explode {
+				dropped.vx = f.x/32 + (playfield.random.Float64()-0.5)*0.2
+				dropped.vy = -0.5 - playfield.random.Float64()*0.3
+			}
+
+			playfield.dropped = append(playfield.dropped, dropped)
+		}
+	}
+
+	playfield.plate = playfield.plate[:0]
+}
+
+func (playfield *CatchPlayfield) Update(time float64) {
+	playfield.mutex.Lock()
+
+	if x := playfield.ruleset.GetCatcherX(); x != playfield.catcherX {
+		playfield.catcherFlip = x < playfield.catcherX
+		playfield.catcherX = x
+	}
+
+	playfield.time = time
+
+	catchObjects := playfield.ruleset.GetObjects()
+
+	for playfield.firstObject < len(catchObjects) {
+		o := catchObjects[playfield.firstObject]
+
+		if !o.Judged || playfield.getY(o.StartTime) < catchBaseHeight+catchFruitSize {
+			break
+		}
+
+		playfield.firstObject++
+	}
+
+	n := 0
+	for _, f := range playfield.dropped {
+		if time-f.time < catchPlateDropTime {
+			playfield.dropped[n] = f
+			n++
+		}
+	}
+
+	playfield.dropped = playfield.dropped[:n]
+
+	n = 0
+	for _, m := range playfield.misses {
+		if time-m.time < catchJudgementTime {
+			playfield.misses[n] = m
+			n++
+		}
+	}
+
+	playfield.misses = playfield.misses[:n]
+
+	playfield.mutex.Unlock()
+}
+
+func (playfield *CatchPlayfield) Draw(batch *batch.QuadBatch, _ []color2.Color, alpha float64) {
+	playfield.mutex.Lock()
+	defer playfield.mutex.Unlock()
+
+	if alpha < 0.01 {
+		return
+	}
+
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, alpha)
+
+	playfield.drawObjects(batch)
+	playfield.drawCatcher(batch)
+	playfield.drawDropped(batch)
+	playfield.drawHUD(batch)
+
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, 1)
+}
+
+// getY returns the height of an object at the given time, objects fall from the top of the screen to the plate in preempt time
+func (playfield *CatchPlayfield) getY(startTime float64) float64 {
+	return catchPlateY * (1 - (startTime-playfield.time)/playfield.preempt)
+}
+
+// toScreen converts osu!pixel x and 480px space y to screen position
+func (playfield *CatchPlayfield) toScreen(x, y float64) vector.Vector2d {
+	return vector.NewVec2d(playfield.left+x*catchPixelScale, y).Scl(playfield.scale)
+}
+
+// drawTexture draws texture with the given size in osu!pixels, width of non-square textures is adjusted to keep the aspect ratio
+func (playfield *CatchPlayfield) drawTexture(batch *batch.QuadBatch, tex *texture.TextureRegion, position vector.Vector2d, size float64, origin vector.Vector2d, flip bool, rotation float64, color color2.Color) {
+	if tex == nil {
+		return
+	}
+
+	scale := size * catchPixelScale * playfield.scale / float64(tex.Height)
+
+	batch.DrawStObject(position, origin, vector.NewVec2d(scale, scale), flip, false, rotation, color, false, *tex)
+}
+
+func (playfield *CatchPlayfield) fruitIndex(object *catch.Object) int {
+	return int(object.StartTime) % len(playfield.fruits)
+}
+
+func (playfield *CatchPlayfield) objectColor(object *catch.Object) color2.Color {
+	if object.Type == catch.Banana {
+		return catchBananaColor
+	}
+
+	if len(playfield.colors) == 0 {
+		return color2.NewL(1)
+	}
+
+	return playfield.colors[int(object.ComboSet)%len(playfield.colors)]
+}
+
+func (playfield *CatchPlayfield) drawObjects(batch *batch.QuadBatch) {
+	catchObjects := playfield.ruleset.GetObjects()
+
+	last := playfield.firstObject
+	for last < len(catchObjects) && catchObjects[last].StartTime-playfield.preempt <= playfield.time {
+		last++
+	}
+
+	// Earlier objects are drawn on top
+	for i := last - 1; i >= playfield.firstObject; i-- {
+		o := catchObjects[i]
+
+		if o.Judged && o.Caught {
+			continue
+		}
+
+		position := playfield.toScreen(o.X, playfield.getY(o.StartTime))
+
+		color := playfield.objectColor(o)
+
+		switch o.Type {
+		case catch.Fruit:
+			size := catchFruitSize * playfield.catcherScale
+			index := playfield.fruitIndex(o)
+
+			if o.IsHyperDash() {
+				hyperColor := catchHyperDashColor
+				hyperColor.A = 0.6
+
+				playfield.drawTexture(batch, playfield.fruits[index], position, size*1.2, vector.Centre, false, 0, hyperColor)
+			}
+
+			playfield.drawTexture(batch, playfield.fruits[index], position, size, vector.Centre, false, 0, color)
+			playfield.drawTexture(batch, playfield.fruitOverlays[index], position, size, vector.Centre, false, 0, color2.NewL(1))
+		case catch.Droplet, catch.TinyDroplet:
+			size := catchFruitSize * catchDropletScale * playfield.catcherScale
+			if o.Type == catch.TinyDroplet {
+				size = catchFruitSize * catchTinyScale * playfield.catcherScale
+			}
+
+			if o.IsHyperDash() {
+				color = catchHyperDashColor
+			}
+
+			playfield.drawTexture(batch, playfield.droplet, position, size, vector.Centre, false, 0, color)
+		case catch.Banana:
+			playfield.drawTexture(batch, playfield.banana, position, catchFruitSize*catchBananaScale*playfield.catcherScale, vector.Centre, false, o.StartTime/200, color)
+		}
+	}
+}
+
+func (playfield *CatchPlayfield) drawCatcher(batch *batch.QuadBatch) {
+	width := catch.GetCatchWidth(playfield.ruleset.GetCS()) / 0.8
+
+	color := color2.NewL(1)
+	if playfield.ruleset.IsHyperDashing() {
+		color = catchHyperDashColor
+	} else if playfield.ruleset.IsDashing() {
+		color = color2.NewRGBA(1, 0.85, 0.85, 1)
+	}
+
+	position := playfield.toScreen(playfield.catcherX, catchPlateY)
+
+	tex := playfield.catcherIdle
+	if playfield.failed && playfield.catcherFail != nil {
+		tex = playfield.catcherFail
+	}
+
+	if tex != nil {
+		playfield.drawTexture(batch, tex, position, width*float64(tex.Height)/float64(tex.Width), vector.TopCentre, playfield.catcherFlip, 0, color)
+	} else {
+		quadColor := catchCatcherColor
+		if playfield.ruleset.IsHyperDashing() {
+			quadColor = catchHyperDashColor
+		}
+
+		size := vector.NewVec2d(width, width/catchCatcherAspect).Scl(catchPixelScale * playfield.scale)
+
+		batch.DrawStObject(position, vector.TopCentre, size, false, false, 0, quadColor, false, graphics.Pixel.GetRegion())
+	}
+
+	if !settings.Gameplay.Catch.ShowPlate {
+		return
+	}
+
+	fruitSize := catchFruitSize * catchPlateFruitScale * playfield.catcherScale
+
+	for _, f := range playfield.plate {
+		fruitPosition := playfield.toScreen(playfield.catcherX+f.x, catchPlateY+f.y*catchPixelScale)
+
+		playfield.drawTexture(batch, playfield.fruits[f.texture], fruitPosition, fruitSize, vector.BottomCentre, false, f.rotation, f.color)
+		playfield.drawTexture(batch, playfield.fruitOverlays[f.texture], fruitPosition, fruitSize, vector.BottomCentre, false, f.rotation, color2.NewL(1))
+	}
+}
+
+func (playfield *CatchPlayfield) drawDropped(batch *batch.QuadBatch) {
+	fruitSize := catchFruitSize * catchPlateFruitScale * playfield.catcherScale
+
+	for _, f := range playfield.dropped {
+		t := playfield.time - f.time
+		if t < 0 {
+			continue
+		}
+
+		x := f.x + f.vx*t
+		y := f.y + f.vy*t + catchGravity*t*t/2
+
+		color := f.color
+		color.A *= float32(1 - t/catchPlateDropTime)
+
+		position := playfield.toScreen(x, catchPlateY+y*catchPixelScale)
+		rotation := f.rotation + f.vx*t/100
+
+		playfield.drawTexture(batch, playfield.fruits[f.texture], position, fruitSize, vector.BottomCentre, false, rotation, color)
+		playfield.drawTexture(batch, playfield.fruitOverlays[f.texture], position, fruitSize, vector.BottomCentre, false, rotation, color2.NewLA(1, color.A))
+	}
+}
+
+func (playfield *CatchPlayfield) drawHUD(batch *batch.QuadBatch) {
+	if settings.Gameplay.Catch.ShowHitJudgements {
+		for _, m := range playfield.misses {
+			progress := (playfield.time - m.time) / catchJudgementTime
+			if progress < 0 {
+				continue
+			}
+
+			alpha := float32(1 - math.Pow(progress, 3))
+			position := playfield.toScreen(m.x, catchPlateY-40-20*progress)
+
+			if playfield.missTexture != nil {
+				batch.DrawStObject(position, vector.Centre, vector.NewVec2d(playfield.scale, playfield.scale).Scl(0.5), false, false, 0, color2.NewLA(1, alpha), false, *playfield.missTexture)
+			} else if playfield.font != nil {
+				textColor := catchMissColor
+				textColor.A = alpha
+
+				playfield.font.DrawOriginRotationColor(batch, position.X, position.Y, vector.Centre, 20*playfield.scale, 0, false, textColor, "Miss")
+			}
+		}
+	}
+
+	if !settings.Gameplay.Catch.ShowScoreAndCombo {
+		return
+	}
+
+	score := playfield.ruleset.GetScore()
+
+	if score.Combo > 0 {
+		comboFont := skin.GetFont("combo")
+		comboSize := comboFont.GetSize() * playfield.scale * 0.5
+
+		comboFont.DrawOrigin(batch, 10, catchBaseHeight*playfield.scale-10, vector.BottomLeft, comboSize, false, fmt.Sprintf("%dx", score.Combo))
+	}
+
+	scoreFont := skin.GetFont("score")
+	scoreSize := scoreFont.GetSize() * playfield.scale * 0.4
+	rightX := (playfield.left*2+catch.PlayfieldWidth*catchPixelScale)*playfield.scale - 10
+
+	scoreFont.DrawOrigin(batch, rightX, 0, vector.TopRight, scoreSize, true, fmt.Sprintf("%08d", score.Score))
+	scoreFont.DrawOrigin(batch, rightX, scoreSize, vector.TopRight, scoreSize*0.6, true, fmt.Sprintf("%5.2f%%", score.Accuracy))
+}
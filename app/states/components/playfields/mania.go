@@ -0,0 +1,388 @@
+package playfields
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/mania"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/skin"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	"github.com/wieku/danser-go/framework/graphics/texture"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+	"sync"
+)
+
+const (
+	maniaBaseHeight = 480.0 // stable's mania layout is defined in 480px tall space
+
+	maniaMaxTimeRange  = 11485.0
+	maniaNoteHeight    = 12.0
+	maniaLightHeight   = 100.0
+	maniaLightSlices   = 10
+	maniaJudgementTime = 300.0
+	maniaMissAlpha     = 0.4
+)
+
+var maniaFallbackColors = map[string]color2.Color{
+	"1": color2.NewRGBA(0.92, 0.92, 0.92, 1),
+	"2": color2.NewRGBA(0.35, 0.7, 1, 1),
+	"S": color2.NewRGBA(1, 0.8, 0.25, 1),
+}
+
+var maniaJudgementColors = map[mania.HitResult]color2.Color{
+	mania.HitMax: color2.NewRGBA(0.75, 0.95, 1, 1),
+	mania.Hit300: color2.NewRGBA(1, 0.85, 0.3, 1),
+	mania.Hit200: color2.NewRGBA(0.45, 1, 0.35, 1),
+	mania.Hit100: color2.NewRGBA(0.3, 0.6, 1, 1),
+	mania.Hit50:  color2.NewRGBA(0.7, 0.7, 0.7, 1),
+	mania.Miss:   color2.NewRGBA(1, 0.3, 0.3, 1),
+}
+
+var maniaJudgementTextures = map[mania.HitResult]string{
+	mania.HitMax: "mania-hit300g",
+	mania.Hit300: "mania-hit300",
+	mania.Hit200: "mania-hit200",
+	mania.Hit100: "mania-hit100",
+	mania.Hit50:  "mania-hit50",
+	mania.Miss:   "mania-hit0",
+}
+
+type maniaColumn struct {
+	x     float64
+	width float64
+
+	noteColor  color2.Color // used when note texture is missing
+	lightColor color2.Color
+	holdColor  color2.Color
+
+	note     *texture.TextureRegion
+	holdHead *texture.TextureRegion
+	holdBody *texture.TextureRegion
+	holdTail *texture.TextureRegion
+	key      *texture.TextureRegion
+	keyDown  *texture.TextureRegion
+}
+
+// ManiaPlayfield draws osu!mania stage with notes, key presses and judgements of a single player using skin's [Mania] layout
+type ManiaPlayfield struct {
+	ruleset *mania.ManiaRuleset
+	info    *skin.ManiaInfo
+	font    *font.Font
+
+	scale      float64
+	width      float64
+	stageX     float64
+	stageWidth float64
+	scrollTime float64
+
+	columns []maniaColumn
+
+	judgementTextures map[mania.HitResult]*texture.TextureRegion
+
+	mutex sync.Mutex
+
+	time           float64
+	firstNote      int
+	lastResult     mania.HitResult
+	lastResultTime float64
+}
+
+func NewManiaPlayfield(ruleset *mania.ManiaRuleset, scaledWidth, scaledHeight float64) *ManiaPlayfield {
+	keys := ruleset.GetKeys()
+
+	playfield := &ManiaPlayfield{
+		ruleset:           ruleset,
+		info:              skin.GetInfo().GetManiaInfo(keys),
+		font:              font.GetFont("Quicksand Bold"),
+		scale:             scaledHeight / maniaBaseHeight,
+		lastResultTime:    math.Inf(-1),
+		judgementTextures: make(map[mania.HitResult]*texture.TextureRegion),
+	}
+
+	playfield.width = scaledWidth / playfield.scale
+
+	// Scroll speed is defined in real time so it has to be scaled to beatmap time
+	playfield.scrollTime = maniaMaxTimeRange / mutils.ClampF(settings.Gameplay.Mania.ScrollSpeed, 1, 40) * ruleset.GetBeatMap().Diff.Speed
+
+	info := playfield.info
+
+	for i := 0; i < keys; i++ {
+		playfield.stageWidth += info.ColumnWidth[i]
+
+		if i < keys-1 {
+			playfield.stageWidth += info.ColumnSpacing[i]
+		}
+	}
+
+	playfield.stageX = (playfield.width - playfield.stageWidth) / 2
+
+	x := playfield.stageX
+
+	for i := 0; i < keys; i++ {
+		style := "1"
+		if keys%2 == 1 && i == keys/2 {
+			style = "S"
+		} else if mutils.Min(i, keys-1-i)%2 == 1 {
+			style = "2"
+		}
+
+		column := maniaColumn{
+			x:          x,
+			width:      info.ColumnWidth[i],
+			noteColor:  maniaFallbackColors[style],
+			lightColor: info.LightColours[i],
+			holdColor:  maniaFallbackColors[style],
+			note:       skin.GetTexture(info.NoteImages[i]),
+			holdHead:   skin.GetTexture(info.HoldHeadImages[i]),
+			holdBody:   skin.GetTexture(info.HoldBodyImages[i]),
+			holdTail:   skin.GetTexture(info.HoldTailImages[i]),
+			key:        skin.GetTexture(info.KeyImages[i]),
+			keyDown:    skin.GetTexture(info.KeyDownImages[i]),
+		}
+
+		if info.HoldColour != nil {
+			column.holdColor = *info.HoldColour
+		}
+
+		column.holdColor.A *= 0.75
+
+		playfield.columns = append(playfield.columns, column)
+
+		x += info.ColumnWidth[i]
+		if i < keys-1 {
+			x += info.ColumnSpacing[i]
+		}
+	}
+
+	for result, name := range maniaJudgementTextures {
+		playfield.judgementTextures[result] = skin.GetTexture(name)
+	}
+
+	ruleset.SetListener(playfield.hitReceived)
+
+	return playfield
+}
+
+func (playfield *ManiaPlayfield) hitReceived(time float64, _ *mania.Note, result mania.HitResult, _ bool) {
+	playfield.mutex.Lock()
+
+	playfield.lastResult = result
+	playfield.lastResultTime = time
+
+	playfield.mutex.Unlock()
+}
+
+func (playfield *ManiaPlayfield) Update(time float64) {
+	playfield.mutex.Lock()
+
+	playfield.time = time
+
+	notes := playfield.ruleset.GetNotes()
+
+	for playfield.firstNote < len(notes) && notes[playfield.firstNote].Judged && notes[playfield.firstNote].EndTime < time-playfield.scrollTime {
+		playfield.firstNote++
+	}
+
+	playfield.mutex.Unlock()
+}
+
+func (playfield *ManiaPlayfield) Draw(batch *batch.QuadBatch, _ []color2.Color, alpha float64) {
+	playfield.mutex.Lock()
+	defer playfield.mutex.Unlock()
+
+	if alpha < 0.01 {
+		return
+	}
+
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, alpha)
+
+	playfield.drawStage(batch)
+	playfield.drawNotes(batch)
+	playfield.drawKeys(batch)
+	playfield.drawHUD(batch)
+
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, 1)
+}
+
+func (playfield *ManiaPlayfield) drawQuad(batch *batch.QuadBatch, x, y, width, height float64, origin vector.Vector2d, color color2.Color) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	batch.DrawStObject(vector.NewVec2d(x, y).Scl(playfield.scale), origin, vector.NewVec2d(width, height).Scl(playfield.scale), false, false, 0, color, false, graphics.Pixel.GetRegion())
+}
+
+// drawTexture draws texture stretched to column width with its bottom at y, height <= 0 keeps texture's aspect ratio
+func (playfield *ManiaPlayfield) drawTexture(batch *batch.QuadBatch, tex *texture.TextureRegion, column maniaColumn, y, height float64, color color2.Color) {
+	scaleX := column.width * playfield.scale / float64(tex.Width)
+
+	scaleY := scaleX
+	if height > 0 {
+		scaleY = height * playfield.scale / float64(tex.Height)
+	}
+
+	batch.DrawStObject(vector.NewVec2d(column.x+column.width/2, y).Scl(playfield.scale), vector.BottomCentre, vector.NewVec2d(scaleX, scaleY), false, false, 0, color, false, *tex)
+}
+
+func (playfield *ManiaPlayfield) drawStage(batch *batch.QuadBatch) {
+	info := playfield.info
+
+	for i, column := range playfield.columns {
+		bgColor := info.Colours[i]
+		bgColor.A *= float32(settings.Gameplay.Mania.StageOpacity)
+
+		playfield.drawQuad(batch, column.x, 0, column.width, maniaBaseHeight, vector.TopLeft, bgColor)
+
+		if settings.Gameplay.Mania.ShowKeyLights && playfield.ruleset.IsKeyPressed(i) {
+			sliceHeight := maniaLightHeight / maniaLightSlices
+
+			for j := 0; j < maniaLightSlices; j++ {
+				lightColor := column.lightColor
+				lightColor.A *= 0.5 * float32(maniaLightSlices-j) / maniaLightSlices
+
+				playfield.drawQuad(batch, column.x, info.HitPosition-float64(j)*sliceHeight, column.width, sliceHeight, vector.BottomLeft, lightColor)
+			}
+		}
+
+		playfield.drawQuad(batch, column.x, 0, info.ColumnLineWidth[i], info.HitPosition, vector.TopCentre, info.ColumnLineColour)
+	}
+
+	last := playfield.columns[len(playfield.columns)-1]
+	playfield.drawQuad(batch, last.x+last.width, 0, info.ColumnLineWidth[len(playfield.columns)], info.HitPosition, vector.TopCentre, info.ColumnLineColour)
+
+	if info.JudgementLine {
+		playfield.drawQuad(batch, playfield.stageX, info.HitPosition, playfield.stageWidth, 1, vector.CentreLeft, info.JudgementLineColour)
+	}
+}
+
+// getY returns the position of the given time on the stage
+func (playfield *ManiaPlayfield) getY(time float64) float64 {
+	return playfield.info.HitPosition * (1 - (time-playfield.time)/playfield.scrollTime)
+}
+
+func (playfield *ManiaPlayfield) drawNotes(batch *batch.QuadBatch) {
+	notes := playfield.ruleset.GetNotes()
+
+	for i := playfield.firstNote; i < len(notes); i++ {
+		note := notes[i]
+
+		if note.StartTime > playfield.time+playfield.scrollTime {
+			break
+		}
+
+		if note.Judged && note.Result != mania.Miss && !note.Broken {
+			continue
+		}
+
+		column := playfield.columns[note.Column]
+
+		alpha := 1.0
+		if note.Judged || (note.IsHold && note.StartTime < playfield.time && !note.HeadHit) {
+			alpha = maniaMissAlpha
+		}
+
+		headTime := note.StartTime
+		if note.Holding {
+			headTime = math.Max(headTime, playfield.time)
+		}
+
+		headY := playfield.getY(headTime)
+
+		if note.IsHold {
+			playfield.drawHoldBody(batch, column, headY, playfield.getY(note.EndTime), alpha)
+		}
+
+		noteTexture := column.note
+		if note.IsHold && column.holdHead != nil {
+			noteTexture = column.holdHead
+		}
+
+		if noteTexture != nil {
+			playfield.drawTexture(batch, noteTexture, column, headY, 0, color2.NewLA(1, float32(alpha)))
+		} else {
+			noteColor := column.noteColor
+			noteColor.A *= float32(alpha)
+
+			playfield.drawQuad(batch, column.x, headY, column.width, maniaNoteHeight, vector.BottomLeft, noteColor)
+		}
+	}
+}
+
+func (playfield *ManiaPlayfield) drawHoldBody(batch *batch.QuadBatch, column maniaColumn, headY, tailY, alpha float64) {
+	length := headY - tailY
+	if length <= 0 {
+		return
+	}
+
+	if column.holdBody != nil {
+		playfield.drawTexture(batch, column.holdBody, column, headY, length, color2.NewLA(1, float32(alpha)))
+	} else {
+		bodyColor := column.holdColor
+		bodyColor.A *= float32(alpha)
+
+		playfield.drawQuad(batch, column.x+column.width*0.1, headY, column.width*0.8, length, vector.BottomLeft, bodyColor)
+	}
+
+	if column.holdTail != nil {
+		playfield.drawTexture(batch, column.holdTail, column, tailY, 0, color2.NewLA(1, float32(alpha)))
+	}
+}
+
+func (playfield *ManiaPlayfield) drawKeys(batch *batch.QuadBatch) {
+	for i, column := range playfield.columns {
+		keyTexture := column.key
+		if playfield.ruleset.IsKeyPressed(i) && column.keyDown != nil {
+			keyTexture = column.keyDown
+		}
+
+		if keyTexture != nil {
+			playfield.drawTexture(batch, keyTexture, column, maniaBaseHeight, 0, color2.NewL(1))
+		}
+	}
+}
+
+func (playfield *ManiaPlayfield) drawHUD(batch *batch.QuadBatch) {
+	centreX := (playfield.stageX + playfield.stageWidth/2) * playfield.scale
+
+	if settings.Gameplay.Mania.ShowHitJudgements {
+		if progress := (playfield.time - playfield.lastResultTime) / maniaJudgementTime; progress >= 0 && progress < 1 {
+			alpha := 1 - math.Pow(progress, 3)
+			bump := 1 + 0.2*(1-progress)
+
+			if tex := playfield.judgementTextures[playfield.lastResult]; tex != nil {
+				scl := bump * playfield.scale
+
+				batch.DrawStObject(vector.NewVec2d(centreX, playfield.info.ScorePosition*playfield.scale), vector.Centre, vector.NewVec2d(scl, scl), false, false, 0, color2.NewLA(1, float32(alpha)), false, *tex)
+			} else if playfield.font != nil {
+				textColor := maniaJudgementColors[playfield.lastResult]
+				textColor.A *= float32(alpha)
+
+				playfield.font.DrawOriginRotationColor(batch, centreX, playfield.info.ScorePosition*playfield.scale, vector.Centre, 16*bump*playfield.scale, 0, false, textColor, playfield.lastResult.String())
+			}
+		}
+	}
+
+	if !settings.Gameplay.Mania.ShowScoreAndCombo {
+		return
+	}
+
+	score := playfield.ruleset.GetScore()
+
+	if score.Combo > 0 {
+		comboFont := skin.GetFont("combo")
+		comboFont.DrawOrigin(batch, centreX, playfield.info.ComboPosition*playfield.scale, vector.Centre, comboFont.GetSize()*playfield.scale*0.5, false, fmt.Sprintf("%d", score.Combo))
+	}
+
+	scoreFont := skin.GetFont("score")
+	scoreSize := scoreFont.GetSize() * playfield.scale * 0.4
+	rightX := playfield.width*playfield.scale - 10
+
+	scoreFont.DrawOrigin(batch, rightX, 0, vector.TopRight, scoreSize, true, fmt.Sprintf("%08d", score.Score))
+	scoreFont.DrawOrigin(batch, rightX, scoreSize, vector.TopRight, scoreSize*0.6, true, fmt.Sprintf("%5.2f%%", score.Accuracy))
+}
@@ -0,0 +1,12 @@
+package playfields
+
+import (
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+)
+
+// IPlayfield draws game modes other than osu!standard, it's updated on update thread and drawn on GL thread
+type IPlayfield interface {
+	Update(time float64)
+	Draw(batch *batch.QuadBatch, colors []color2.Color, alpha float64)
+}
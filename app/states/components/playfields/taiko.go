@@ -0,0 +1,443 @@
+package playfields
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/rulesets/taiko"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/skin"
+	"github.com/wieku/danser-go/framework/graphics/batch"
+	"github.com/wieku/danser-go/framework/graphics/font"
+	"github.com/wieku/danser-go/framework/graphics/texture"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+	"sync"
+)
+
+const (
+	taikoBaseHeight = 480.0
+
+	taikoBarCentre    = 150.0
+	taikoBarHeight    = 100.0
+	taikoDrumWidth    = 100.0
+	taikoHitX         = 160.0
+	taikoNoteSize     = 60.0
+	taikoBigNoteSize  = 90.0
+	taikoTickSize     = 12.0
+	taikoHealthHeight = 8.0
+
+	// taikoPixelScale converts osu!pixels to the 480px tall space, stable's playfield is 512 osu!pixels wide on 640px wide screen
+	taikoPixelScale = 640.0 / 512
+
+	taikoHitFadeTime   = 100.0
+	taikoJudgementTime = 300.0
+	taikoMissAlpha     = 0.5
+)
+
+var (
+	taikoDonColor      = color2.NewRGBA(0.92, 0.27, 0.17, 1)
+	taikoKatColor      = color2.NewRGBA(0.26, 0.56, 0.67, 1)
+	taikoDrumRollColor = color2.NewRGBA(0.99, 0.72, 0.02, 1)
+	taikoSwellColor    = color2.NewRGBA(0.96, 0.6, 0.15, 1)
+	taikoBarColor      = color2.NewRGBA(0.1, 0.1, 0.1, 0.8)
+	taikoDrumAreaColor = color2.NewRGBA(0.2, 0.2, 0.25, 0.9)
+)
+
+var taikoJudgementColors = map[taiko.HitResult]color2.Color{
+	taiko.Great: color2.NewRGBA(1, 0.85, 0.3, 1),
+	taiko.Ok:    color2.NewRGBA(0.45, 1, 0.35, 1),
+	taiko.Miss:  color2.NewRGBA(1, 0.3, 0.3, 1),
+}
+
+var taikoJudgementTextures = map[taiko.HitResult]string{
+	taiko.Great: "taiko-hit300",
+	taiko.Ok:    "taiko-hit100",
+	taiko.Miss:  "taiko-hit0",
+}
+
+// TaikoPlayfield draws osu!taiko scrolling bar with notes, drum presses and judgements of a single player
+type TaikoPlayfield struct {
+	ruleset *taiko.TaikoRuleset
+	font    *font.Font
+
+	scale float64
+	width float64
+
+	// velocities are scroll speeds of objects in 480px tall space per millisecond, they're affected by BPM and SV
+	velocities []float64
+	lookAhead  float64
+
+	hitCircle, hitCircleOverlay *texture.TextureRegion
+	bigCircle, bigCircleOverlay *texture.TextureRegion
+	rollMiddle, rollEnd         *texture.TextureRegion
+	barLeft, barRight           *texture.TextureRegion
+	drumInner, drumOuter        *texture.TextureRegion
+	hitTarget                   *texture.TextureRegion
+
+	judgementTextures map[taiko.HitResult]*texture.TextureRegion
+
+	mutex sync.Mutex
+
+	time           float64
+	firstObject    int
+	lastResult     taiko.HitResult
+	lastResultTime float64
+}
+
+func NewTaikoPlayfield(ruleset *taiko.TaikoRuleset, scaledWidth, scaledHeight float64) *TaikoPlayfield {
+	playfield := &TaikoPlayfield{
+		ruleset:           ruleset,
+		font:              font.GetFont("Quicksand Bold"),
+		scale:             scaledHeight / taikoBaseHeight,
+		lastResultTime:    math.Inf(-1),
+		judgementTextures: make(map[taiko.HitResult]*texture.TextureRegion),
+	}
+
+	playfield.width = scaledWidth / playfield.scale
+
+	timings := ruleset.GetBeatMap().Timings
+
+	minVelocity := math.Inf(1)
+
+	for _, o := range ruleset.GetObjects() {
+		velocity := timings.GetVelocity(timings.GetPointAt(o.StartTime)) / 1000 * 1.4 * taikoPixelScale * settings.Gameplay.Taiko.ScrollSpeed
+
+		playfield.velocities = append(playfield.velocities, velocity)
+
+		minVelocity = math.Min(minVelocity, velocity)
+	}
+
+	playfield.lookAhead = (playfield.width - taikoHitX + taikoBigNoteSize) / math.Max(minVelocity, 0.001)
+
+	playfield.hitCircle = skin.GetTexture("taikohitcircle")
+	playfield.hitCircleOverlay = skin.GetTexture("taikohitcircleoverlay")
+	playfield.bigCircle = skin.GetTexture("taikobigcircle")
+	playfield.bigCircleOverlay = skin.GetTexture("taikobigcircleoverlay")
+
+	// Skins without osu!taiko elements fall back to osu!standard circles
+	if playfield.hitCircle == nil {
+		playfield.hitCircle = skin.GetTexture("hitcircle")
+		playfield.hitCircleOverlay = skin.GetTexture("hitcircleoverlay")
+	}
+
+	if playfield.bigCircle == nil {
+		playfield.bigCircle = playfield.hitCircle
+		playfield.bigCircleOverlay = playfield.hitCircleOverlay
+	}
+
+	playfield.rollMiddle = skin.GetTexture("taiko-roll-middle")
+	playfield.rollEnd = skin.GetTexture("taiko-roll-end")
+	playfield.barLeft = skin.GetTexture("taiko-bar-left")
+	playfield.barRight = skin.GetTexture("taiko-bar-right")
+	playfield.drumInner = skin.GetTexture("taiko-drum-inner")
+	playfield.drumOuter = skin.GetTexture("taiko-drum-outer")
+	playfield.hitTarget = skin.GetTexture("approachcircle")
+
+	for result, name := range taikoJudgementTextures {
+		playfield.judgementTextures[result] = skin.GetTexture(name)
+	}
+
+	ruleset.SetListener(playfield.hitReceived)
+
+	return playfield
+}
+
+func (playfield *TaikoPlayfield) hitReceived(time float64, _ *taiko.Object, result taiko.HitResult) {
+	playfield.mutex.Lock()
+
+	playfield.lastResult = result
+	playfield.lastResultTime = time
+
+	playfield.mutex.Unlock()
+}
+
+func (playfield *TaikoPlayfield) Update(time float64) {
+	playfield.mutex.Lock()
+
+	playfield.time = time
+
+	taikoObjects := playfield.ruleset.GetObjects()
+
+	for playfield.firstObject < len(taikoObjects) {
+		o := taikoObjects[playfield.firstObject]
+
+		if !o.Judged || playfield.getX(o.EndTime, playfield.firstObject) > -taikoBigNoteSize {
+			break
+		}
+
+		playfield.firstObject++
+	}
+
+	playfield.mutex.Unlock()
+}
+
+func (playfield *TaikoPlayfield) Draw(batch *batch.QuadBatch, _ []color2.Color, alpha float64) {
+	playfield.mutex.Lock()
+	defer playfield.mutex.Unlock()
+
+	if alpha < 0.01 {
+		return
+	}
+
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, alpha)
+
+	playfield.drawBar(batch)
+	playfield.drawObjects(batch)
+	playfield.drawDrum(batch)
+	playfield.drawHUD(batch)
+
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, 1)
+}
+
+// getX returns the position of the given time of i-th object on the bar
+func (playfield *TaikoPlayfield) getX(time float64, i int) float64 {
+	return taikoHitX + (time-playfield.time)*playfield.velocities[i]
+}
+
+func (playfield *TaikoPlayfield) drawQuad(batch *batch.QuadBatch, x, y, width, height float64, origin vector.Vector2d, color color2.Color) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	batch.DrawStObject(vector.NewVec2d(x, y).Scl(playfield.scale), origin, vector.NewVec2d(width, height).Scl(playfield.scale), false, false, 0, color, false, graphics.Pixel.GetRegion())
+}
+
+// drawTexture draws texture centred at x, y with the given height, width <= 0 keeps texture's aspect ratio
+func (playfield *TaikoPlayfield) drawTexture(batch *batch.QuadBatch, tex *texture.TextureRegion, x, y, width, height float64, origin vector.Vector2d, flip bool, color color2.Color) {
+	if tex == nil {
+		return
+	}
+
+	scaleY := height * playfield.scale / float64(tex.Height)
+
+	scaleX := scaleY
+	if width > 0 {
+		scaleX = width * playfield.scale / float64(tex.Width)
+	}
+
+	batch.DrawStObject(vector.NewVec2d(x, y).Scl(playfield.scale), origin, vector.NewVec2d(scaleX, scaleY), flip, false, 0, color, false, *tex)
+}
+
+func (playfield *TaikoPlayfield) drawBar(batch *batch.QuadBatch) {
+	barTop := taikoBarCentre - taikoBarHeight/2
+
+	if playfield.barRight != nil {
+		playfield.drawTexture(batch, playfield.barRight, taikoDrumWidth, barTop, playfield.width-taikoDrumWidth, taikoBarHeight, vector.TopLeft, false, color2.NewL(1))
+	} else {
+		playfield.drawQuad(batch, taikoDrumWidth, barTop, playfield.width-taikoDrumWidth, taikoBarHeight, vector.TopLeft, taikoBarColor)
+	}
+
+	if playfield.hitTarget != nil {
+		playfield.drawTexture(batch, playfield.hitTarget, taikoHitX, taikoBarCentre, 0, taikoNoteSize, vector.Centre, false, color2.NewLA(1, 0.5))
+	}
+
+	if settings.Gameplay.Taiko.ShowHealth {
+		health := playfield.ruleset.GetScore().Health
+
+		healthColor := color2.NewRGBA(1, 0.3, 0.3, 1)
+		if playfield.ruleset.IsPassing() {
+			healthColor = color2.NewRGBA(0.45, 1, 0.35, 1)
+		}
+
+		healthY := barTop - taikoHealthHeight - 2
+		healthWidth := playfield.width - taikoDrumWidth
+
+		playfield.drawQuad(batch, taikoDrumWidth, healthY, healthWidth, taikoHealthHeight, vector.TopLeft, color2.NewLA(0, 0.5))
+		playfield.drawQuad(batch, taikoDrumWidth, healthY, healthWidth*health, taikoHealthHeight, vector.TopLeft, healthColor)
+		playfield.drawQuad(batch, taikoDrumWidth+healthWidth/2, healthY, 1, taikoHealthHeight, vector.TopCentre, color2.NewL(1))
+	}
+}
+
+func (playfield *TaikoPlayfield) drawObjects(batch *batch.QuadBatch) {
+	taikoObjects := playfield.ruleset.GetObjects()
+
+	last := playfield.firstObject
+	for last < len(taikoObjects) && taikoObjects[last].StartTime <= playfield.time+playfield.lookAhead {
+		last++
+	}
+
+	// Earlier objects are drawn on top
+	for i := last - 1; i >= playfield.firstObject; i-- {
+		switch o := taikoObjects[i]; o.Type {
+		case taiko.Hit:
+			playfield.drawHit(batch, o, i)
+		case taiko.DrumRoll:
+			playfield.drawDrumRoll(batch, o, i)
+		case taiko.Swell:
+			playfield.drawSwell(batch, o, i)
+		}
+	}
+}
+
+func (playfield *TaikoPlayfield) drawNote(batch *batch.QuadBatch, x float64, strong bool, color color2.Color, alpha float64) {
+	size, circle, overlay := taikoNoteSize, playfield.hitCircle, playfield.hitCircleOverlay
+	if strong {
+		size, circle, overlay = taikoBigNoteSize, playfield.bigCircle, playfield.bigCircleOverlay
+	}
+
+	color.A *= float32(alpha)
+
+	playfield.drawTexture(batch, circle, x, taikoBarCentre, 0, size, vector.Centre, false, color)
+	playfield.drawTexture(batch, overlay, x, taikoBarCentre, 0, size, vector.Centre, false, color2.NewLA(1, float32(alpha)))
+}
+
+func (playfield *TaikoPlayfield) drawHit(batch *batch.QuadBatch, note *taiko.Object, i int) {
+	alpha := 1.0
+
+	if note.Judged {
+		if note.Result == taiko.Miss {
+			alpha = taikoMissAlpha
+		} else {
+			// Hit notes quickly fade out at the hit position
+			alpha = 1 - (playfield.time-note.JudgeTime)/taikoHitFadeTime
+			if alpha <= 0 {
+				return
+			}
+
+			playfield.drawNote(batch, taikoHitX, note.Strong, playfield.noteColor(note), alpha)
+
+			return
+		}
+	}
+
+	playfield.drawNote(batch, playfield.getX(note.StartTime, i), note.Strong, playfield.noteColor(note), alpha)
+}
+
+func (playfield *TaikoPlayfield) noteColor(note *taiko.Object) color2.Color {
+	if note.Kat {
+		return taikoKatColor
+	}
+
+	return taikoDonColor
+}
+
+func (playfield *TaikoPlayfield) drawDrumRoll(batch *batch.QuadBatch, drumRoll *taiko.Object, i int) {
+	size := taikoNoteSize
+	if drumRoll.Strong {
+		size = taikoBigNoteSize
+	}
+
+	startX := playfield.getX(drumRoll.StartTime, i)
+	endX := playfield.getX(drumRoll.EndTime, i)
+
+	bodyColor := taikoDrumRollColor
+
+	if playfield.rollMiddle != nil {
+		playfield.drawTexture(batch, playfield.rollMiddle, startX, taikoBarCentre, endX-startX, size, vector.CentreLeft, false, bodyColor)
+		playfield.drawTexture(batch, playfield.rollEnd, endX, taikoBarCentre, 0, size, vector.CentreLeft, false, bodyColor)
+	} else {
+		playfield.drawQuad(batch, startX, taikoBarCentre, endX-startX, size*0.8, vector.CentreLeft, bodyColor)
+		playfield.drawNote(batch, endX, drumRoll.Strong, bodyColor, 1)
+	}
+
+	for _, tick := range drumRoll.Ticks {
+		if !tick.Hit {
+			playfield.drawQuad(batch, playfield.getX(tick.Time, i), taikoBarCentre, taikoTickSize, taikoTickSize, vector.Centre, color2.NewL(1))
+		}
+	}
+
+	playfield.drawNote(batch, startX, drumRoll.Strong, bodyColor, 1)
+}
+
+func (playfield *TaikoPlayfield) drawSwell(batch *batch.QuadBatch, swell *taiko.Object, i int) {
+	if swell.Judged {
+		return
+	}
+
+	x := playfield.getX(swell.StartTime, i)
+
+	if playfield.time >= swell.StartTime {
+		x = taikoHitX
+	}
+
+	playfield.drawNote(batch, x, true, taikoSwellColor, 1)
+
+	if playfield.time >= swell.StartTime && playfield.font != nil {
+		remaining := fmt.Sprintf("%d", swell.RequiredHits-swell.Hits)
+
+		playfield.font.DrawOrigin(batch, x*playfield.scale, (taikoBarCentre+taikoBarHeight/2+20)*playfield.scale, vector.Centre, 24*playfield.scale, false, remaining)
+	}
+}
+
+func (playfield *TaikoPlayfield) drawDrum(batch *batch.QuadBatch) {
+	barTop := taikoBarCentre - taikoBarHeight/2
+
+	if playfield.barLeft != nil {
+		playfield.drawTexture(batch, playfield.barLeft, 0, barTop, taikoDrumWidth, taikoBarHeight, vector.TopLeft, false, color2.NewL(1))
+	} else {
+		playfield.drawQuad(batch, 0, barTop, taikoDrumWidth, taikoBarHeight, vector.TopLeft, taikoDrumAreaColor)
+	}
+
+	keys := playfield.ruleset.GetKeyState()
+
+	centreX := taikoDrumWidth / 2
+	drumSize := taikoBarHeight * 0.8
+
+	sides := []struct {
+		centre, rim uint32
+		origin      vector.Vector2d
+		flip        bool
+	}{
+		{taiko.LeftCentre, taiko.LeftRim, vector.CentreRight, false},
+		{taiko.RightCentre, taiko.RightRim, vector.CentreLeft, true},
+	}
+
+	for _, side := range sides {
+		if keys&side.rim > 0 {
+			if playfield.drumOuter != nil {
+				playfield.drawTexture(batch, playfield.drumOuter, centreX, taikoBarCentre, 0, drumSize, side.origin, side.flip, color2.NewL(1))
+			} else {
+				playfield.drawQuad(batch, centreX, taikoBarCentre, drumSize/2, drumSize, side.origin, taikoKatColor)
+			}
+		}
+
+		if keys&side.centre > 0 {
+			if playfield.drumInner != nil {
+				playfield.drawTexture(batch, playfield.drumInner, centreX, taikoBarCentre, 0, drumSize, side.origin, side.flip, color2.NewL(1))
+			} else {
+				playfield.drawQuad(batch, centreX, taikoBarCentre, drumSize/2*0.7, drumSize*0.7, side.origin, taikoDonColor)
+			}
+		}
+	}
+
+	if settings.Gameplay.Taiko.ShowScoreAndCombo {
+		if combo := playfield.ruleset.GetScore().Combo; combo > 0 {
+			comboFont := skin.GetFont("combo")
+			comboFont.DrawOrigin(batch, centreX*playfield.scale, taikoBarCentre*playfield.scale, vector.Centre, comboFont.GetSize()*playfield.scale*0.4, false, fmt.Sprintf("%d", combo))
+		}
+	}
+}
+
+func (playfield *TaikoPlayfield) drawHUD(batch *batch.QuadBatch) {
+	if settings.Gameplay.Taiko.ShowHitJudgements {
+		if progress := (playfield.time - playfield.lastResultTime) / taikoJudgementTime; progress >= 0 && progress < 1 {
+			alpha := 1 - math.Pow(progress, 3)
+			y := (taikoBarCentre - taikoBarHeight*0.3*progress) * playfield.scale
+
+			if tex := playfield.judgementTextures[playfield.lastResult]; tex != nil {
+				batch.DrawStObject(vector.NewVec2d(taikoHitX*playfield.scale, y), vector.Centre, vector.NewVec2d(playfield.scale, playfield.scale), false, false, 0, color2.NewLA(1, float32(alpha)), false, *tex)
+			} else if playfield.font != nil {
+				textColor := taikoJudgementColors[playfield.lastResult]
+				textColor.A *= float32(alpha)
+
+				playfield.font.DrawOriginRotationColor(batch, taikoHitX*playfield.scale, y, vector.Centre, 20*playfield.scale, 0, false, textColor, playfield.lastResult.String())
+			}
+		}
+	}
+
+	if !settings.Gameplay.Taiko.ShowScoreAndCombo {
+		return
+	}
+
+	score := playfield.ruleset.GetScore()
+
+	scoreFont := skin.GetFont("score")
+	scoreSize := scoreFont.GetSize() * playfield.scale * 0.4
+	rightX := playfield.width*playfield.scale - 10
+
+	scoreFont.DrawOrigin(batch, rightX, 0, vector.TopRight, scoreSize, true, fmt.Sprintf("%08d", score.Score))
+	scoreFont.DrawOrigin(batch, rightX, scoreSize, vector.TopRight, scoreSize*0.6, true, fmt.Sprintf("%5.2f%%", score.Accuracy))
+}
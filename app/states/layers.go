@@ -0,0 +1,52 @@
+package states
+
+import "github.com/wieku/danser-go/app/states/components/overlays"
+
+// Layer is a part of Player's frame that can be rendered separately
+type Layer int
+
+const (
+	LayerBackground Layer = iota
+	LayerObjects
+	LayerCursors
+	LayerHUD
+	LayerKnockout
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerBackground:
+		return "background"
+	case LayerObjects:
+		return "objects"
+	case LayerCursors:
+		return "cursors"
+	case LayerHUD:
+		return "hud"
+	case LayerKnockout:
+		return "knockout"
+	}
+
+	return "unknown"
+}
+
+// SetLayerHook sets the function called every time Player starts drawing a different layer.
+// Layers can be entered multiple times during a single frame.
+func (player *Player) SetLayerHook(hook func(layer Layer)) {
+	player.layerHook = hook
+}
+
+func (player *Player) setLayer(layer Layer) {
+	if player.layerHook != nil {
+		player.layerHook(layer)
+	}
+}
+
+// overlayLayer returns the layer overlay's parts are drawn to
+func (player *Player) overlayLayer() Layer {
+	if _, ok := player.overlay.(*overlays.KnockoutOverlay); ok {
+		return LayerKnockout
+	}
+
+	return LayerHUD
+}
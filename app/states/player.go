@@ -8,12 +8,18 @@ import (
 	camera2 "github.com/wieku/danser-go/app/bmath/camera"
 	"github.com/wieku/danser-go/app/dance"
 	"github.com/wieku/danser-go/app/discord"
+	"github.com/wieku/danser-go/app/ffmpeg"
 	"github.com/wieku/danser-go/app/graphics"
 	"github.com/wieku/danser-go/app/input"
+	"github.com/wieku/danser-go/app/rulesets/catch"
+	"github.com/wieku/danser-go/app/rulesets/mania"
+	"github.com/wieku/danser-go/app/rulesets/osu"
+	"github.com/wieku/danser-go/app/rulesets/taiko"
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/app/states/components/common"
 	"github.com/wieku/danser-go/app/states/components/containers"
 	"github.com/wieku/danser-go/app/states/components/overlays"
+	"github.com/wieku/danser-go/app/states/components/playfields"
 	"github.com/wieku/danser-go/app/utils"
 	"github.com/wieku/danser-go/framework/bass"
 	"github.com/wieku/danser-go/framework/frame"
@@ -32,9 +38,11 @@ import (
 	"github.com/wieku/danser-go/framework/statistic"
 	"log"
 	"math"
+	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"time"
 )
 
 const windowsOffset = 15
@@ -108,6 +116,23 @@ type Player struct {
 	ScaledHeight float64
 
 	nightcore *common.NightcoreProcessor
+
+	ruleset    *osu.OsuRuleSet
+	breakIndex int
+
+	// Storyboard's pass/fail state evaluated at the start of each passed break
+	passingHistory []bool
+
+	judgementLog *osu.JudgementLog
+
+	seeker  *timelineSeeker
+	stepper *frameStepper
+
+	inspector *common.JudgementInspector
+
+	playfield playfields.IPlayfield
+
+	layerHook func(layer Layer)
 }
 
 func NewPlayer(beatMap *beatmap.BeatMap) *Player {
@@ -217,23 +242,41 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 
 	player.bMap.Reset()
 
+	mode := beatMap.Mode
+	if mode == beatmap.ModeOsu {
+		mode = settings.PLAYMODE
+	}
+
 	if settings.PLAY {
 		player.controller = dance.NewPlayerController()
 
 		player.controller.SetBeatMap(player.bMap)
 		player.controller.InitCursors()
-		player.overlay = overlays.NewScoreOverlay(player.controller.(*dance.PlayerController).GetRuleset(), player.controller.GetCursors()[0])
-	} else if settings.KNOCKOUT {
+		player.ruleset = player.controller.(*dance.PlayerController).GetRuleset()
+		player.overlay = overlays.NewScoreOverlay(player.ruleset, player.controller.GetCursors()[0])
+	} else if settings.KNOCKOUT || mode != beatmap.ModeOsu {
 		controller := dance.NewReplayController()
 		player.controller = controller
 
 		player.controller.SetBeatMap(player.bMap)
 		player.controller.InitCursors()
 
-		if settings.PLAYERS == 1 {
-			player.overlay = overlays.NewScoreOverlay(player.controller.(*dance.ReplayController).GetRuleset(), player.controller.GetCursors()[0])
-		} else {
-			player.overlay = overlays.NewKnockoutOverlay(controller.(*dance.ReplayController))
+		// Playfields of other game modes show the first player
+		switch ruleset := controller.(*dance.ReplayController).GetModeRuleset().(type) {
+		case *mania.ManiaRuleset:
+			player.playfield = playfields.NewManiaPlayfield(ruleset, player.ScaledWidth, player.ScaledHeight)
+		case *taiko.TaikoRuleset:
+			player.playfield = playfields.NewTaikoPlayfield(ruleset, player.ScaledWidth, player.ScaledHeight)
+		case *catch.CatchRuleset:
+			player.playfield = playfields.NewCatchPlayfield(ruleset, player.ScaledWidth, player.ScaledHeight)
+		default:
+			player.ruleset = controller.(*dance.ReplayController).GetRuleset()
+
+			if settings.PLAYERS == 1 {
+				player.overlay = overlays.NewScoreOverlay(player.controller.(*dance.ReplayController).GetRuleset(), player.controller.GetCursors()[0])
+			} else {
+				player.overlay = overlays.NewKnockoutOverlay(controller.(*dance.ReplayController))
+			}
 		}
 	} else {
 		player.controller = dance.NewGenericController()
@@ -241,6 +284,14 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 		player.controller.InitCursors()
 	}
 
+	if player.ruleset != nil && settings.Gameplay.JudgementInspector.Show {
+		player.inspector = common.NewJudgementInspector(player.ruleset, player.controller.GetCursors()[0])
+	}
+
+	if player.ruleset != nil && settings.Recording.JudgementLog != "none" {
+		player.judgementLog = osu.NewJudgementLog(player.ruleset, player.controller.GetCursors())
+	}
+
 	player.lastTime = -1
 
 	player.objectContainer = containers.NewHitObjectContainer(beatMap)
@@ -253,6 +304,20 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 	player.speedGlider = animation.NewGlider(settings.SPEED)
 	player.pitchGlider = animation.NewGlider(settings.PITCH)
 
+	if lazerMods := beatMap.Diff.LazerMods; lazerMods != nil && (lazerMods.WindUp || lazerMods.WindDown) {
+		// Like in osu!lazer, final rate is reached at 75% of the map
+		rampStart := beatMap.HitObjects[0].GetStartTime()
+		rampEnd := rampStart + (beatMap.HitObjects[len(beatMap.HitObjects)-1].GetEndTime()-rampStart)*0.75
+
+		player.speedGlider.SetValue(settings.SPEED * lazerMods.InitialRate)
+		player.speedGlider.AddEventS(rampStart, rampEnd, settings.SPEED*lazerMods.InitialRate, settings.SPEED*lazerMods.FinalRate)
+
+		if lazerMods.AdjustPitch {
+			player.pitchGlider.SetValue(settings.PITCH * lazerMods.InitialRate)
+			player.pitchGlider.AddEventS(rampStart, rampEnd, settings.PITCH*lazerMods.InitialRate, settings.PITCH*lazerMods.FinalRate)
+		}
+	}
+
 	player.hudGlider = animation.NewGlider(0)
 	player.hudGlider.SetEasing(easing.OutQuad)
 
@@ -373,6 +438,11 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 		}
 	}
 
+	if controller, ok := player.controller.(*dance.ReplayController); ok && controller.GetRuleset() != nil && !settings.RECORD {
+		player.seeker = newTimelineSeeker(player, controller, beatmapEnd)
+		player.stepper = newFrameStepper(player, player.seeker, controller)
+	}
+
 	player.dimGlider.AddEvent(beatmapEnd, beatmapEnd+fadeOut, 0.0)
 	player.fxGlider.AddEvent(beatmapEnd, beatmapEnd+fadeOut, 0.0)
 	player.cursorGlider.AddEvent(beatmapEnd, beatmapEnd+fadeOut, 0.0)
@@ -508,6 +578,10 @@ func NewPlayer(beatMap *beatmap.BeatMap) *Player {
 
 			player.updateMain(delta)
 
+			if player.progressMsF >= player.MapEnd {
+				player.saveJudgementLog()
+			}
+
 			lastTimeNano = currentTimeNano
 
 			player.updateLimiter.Sync()
@@ -539,6 +613,8 @@ func (player *Player) Update(delta float64) bool {
 		player.musicPlayer.Stop()
 		bass.StopLoops()
 
+		player.saveJudgementLog()
+
 		return true
 	}
 
@@ -572,6 +648,11 @@ func (player *Player) updateMain(delta float64) {
 		player.start = true
 	}
 
+	if player.seeker != nil {
+		player.seeker.preUpdate()
+		player.stepper.update()
+	}
+
 	player.speedGlider.Update(player.progressMsF)
 	player.pitchGlider.Update(player.progressMsF)
 
@@ -583,7 +664,11 @@ func (player *Player) updateMain(delta float64) {
 			player.bMap.Update(player.progressMsF)
 		}
 
-		player.objectContainer.Update(player.progressMsF)
+		if player.playfield != nil {
+			player.playfield.Update(player.progressMsF)
+		} else {
+			player.objectContainer.Update(player.progressMsF)
+		}
 	}
 
 	if player.progressMsF >= player.startPointE || settings.PLAY {
@@ -600,6 +685,14 @@ func (player *Player) updateMain(delta float64) {
 			player.controller.Update(player.bMap.HitObjects[len(player.bMap.HitObjects)-1].GetEndTime()+float64(player.bMap.Diff.Hit50)+100, delta)
 		}
 
+		if player.seeker != nil {
+			player.seeker.postUpdate()
+		}
+
+		if player.inspector != nil {
+			player.inspector.Update(player.progressMsF)
+		}
+
 		if player.lateStart {
 			if player.overlay != nil {
 				player.overlay.Update(player.progressMsF)
@@ -613,6 +706,8 @@ func (player *Player) updateMain(delta float64) {
 
 	player.updateMusic(delta)
 
+	player.updateStoryboardState()
+
 	player.coin.Update(player.progressMsF)
 	player.coin.SetAlpha(float32(player.fxGlider.GetValue()))
 
@@ -640,6 +735,84 @@ func (player *Player) updateMain(delta float64) {
 	}
 }
 
+func (player *Player) saveJudgementLog() {
+	if player.judgementLog == nil {
+		return
+	}
+
+	name := ffmpeg.GetOutputName()
+	if !settings.RECORD || name == "" {
+		name = "danser_" + time.Now().Format("2006-01-02_15-04-05")
+	}
+
+	if err := os.MkdirAll(settings.Recording.GetOutputDir(), 0755); err != nil {
+		log.Println("Failed to create output directory:", err)
+	}
+
+	player.judgementLog.Save(filepath.Join(settings.Recording.GetOutputDir(), name), settings.Recording.JudgementLog)
+	player.judgementLog = nil
+}
+
+// updateStoryboardState evaluates player's pass/fail state at the start of each break, like stable does
+func (player *Player) updateStoryboardState() {
+	storyboard := player.background.GetStoryboard()
+	if storyboard == nil || player.ruleset == nil {
+		return
+	}
+
+	for player.breakIndex < len(player.bMap.Pauses) && player.progressMsF >= player.bMap.Pauses[player.breakIndex].GetStartTime() {
+		passing := player.ruleset.GetHP(player.getStoryboardCursor()) >= 0.5
+
+		storyboard.SetPassing(passing)
+		player.passingHistory = append(player.passingHistory, passing)
+
+		player.breakIndex++
+	}
+}
+
+// rewindStoryboard moves the storyboard back to the given time, restoring pass/fail state evaluated at the last break before it
+func (player *Player) rewindStoryboard(time float64) {
+	player.breakIndex = 0
+	for player.breakIndex < len(player.bMap.Pauses) && time >= player.bMap.Pauses[player.breakIndex].GetStartTime() {
+		player.breakIndex++
+	}
+
+	storyboard := player.background.GetStoryboard()
+	if storyboard == nil {
+		return
+	}
+
+	passing := true
+
+	if count := mutils.Min(player.breakIndex, len(player.passingHistory)); count > 0 {
+		passing = player.passingHistory[count-1]
+		player.passingHistory = player.passingHistory[:count]
+	} else {
+		player.passingHistory = player.passingHistory[:0]
+	}
+
+	storyboard.Rewind(time, passing)
+}
+
+// getStoryboardCursor returns the cursor selected by Playfield.Background.PassFailPlayer
+func (player *Player) getStoryboardCursor() *graphics.Cursor {
+	cursors := player.controller.GetCursors()
+
+	if index := settings.Playfield.Background.PassFailPlayer; index > 0 {
+		return cursors[mutils.Min(index, len(cursors))-1]
+	}
+
+	best := cursors[0]
+
+	for _, c := range cursors[1:] {
+		if player.ruleset.GetScore(c).Score > player.ruleset.GetScore(best).Score {
+			best = c
+		}
+	}
+
+	return best
+}
+
 func (player *Player) updateMusic(delta float64) {
 	player.musicPlayer.Update()
 
@@ -686,6 +859,8 @@ func (player *Player) Draw(float64) {
 		bgAlpha = mutils.ClampF(bgAlpha*player.Scl, 0, 1)
 	}
 
+	player.setLayer(LayerBackground)
+
 	player.background.Draw(player.progressMsF, player.batch, player.blurGlider.GetValue(), bgAlpha, player.bgCamera.GetProjectionView())
 
 	if player.progressMsF > 0 {
@@ -697,9 +872,12 @@ func (player *Player) Draw(float64) {
 	cursorColors := settings.Cursor.GetColors(settings.DIVIDES, len(player.controller.GetCursors()), player.Scl, player.cursorGlider.GetValue())
 
 	if player.overlay != nil {
+		player.setLayer(player.overlayLayer())
 		player.drawOverlayPart(player.overlay.DrawBackground, cursorColors, cameras[0])
 	}
 
+	player.setLayer(LayerHUD)
+
 	player.drawEpilepsyWarning()
 
 	player.counter += timMs
@@ -728,22 +906,42 @@ func (player *Player) Draw(float64) {
 	}
 
 	if player.overlay != nil {
+		player.setLayer(player.overlayLayer())
 		player.drawOverlayPart(player.overlay.DrawBeforeObjects, cursorColors, cameras[0])
 	}
 
-	player.objectContainer.Draw(player.batch, cameras, player.progressMsF, float32(player.Scl), float32(player.objectsAlpha.GetValue()))
+	player.setLayer(LayerObjects)
+
+	if player.playfield != nil {
+		player.drawOverlayPart(func(batch *batch2.QuadBatch, colors []color2.Color, _ float64) {
+			player.playfield.Draw(batch, colors, player.objectsAlpha.GetValue()*player.hudGlider.GetValue())
+		}, cursorColors, player.uiCamera.GetProjectionView())
+	} else {
+		player.objectContainer.Draw(player.batch, cameras, player.progressMsF, float32(player.Scl), float32(player.objectsAlpha.GetValue()))
+	}
+
+	if player.inspector != nil {
+		player.setLayer(LayerHUD)
+		player.drawOverlayPart(player.inspector.Draw, cursorColors, cameras[0])
+	}
 
 	if player.overlay != nil {
+		player.setLayer(player.overlayLayer())
 		player.drawOverlayPart(player.overlay.DrawNormal, cursorColors, cameras[0])
 	}
 
+	player.setLayer(LayerBackground)
+
 	player.background.DrawOverlay(player.progressMsF, player.batch, bgAlpha, player.bgCamera.GetProjectionView())
 
 	if player.overlay != nil && player.overlay.ShouldDrawHUDBeforeCursor() {
+		player.setLayer(player.overlayLayer())
 		player.drawOverlayPart(player.overlay.DrawHUD, cursorColors, player.uiCamera.GetProjectionView())
 	}
 
-	if settings.Playfield.DrawCursors {
+	if settings.Playfield.DrawCursors && player.playfield == nil {
+		player.setLayer(LayerCursors)
+
 		for _, g := range player.controller.GetCursors() {
 			g.UpdateRenderer()
 		}
@@ -780,6 +978,7 @@ func (player *Player) Draw(float64) {
 	player.batch.SetAdditive(false)
 
 	if player.overlay != nil && !player.overlay.ShouldDrawHUDBeforeCursor() {
+		player.setLayer(player.overlayLayer())
 		player.drawOverlayPart(player.overlay.DrawHUD, cursorColors, player.uiCamera.GetProjectionView())
 	}
 
@@ -787,6 +986,13 @@ func (player *Player) Draw(float64) {
 		player.bloomEffect.EndAndRender()
 	}
 
+	player.setLayer(LayerHUD)
+
+	if player.seeker != nil {
+		player.seeker.draw(player.batch, player.hudGlider.GetValue())
+		player.stepper.draw(player.batch)
+	}
+
 	player.drawDebug()
 }
 
@@ -970,4 +1176,13 @@ func (player *Player) Show() {}
 
 func (player *Player) Hide() {}
 
-func (player *Player) Dispose() {}
+func (player *Player) Dispose() {
+	switch controller := player.controller.(type) {
+	case *dance.PlayerController:
+		controller.Stop()
+	case *dance.GenericController:
+		controller.Stop()
+	}
+
+	player.background.Dispose()
+}
@@ -0,0 +1,201 @@
+package states
+
+import (
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/wieku/danser-go/app/audio"
+	"github.com/wieku/danser-go/app/dance"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/input"
+	"github.com/wieku/danser-go/app/settings"
+	"github.com/wieku/danser-go/app/states/components/containers"
+	batch2 "github.com/wieku/danser-go/framework/graphics/batch"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+)
+
+const (
+	seekStep = 5000.0
+
+	seekBarHeight      = 6.0
+	seekBarHoverHeight = 12.0
+	seekBarHitArea     = 30.0
+)
+
+// timelineSeeker lets the user move replays back and forth in time with arrow keys or by dragging the progress bar.
+// Hit objects, ruleset, overlays and storyboard are moved back, background effects keep going forward.
+type timelineSeeker struct {
+	player     *Player
+	controller *dance.ReplayController
+
+	beatmapEnd float64
+
+	leftPressed  bool
+	rightPressed bool
+
+	dragging  bool
+	hovered   bool
+	lastDragX float64
+
+	catchingUp bool
+	target     float64
+}
+
+func newTimelineSeeker(player *Player, controller *dance.ReplayController, beatmapEnd float64) *timelineSeeker {
+	return &timelineSeeker{
+		player:     player,
+		controller: controller,
+		beatmapEnd: beatmapEnd,
+		lastDragX:  -1,
+	}
+}
+
+// preUpdate polls the input and moves the playback to the requested time, it has to be called before controller's update
+func (seeker *timelineSeeker) preUpdate() {
+	if !seeker.player.start || !seeker.controller.CanRewind() {
+		return
+	}
+
+	target := math.NaN()
+
+	left := input.Win.GetKey(glfw.KeyLeft) == glfw.Press
+	right := input.Win.GetKey(glfw.KeyRight) == glfw.Press
+
+	if left && !seeker.leftPressed {
+		target = seeker.player.progressMsF - seekStep
+	} else if right && !seeker.rightPressed {
+		target = seeker.player.progressMsF + seekStep
+	}
+
+	seeker.leftPressed = left
+	seeker.rightPressed = right
+
+	wWidth, wHeight := input.Win.GetSize()
+	mX, mY := input.Win.GetCursorPos()
+
+	pressed := input.Win.GetMouseButton(glfw.MouseButtonLeft) == glfw.Press
+
+	seeker.hovered = mY >= float64(wHeight)*(1-seekBarHitArea/seeker.player.ScaledHeight) && mY <= float64(wHeight)
+
+	if pressed && (seeker.dragging || seeker.hovered) {
+		seeker.dragging = true
+
+		if mX != seeker.lastDragX {
+			seeker.lastDragX = mX
+
+			progress := mutils.ClampF(mX/float64(wWidth), 0, 1)
+			target = seeker.getStartTime() + progress*(seeker.player.mapEndL-seeker.getStartTime())
+		}
+	} else {
+		seeker.dragging = false
+		seeker.lastDragX = -1
+	}
+
+	if !math.IsNaN(target) {
+		seeker.seek(target)
+	}
+}
+
+// postUpdate restores audio after objects skipped during seeking have been processed
+func (seeker *timelineSeeker) postUpdate() {
+	if !seeker.catchingUp {
+		return
+	}
+
+	for _, o := range seeker.player.bMap.HitObjects {
+		if o.GetEndTime() <= seeker.target || (!math.IsInf(settings.END, 1) && o.GetEndTime() > seeker.beatmapEnd) {
+			continue
+		}
+
+		o.DisableAudioSubmission(false)
+	}
+
+	if seeker.player.overlay != nil {
+		seeker.player.overlay.DisableAudioSubmission(false)
+	}
+
+	seeker.catchingUp = false
+}
+
+func (seeker *timelineSeeker) seek(target float64) {
+	player := seeker.player
+
+	target = mutils.ClampF(target, seeker.getStartTime(), player.mapEndL-1)
+
+	current := player.progressMsF
+
+	if math.Abs(target-current) < 1 {
+		return
+	}
+
+	// Rebuilding hit object visuals and overlay sprites touches GL resources
+	mainthread.Call(func() {
+		seeker.controller.Rewind(target)
+
+		if target < current {
+			player.bMap.Rewind(target)
+			player.objectContainer = containers.NewHitObjectContainer(player.bMap)
+
+			audio.StopSliderLoops()
+		}
+	})
+
+	// Objects and combo breaks between the snapshot and the target are processed in one go, so they shouldn't make any sounds
+	for _, o := range player.bMap.HitObjects {
+		o.DisableAudioSubmission(true)
+	}
+
+	if player.overlay != nil {
+		player.overlay.DisableAudioSubmission(true)
+	}
+
+	offset := player.progressMsF - player.rawPositionF
+
+	player.rawPositionF = target - offset
+	player.progressMsF = target
+
+	player.musicPlayer.SetPosition(player.rawPositionF / 1000)
+
+	// Breaks passed again have to re-evaluate storyboard's pass/fail state
+	if target < current {
+		player.rewindStoryboard(target)
+	}
+
+	seeker.target = target
+	seeker.catchingUp = true
+}
+
+func (seeker *timelineSeeker) getStartTime() float64 {
+	return math.Max(seeker.player.startPoint, seeker.controller.GetFirstSnapshotTime())
+}
+
+func (seeker *timelineSeeker) draw(batch *batch2.QuadBatch, alpha float64) {
+	player := seeker.player
+
+	if alpha < 0.01 {
+		return
+	}
+
+	startTime := seeker.getStartTime()
+	progress := mutils.ClampF((player.progressMsF-startTime)/(player.mapEndL-startTime), 0, 1)
+
+	height := seekBarHeight
+	if seeker.hovered || seeker.dragging {
+		height = seekBarHoverHeight
+	}
+
+	batch.Begin()
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, 1)
+	batch.SetCamera(player.uiCamera.GetProjectionView())
+
+	pos := vector.NewVec2d(0, player.ScaledHeight)
+
+	batch.DrawStObject(pos, vector.BottomLeft, vector.NewVec2d(player.ScaledWidth, height), false, false, 0, color2.NewLA(0, float32(0.5*alpha)), false, graphics.Pixel.GetRegion())
+	batch.DrawStObject(pos, vector.BottomLeft, vector.NewVec2d(player.ScaledWidth*progress, height), false, false, 0, color2.NewLA(1, float32(0.8*alpha)), false, graphics.Pixel.GetRegion())
+
+	batch.End()
+	batch.ResetTransform()
+}
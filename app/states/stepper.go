@@ -0,0 +1,213 @@
+package states
+
+import (
+	"fmt"
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/wieku/danser-go/app/beatmap/objects"
+	"github.com/wieku/danser-go/app/dance"
+	"github.com/wieku/danser-go/app/graphics"
+	"github.com/wieku/danser-go/app/input"
+	"github.com/wieku/danser-go/app/replay"
+	batch2 "github.com/wieku/danser-go/framework/graphics/batch"
+	color2 "github.com/wieku/danser-go/framework/math/color"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"github.com/wieku/rplpa"
+	"strings"
+)
+
+// frameStepper pauses replays and moves them frame by frame, showing replay data and the object ruleset is waiting for.
+// P pauses, Period/Comma step to the next/previous replay frame of inspected player (one millisecond with Shift), Tab changes inspected player.
+type frameStepper struct {
+	player     *Player
+	seeker     *timelineSeeker
+	controller *dance.ReplayController
+
+	paused    bool
+	inspected int
+
+	keyStates map[glfw.Key]bool
+}
+
+func newFrameStepper(player *Player, seeker *timelineSeeker, controller *dance.ReplayController) *frameStepper {
+	return &frameStepper{
+		player:     player,
+		seeker:     seeker,
+		controller: controller,
+		keyStates:  make(map[glfw.Key]bool),
+	}
+}
+
+// update polls the input, it has to be called before controller's update
+func (stepper *frameStepper) update() {
+	if !stepper.player.start {
+		return
+	}
+
+	if stepper.pressed(glfw.KeyP) {
+		stepper.togglePause()
+	}
+
+	if stepper.pressed(glfw.KeyTab) {
+		stepper.inspected = (stepper.inspected + 1) % len(stepper.controller.GetCursors())
+
+		if stepper.player.inspector != nil {
+			stepper.player.inspector.SetCursor(stepper.controller.GetCursors()[stepper.inspected])
+		}
+	}
+
+	forward := stepper.pressed(glfw.KeyPeriod)
+	backward := stepper.pressed(glfw.KeyComma)
+
+	if !stepper.paused || (!forward && !backward) {
+		return
+	}
+
+	fine := input.Win.GetKey(glfw.KeyLeftShift) == glfw.Press || input.Win.GetKey(glfw.KeyRightShift) == glfw.Press
+
+	current := stepper.player.progressMsF
+	target := current
+
+	info, ok := stepper.controller.GetReplayFrame(stepper.inspected)
+
+	switch {
+	case fine || !ok:
+		target = float64(int64(current))
+
+		if forward {
+			target++
+		} else {
+			target--
+		}
+	case forward:
+		if info.NextTime < 0 {
+			return
+		}
+
+		target = float64(info.NextTime)
+	default:
+		// Current frame has been already processed, so going back means moving to the previous one
+		target = float64(info.PrevTime)
+	}
+
+	if target < current && !stepper.controller.CanRewind() {
+		return
+	}
+
+	// Music position is not precise to a millisecond, half a millisecond more guarantees the frame is processed
+	stepper.seeker.seek(target + 0.5)
+}
+
+func (stepper *frameStepper) pressed(key glfw.Key) bool {
+	state := input.Win.GetKey(key) == glfw.Press
+
+	wasPressed := stepper.keyStates[key]
+	stepper.keyStates[key] = state
+
+	return state && !wasPressed
+}
+
+func (stepper *frameStepper) togglePause() {
+	stepper.paused = !stepper.paused
+
+	if stepper.paused {
+		stepper.player.musicPlayer.Pause()
+	} else {
+		stepper.player.musicPlayer.Resume()
+	}
+}
+
+func (stepper *frameStepper) draw(batch *batch2.QuadBatch) {
+	if !stepper.paused {
+		return
+	}
+
+	player := stepper.player
+
+	cursor := stepper.controller.GetCursors()[stepper.inspected]
+
+	lines := []string{
+		fmt.Sprintf("PAUSED | %.0fms", player.progressMsF),
+		fmt.Sprintf("Player: %s (%d/%d)", cursor.Name, stepper.inspected+1, len(stepper.controller.GetCursors())),
+	}
+
+	if info, ok := stepper.controller.GetReplayFrame(stepper.inspected); ok && info.Frame != nil {
+		lines = append(lines,
+			fmt.Sprintf("Frame: #%d at %dms (delta %dms)", info.Index, info.Time, info.Frame.Time),
+			fmt.Sprintf("Position: %.2f, %.2f", info.Frame.MouseX, info.Frame.MouseY),
+			fmt.Sprintf("Keys: %s (%05b)", formatKeys(info.Frame.KeyPressed), replay.EncodeKeys(info.Frame.KeyPressed)),
+		)
+	} else {
+		lines = append(lines, "Frame: none")
+	}
+
+	if g := player.ruleset.GetCurrentObject(cursor); g != nil {
+		object := player.bMap.HitObjects[g.GetNumber()]
+
+		lines = append(lines, fmt.Sprintf("Object: #%d %s at %.0fms (%+.0fms)", g.GetNumber(), objectType(object), object.GetStartTime(), player.progressMsF-object.GetStartTime()))
+	} else {
+		lines = append(lines, "Object: none")
+	}
+
+	size := 16.0
+	padDown := 4.0
+
+	batch.Begin()
+	batch.ResetTransform()
+	batch.SetColor(1, 1, 1, 1)
+	batch.SetCamera(player.uiCamera.GetProjectionView())
+
+	baseY := player.ScaledHeight * 0.4
+
+	for i, line := range lines {
+		width := player.font.GetWidthMonospaced(size, line)
+		batch.DrawStObject(vector.NewVec2d(0, baseY+(size+padDown)*float64(i)), vector.CentreLeft, vector.NewVec2d(width, size+padDown), false, false, 0, color2.NewLA(0, 0.8), false, graphics.Pixel.GetRegion())
+	}
+
+	batch.ResetTransform()
+
+	for i, line := range lines {
+		player.font.DrawOrigin(batch, 0, baseY+(size+padDown)*float64(i), vector.CentreLeft, size, true, line)
+	}
+
+	batch.End()
+}
+
+func formatKeys(keys *rplpa.KeyPressed) string {
+	if keys == nil {
+		return "-"
+	}
+
+	names := make([]string, 0, 5)
+
+	add := func(pressed bool, name string) {
+		if pressed {
+			names = append(names, name)
+		}
+	}
+
+	// Key presses also set corresponding mouse bits
+	add(keys.LeftClick && !keys.Key1, "M1")
+	add(keys.RightClick && !keys.Key2, "M2")
+	add(keys.Key1, "K1")
+	add(keys.Key2, "K2")
+	add(keys.Smoke, "Smoke")
+
+	if len(names) == 0 {
+		return "-"
+	}
+
+	return strings.Join(names, " ")
+}
+
+func objectType(object objects.IHitObject) string {
+	switch object.(type) {
+	case *objects.Circle:
+		return "circle"
+	case *objects.Slider:
+		return "slider"
+	case *objects.Spinner:
+		return "spinner"
+	}
+
+	return "object"
+}
@@ -2,6 +2,7 @@ package storyboard
 
 import (
 	"fmt"
+	"github.com/wieku/danser-go/app/audio"
 	"github.com/wieku/danser-go/app/beatmap"
 	"github.com/wieku/danser-go/app/settings"
 	"github.com/wieku/danser-go/app/skin"
@@ -18,10 +19,12 @@ import (
 	"github.com/wieku/danser-go/framework/math/vector"
 	"github.com/wieku/danser-go/framework/qpc"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 type Storyboard struct {
@@ -31,6 +34,7 @@ type Storyboard struct {
 	samples map[string]*bass.Sample
 
 	background  *sprite.Manager
+	fail        *sprite.Manager
 	pass        *sprite.Manager
 	foreground  *sprite.Manager
 	overlay     *sprite.Manager
@@ -47,6 +51,38 @@ type Storyboard struct {
 
 	videos     []sprite.ISprite
 	videoAlpha float64
+
+	sprites     []*loadedSprite
+	updateMutex *sync.Mutex
+
+	triggered       []*triggeredSprite
+	triggerMutex    *sync.Mutex
+	pendingTriggers []triggerEvent
+	passing         bool
+
+	removeHitSoundListener func()
+}
+
+// loadedSprite keeps everything needed to bring a sprite back to its initial state when storyboard is rewound
+type loadedSprite struct {
+	sprite sprite.ISprite
+	layer  *sprite.Manager
+
+	transforms []*animation.Transformation
+	initial    []*animation.Transformation // Initial values of properties animated only by triggers
+}
+
+func (loaded *loadedSprite) reset() {
+	loaded.sprite.ClearTransformations()
+	loaded.sprite.AddTransforms(loaded.transforms)
+
+	if len(loaded.initial) > 0 {
+		loaded.sprite.AddTransforms(loaded.initial)
+		loaded.sprite.ResetValuesToTransforms()
+		loaded.sprite.RemoveTransformations(loaded.initial)
+	} else {
+		loaded.sprite.ResetValuesToTransforms()
+	}
 }
 
 func getSection(line string) string {
@@ -71,11 +107,16 @@ func NewStoryboard(beatMap *beatmap.BeatMap) *Storyboard {
 		samples:    make(map[string]*bass.Sample),
 		zIndex:     -1,
 		background: sprite.NewManager(),
+		fail:       sprite.NewManager(),
 		pass:       sprite.NewManager(),
 		foreground: sprite.NewManager(),
 		overlay:    sprite.NewManager(),
 		atlas:      nil,
 		videos:     make([]sprite.ISprite, 0),
+
+		updateMutex:  &sync.Mutex{},
+		triggerMutex: &sync.Mutex{},
+		passing:      true,
 	}
 
 	storyboard.pathCache, _ = files2.NewFileMap(path)
@@ -143,7 +184,7 @@ func NewStoryboard(beatMap *beatmap.BeatMap) *Storyboard {
 
 					sbSprite := sprite.NewAudioSprite(storyboard.getSample(sample), startTime, volume/100)
 
-					storyboard.addSpriteToLayer(spl[2], sbSprite)
+					storyboard.addSpriteToLayer(spl[2], &loadedSprite{sprite: sbSprite})
 
 					hasAudio = true
 				} else if settings.Playfield.Background.LoadVideos && (strings.HasPrefix(line, "Video") || strings.HasPrefix(line, "1")) {
@@ -161,12 +202,15 @@ func NewStoryboard(beatMap *beatmap.BeatMap) *Storyboard {
 					video.SetStartTime(offset)
 					video.ShowForever(false)
 
-					video.AddTransform(animation.NewSingleTransform(animation.Fade, easing.Linear, video.GetStartTime(), video.GetStartTime()+1000, 0, 1))
-					video.AddTransform(animation.NewSingleTransform(animation.Fade, easing.Linear, video.GetEndTime()-1000, video.GetEndTime(), 1, 0))
+					fades := []*animation.Transformation{
+						animation.NewSingleTransform(animation.Fade, easing.Linear, video.GetStartTime(), video.GetStartTime()+1000, 0, 1),
+						animation.NewSingleTransform(animation.Fade, easing.Linear, video.GetEndTime()-1000, video.GetEndTime(), 1, 0),
+					}
 
+					video.AddTransforms(fades)
 					video.ResetValuesToTransforms()
 
-					storyboard.background.Add(video)
+					storyboard.addSpriteToLayer("Background", &loadedSprite{sprite: video, transforms: fades})
 
 					storyboard.videos = append(storyboard.videos, video)
 
@@ -213,6 +257,10 @@ func NewStoryboard(beatMap *beatmap.BeatMap) *Storyboard {
 		}
 	}
 
+	if len(storyboard.triggered) > 0 {
+		storyboard.removeHitSoundListener = audio.AddHitSoundListener(storyboard.onHitSound)
+	}
+
 	log.Println("Storyboard loaded")
 
 	storyboard.currentTime = -1000000
@@ -269,30 +317,87 @@ func (storyboard *Storyboard) loadSprite(currentSprite string, commands []string
 	if len(textures) != 0 {
 		sbSprite := sprite.NewAnimation(textures, frameDelay, loopForever, float64(storyboard.zIndex), pos, origin)
 
-		transforms := parseCommands(commands)
+		transforms, triggers := parseCommands(commands)
 
 		sbSprite.ShowForever(false)
 		sbSprite.AddTransforms(transforms)
 		sbSprite.AdjustTimesToTransformations()
 		sbSprite.ResetValuesToTransforms()
 
-		storyboard.addSpriteToLayer(spl[1], sbSprite)
+		loaded := &loadedSprite{sprite: sbSprite, transforms: transforms}
+
+		if len(triggers) > 0 {
+			loaded.initial = storyboard.addTriggers(sbSprite, transforms, triggers)
+		}
+
+		storyboard.addSpriteToLayer(spl[1], loaded)
 
 		storyboard.numSprites++
 	}
 }
 
-func (storyboard *Storyboard) addSpriteToLayer(layer string, sbSprite sprite.ISprite) {
+// addTriggers registers sprite's triggers, returns transformations giving initial values to properties animated only by triggers
+func (storyboard *Storyboard) addTriggers(sbSprite sprite.ISprite, transforms []*animation.Transformation, triggers []*TriggerProcessor) []*animation.Transformation {
+	startTime := math.MaxFloat64
+	endTime := -math.MaxFloat64
+
+	if len(transforms) > 0 {
+		startTime = sbSprite.GetStartTime()
+		endTime = sbSprite.GetEndTime()
+	}
+
+	applied := make(map[animation.TransformationType]bool)
+	for _, t := range transforms {
+		applied[t.GetType()] = true
+	}
+
+	initial := make([]*animation.Transformation, 0)
+
+	for _, trigger := range triggers {
+		startTime = math.Min(startTime, trigger.GetStartTime())
+		endTime = math.Max(endTime, trigger.GetEndTime())
+
+		for _, t := range trigger.Activate(trigger.GetStartTime()) {
+			if !applied[t.GetType()] {
+				initial = append(initial, t)
+			}
+		}
+	}
+
+	// Properties not covered by regular commands take their initial values from triggered commands
+	if len(initial) > 0 {
+		sbSprite.AddTransforms(initial)
+		sbSprite.ResetValuesToTransforms()
+		sbSprite.RemoveTransformations(initial)
+	}
+
+	sbSprite.SetStartTime(startTime)
+	sbSprite.SetEndTime(endTime)
+
+	storyboard.triggered = append(storyboard.triggered, newTriggeredSprite(sbSprite, triggers))
+
+	return initial
+}
+
+func (storyboard *Storyboard) addSpriteToLayer(layer string, loaded *loadedSprite) {
 	switch layer {
 	case "0", "Background":
-		storyboard.background.Add(sbSprite)
+		loaded.layer = storyboard.background
+	case "1", "Fail":
+		loaded.layer = storyboard.fail
 	case "2", "Pass":
-		storyboard.pass.Add(sbSprite)
+		loaded.layer = storyboard.pass
 	case "3", "Foreground":
-		storyboard.foreground.Add(sbSprite)
+		loaded.layer = storyboard.foreground
 	case "4", "Overlay":
-		storyboard.overlay.Add(sbSprite)
+		loaded.layer = storyboard.overlay
+	default:
+		return
 	}
+
+	loaded.layer.Add(loaded.sprite)
+
+	storyboard.sprites = append(storyboard.sprites, loaded)
 }
 
 func (storyboard *Storyboard) getTexture(image string) *texture.TextureRegion {
@@ -379,6 +484,16 @@ func (storyboard *Storyboard) StopThread() {
 	storyboard.shouldRun = false
 }
 
+// Dispose stops the storyboard and unregisters it from hitsound events
+func (storyboard *Storyboard) Dispose() {
+	storyboard.StopThread()
+
+	if storyboard.removeHitSoundListener != nil {
+		storyboard.removeHitSoundListener()
+		storyboard.removeHitSoundListener = nil
+	}
+}
+
 func (storyboard *Storyboard) IsThreadRunning() bool {
 	return storyboard.shouldRun
 }
@@ -395,8 +510,102 @@ func (storyboard *Storyboard) SetFPS(i int) {
 	storyboard.limiter.FPS = i
 }
 
+func (storyboard *Storyboard) onHitSound(sampleSet, additionSet, hitsound, index int, _ int64) {
+	storyboard.queueTrigger(triggerEvent{
+		triggerType: HitSound,
+		sampleSet:   sampleSet,
+		additionSet: additionSet,
+		hitsound:    hitsound,
+		index:       index,
+	})
+}
+
+// SetPassing updates player's pass/fail state, firing Passing or Failing triggers when it changes
+func (storyboard *Storyboard) SetPassing(passing bool) {
+	if storyboard.passing == passing {
+		return
+	}
+
+	storyboard.passing = passing
+
+	if passing {
+		storyboard.queueTrigger(triggerEvent{triggerType: Passing})
+	} else {
+		storyboard.queueTrigger(triggerEvent{triggerType: Failing})
+	}
+}
+
+func (storyboard *Storyboard) IsPassing() bool {
+	return storyboard.passing
+}
+
+func (storyboard *Storyboard) queueTrigger(event triggerEvent) {
+	if len(storyboard.triggered) == 0 {
+		return
+	}
+
+	storyboard.triggerMutex.Lock()
+	storyboard.pendingTriggers = append(storyboard.pendingTriggers, event)
+	storyboard.triggerMutex.Unlock()
+}
+
+func (storyboard *Storyboard) processTriggers(time float64) {
+	storyboard.triggerMutex.Lock()
+
+	if len(storyboard.pendingTriggers) == 0 {
+		storyboard.triggerMutex.Unlock()
+		return
+	}
+
+	events := storyboard.pendingTriggers
+	storyboard.pendingTriggers = nil
+
+	storyboard.triggerMutex.Unlock()
+
+	for _, event := range events {
+		for _, ts := range storyboard.triggered {
+			ts.fire(event, time)
+		}
+	}
+}
+
+// Rewind moves the storyboard back to the given time with given pass/fail state.
+// Sprites are brought back to their initial state, so triggers fired before that time are forgotten.
+func (storyboard *Storyboard) Rewind(time float64, passing bool) {
+	storyboard.updateMutex.Lock()
+	defer storyboard.updateMutex.Unlock()
+
+	storyboard.triggerMutex.Lock()
+	storyboard.pendingTriggers = nil
+	storyboard.triggerMutex.Unlock()
+
+	for _, ts := range storyboard.triggered {
+		ts.reset()
+	}
+
+	storyboard.background.Clear()
+	storyboard.fail.Clear()
+	storyboard.pass.Clear()
+	storyboard.foreground.Clear()
+	storyboard.overlay.Clear()
+
+	for _, loaded := range storyboard.sprites {
+		loaded.reset()
+		loaded.layer.Add(loaded.sprite)
+	}
+
+	storyboard.passing = passing
+	storyboard.currentTime = time
+}
+
 func (storyboard *Storyboard) Update(time float64) {
+	storyboard.updateMutex.Lock()
+	defer storyboard.updateMutex.Unlock()
+
+	storyboard.processTriggers(time)
+
 	storyboard.background.Update(time)
+	storyboard.fail.Update(time)
 	storyboard.pass.Update(time)
 	storyboard.foreground.Update(time)
 	storyboard.overlay.Update(time)
@@ -415,7 +624,13 @@ func (storyboard *Storyboard) Update(time float64) {
 func (storyboard *Storyboard) Draw(time float64, batch *batch.QuadBatch) {
 	batch.SetTranslation(vector.NewVec2d(-64, -48))
 	storyboard.background.Draw(time, batch)
-	storyboard.pass.Draw(time, batch)
+
+	if storyboard.passing {
+		storyboard.pass.Draw(time, batch)
+	} else {
+		storyboard.fail.Draw(time, batch)
+	}
+
 	storyboard.foreground.Draw(time, batch)
 	batch.SetTranslation(vector.NewVec2d(0, 0))
 }
@@ -427,15 +642,15 @@ func (storyboard *Storyboard) DrawOverlay(time float64, batch *batch.QuadBatch)
 }
 
 func (storyboard *Storyboard) GetRenderedSprites() int {
-	return storyboard.background.GetNumRendered() + storyboard.pass.GetNumRendered() + storyboard.foreground.GetNumRendered() + storyboard.overlay.GetNumRendered()
+	return storyboard.background.GetNumRendered() + storyboard.fail.GetNumRendered() + storyboard.pass.GetNumRendered() + storyboard.foreground.GetNumRendered() + storyboard.overlay.GetNumRendered()
 }
 
 func (storyboard *Storyboard) GetProcessedSprites() int {
-	return storyboard.background.GetNumProcessed() + storyboard.pass.GetNumProcessed() + storyboard.foreground.GetNumProcessed() + storyboard.overlay.GetNumProcessed()
+	return storyboard.background.GetNumProcessed() + storyboard.fail.GetNumProcessed() + storyboard.pass.GetNumProcessed() + storyboard.foreground.GetNumProcessed() + storyboard.overlay.GetNumProcessed()
 }
 
 func (storyboard *Storyboard) GetQueueSprites() int {
-	return storyboard.background.GetNumInQueue() + storyboard.pass.GetNumInQueue() + storyboard.foreground.GetNumInQueue() + storyboard.overlay.GetNumInQueue()
+	return storyboard.background.GetNumInQueue() + storyboard.fail.GetNumInQueue() + storyboard.pass.GetNumInQueue() + storyboard.foreground.GetNumInQueue() + storyboard.overlay.GetNumInQueue()
 }
 
 func (storyboard *Storyboard) GetTotalSprites() int {
@@ -0,0 +1,252 @@
+package storyboard
+
+import (
+	"github.com/wieku/danser-go/framework/graphics/sprite"
+	"github.com/wieku/danser-go/framework/math/animation"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type TriggerType int
+
+const (
+	HitSound = TriggerType(iota)
+	Passing
+	Failing
+)
+
+var triggerSampleSets = map[string]int{
+	"All":    0,
+	"Normal": 1,
+	"Soft":   2,
+	"Drum":   3,
+}
+
+var triggerAdditions = map[string]int{
+	"Whistle": 2,
+	"Finish":  4,
+	"Clap":    8,
+}
+
+type TriggerProcessor struct {
+	triggerType TriggerType
+
+	sampleSet   int
+	additionSet int
+	addition    int
+	customIndex int
+
+	startTime, endTime float64
+	group              int64
+
+	transforms []*animation.Transformation
+	duration   float64
+}
+
+func NewTriggerProcessor(data []string) *TriggerProcessor {
+	trigger := &TriggerProcessor{
+		customIndex: -1,
+		endTime:     math.MaxFloat64,
+	}
+
+	checkError := func(err error) {
+		if err != nil {
+			log.Println("Failed to parse: ", data)
+			panic(err)
+		}
+	}
+
+	if len(data) < 2 {
+		log.Println("Failed to parse: ", data)
+		return nil
+	}
+
+	name := strings.TrimSpace(data[1])
+
+	switch {
+	case strings.HasPrefix(name, "HitSound"):
+		trigger.triggerType = HitSound
+		trigger.parseHitSoundFilter(strings.TrimPrefix(name, "HitSound"))
+	case name == "Passing":
+		trigger.triggerType = Passing
+	case name == "Failing":
+		trigger.triggerType = Failing
+	default:
+		log.Println("Unsupported storyboard trigger:", name)
+		return nil
+	}
+
+	var err error
+
+	if len(data) > 2 && data[2] != "" {
+		trigger.startTime, err = strconv.ParseFloat(data[2], 64)
+		checkError(err)
+	}
+
+	if len(data) > 3 && data[3] != "" {
+		trigger.endTime, err = strconv.ParseFloat(data[3], 64)
+		checkError(err)
+	}
+
+	if len(data) > 4 && data[4] != "" {
+		trigger.group, err = strconv.ParseInt(data[4], 10, 64)
+		checkError(err)
+	}
+
+	return trigger
+}
+
+// parseHitSoundFilter parses HitSound[SampleSet[AdditionsSampleSet]][AdditionsSound][CustomSampleSet] suffix
+func (trigger *TriggerProcessor) parseHitSoundFilter(filter string) {
+	setsParsed := 0
+
+	for filter != "" {
+		if unicode.IsDigit(rune(filter[0])) {
+			index, err := strconv.ParseInt(filter, 10, 32)
+			if err == nil {
+				trigger.customIndex = int(index)
+			}
+
+			return
+		}
+
+		end := 1
+		for end < len(filter) && unicode.IsLower(rune(filter[end])) {
+			end++
+		}
+
+		token := filter[:end]
+		filter = filter[end:]
+
+		if set, ok := triggerSampleSets[token]; ok && setsParsed < 2 {
+			if setsParsed == 0 {
+				trigger.sampleSet = set
+			} else {
+				trigger.additionSet = set
+			}
+
+			setsParsed++
+
+			continue
+		}
+
+		if addition, ok := triggerAdditions[token]; ok {
+			trigger.addition = addition
+		}
+	}
+}
+
+func (trigger *TriggerProcessor) Add(command []string) {
+	if parsed := parseCommand(command); parsed != nil {
+		trigger.transforms = append(trigger.transforms, parsed...)
+
+		for _, t := range parsed {
+			trigger.duration = math.Max(trigger.duration, t.GetEndTime())
+		}
+	}
+}
+
+func (trigger *TriggerProcessor) GetType() TriggerType {
+	return trigger.triggerType
+}
+
+func (trigger *TriggerProcessor) GetGroup() int64 {
+	return trigger.group
+}
+
+func (trigger *TriggerProcessor) GetStartTime() float64 {
+	return trigger.startTime
+}
+
+// GetEndTime returns the latest time transformations of this trigger can affect the sprite
+func (trigger *TriggerProcessor) GetEndTime() float64 {
+	return trigger.endTime + trigger.duration
+}
+
+func (trigger *TriggerProcessor) IsActive(time float64) bool {
+	return time >= trigger.startTime && time <= trigger.endTime
+}
+
+func (trigger *TriggerProcessor) MatchesHitSound(sampleSet, additionSet, hitsound, index int) bool {
+	if trigger.triggerType != HitSound {
+		return false
+	}
+
+	if trigger.sampleSet > 0 && trigger.sampleSet != sampleSet {
+		return false
+	}
+
+	if trigger.additionSet > 0 && trigger.additionSet != additionSet {
+		return false
+	}
+
+	if trigger.addition > 0 && hitsound&trigger.addition == 0 {
+		return false
+	}
+
+	return trigger.customIndex < 0 || trigger.customIndex == index
+}
+
+// Activate returns transformations shifted to the given trigger time
+func (trigger *TriggerProcessor) Activate(time float64) []*animation.Transformation {
+	transforms := make([]*animation.Transformation, 0, len(trigger.transforms))
+
+	for _, t := range trigger.transforms {
+		transforms = append(transforms, t.Clone(time+t.GetStartTime(), time+t.GetEndTime()))
+	}
+
+	return transforms
+}
+
+type triggerEvent struct {
+	triggerType TriggerType
+
+	sampleSet   int
+	additionSet int
+	hitsound    int
+	index       int
+}
+
+type triggeredSprite struct {
+	sprite   sprite.ISprite
+	triggers []*TriggerProcessor
+	active   map[int64][]*animation.Transformation
+}
+
+func newTriggeredSprite(sbSprite sprite.ISprite, triggers []*TriggerProcessor) *triggeredSprite {
+	return &triggeredSprite{
+		sprite:   sbSprite,
+		triggers: triggers,
+		active:   make(map[int64][]*animation.Transformation),
+	}
+}
+
+// reset forgets transformations started by previous activations, they're removed from the sprite when it's rewound
+func (ts *triggeredSprite) reset() {
+	ts.active = make(map[int64][]*animation.Transformation)
+}
+
+func (ts *triggeredSprite) fire(event triggerEvent, time float64) {
+	for _, trigger := range ts.triggers {
+		if trigger.GetType() != event.triggerType || !trigger.IsActive(time) {
+			continue
+		}
+
+		if event.triggerType == HitSound && !trigger.MatchesHitSound(event.sampleSet, event.additionSet, event.hitsound, event.index) {
+			continue
+		}
+
+		// Triggers in the same group cancel transformations started by previous activations
+		if previous := ts.active[trigger.GetGroup()]; previous != nil {
+			ts.sprite.RemoveTransformations(previous)
+		}
+
+		transforms := trigger.Activate(time)
+
+		ts.sprite.AddTransforms(transforms)
+		ts.active[trigger.GetGroup()] = transforms
+	}
+}
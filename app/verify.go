@@ -0,0 +1,49 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/wieku/danser-go/app/beatmap"
+	"github.com/wieku/danser-go/app/beatmap/difficulty"
+	"github.com/wieku/danser-go/app/replay"
+	"io"
+	"log"
+	"os"
+)
+
+// runVerification checks replays against danser's ruleset without initializing GLFW and BASS, never returns
+func runVerification(beatMap *beatmap.BeatMap, mods difficulty.Modifier, replays []string) {
+	// Report goes to stdout, so logs have to be moved elsewhere
+	log.SetOutput(io.MultiWriter(os.Stderr, logFile))
+
+	if beatMap == nil {
+		log.Println("Beatmap for verification not found")
+		os.Exit(2)
+	}
+
+	beatMap.Diff.SetMods(mods)
+	beatmap.ParseTimingPointsAndPauses(beatMap)
+	beatmap.ParseObjects(beatMap, false, false)
+
+	if len(beatMap.HitObjects) == 0 {
+		log.Println("Beatmap doesn't have any hit objects")
+		os.Exit(2)
+	}
+
+	report := replay.Verify(beatMap, replays)
+
+	data, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(data))
+
+	if !report.Match {
+		log.Println("Verification failed: computed scores don't match scores stored in replays")
+		os.Exit(1)
+	}
+
+	log.Println("Verification successful")
+	os.Exit(0)
+}
@@ -30,11 +30,16 @@ type Framebuffer struct {
 
 // NewFrame creates a new fully transparent Framebuffer with given dimensions in pixels.
 func NewFrame(width, height int, smooth, depth bool) *Framebuffer {
+	return NewFrameFormat(width, height, texture.RGBA, depth)
+}
+
+// NewFrameFormat creates a new fully transparent Framebuffer with given dimensions in pixels and texture format.
+func NewFrameFormat(width, height int, format texture.Format, depth bool) *Framebuffer {
 	f := new(Framebuffer)
 	f.width = width
 	f.height = height
 
-	f.tex = texture.NewTextureSingle(width, height, 0)
+	f.tex = texture.NewTextureSingleFormat(width, height, format, 0)
 
 	gl.CreateFramebuffers(1, &f.handle)
 
@@ -100,6 +105,10 @@ func NewFrameDepth(width, height int, smooth bool) *Framebuffer {
 }
 
 func NewFrameMultisample(width, height int, samples int) *Framebuffer {
+	return NewFrameMultisampleFormat(width, height, samples, texture.RGBA)
+}
+
+func NewFrameMultisampleFormat(width, height int, samples int, format texture.Format) *Framebuffer {
 	f := new(Framebuffer)
 	f.width = width
 	f.height = height
@@ -109,10 +118,10 @@ func NewFrameMultisample(width, height int, samples int) *Framebuffer {
 
 
 	gl.CreateRenderbuffers(1, &f.texRenderbuffer)
-	gl.NamedRenderbufferStorageMultisample(f.texRenderbuffer, int32(samples), texture.RGBA.InternalFormat(), int32(width), int32(height))
+	gl.NamedRenderbufferStorageMultisample(f.texRenderbuffer, int32(samples), format.InternalFormat(), int32(width), int32(height))
 	gl.NamedFramebufferRenderbuffer(f.handle, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, f.texRenderbuffer)
 
-	f.tex = texture.NewTextureSingle(width, height, 0)
+	f.tex = texture.NewTextureSingleFormat(width, height, format, 0)
 
 	gl.CreateFramebuffers(1, &f.helperHandle)
 	gl.NamedFramebufferTextureLayer(f.helperHandle, gl.COLOR_ATTACHMENT0, f.tex.GetID(), 0, 0)
@@ -123,6 +132,10 @@ func NewFrameMultisample(width, height int, samples int) *Framebuffer {
 }
 
 func NewFrameMultisampleScreen(width, height int, depth bool, samples int) *Framebuffer {
+	return NewFrameMultisampleScreenFormat(width, height, depth, samples, texture.RGBA)
+}
+
+func NewFrameMultisampleScreenFormat(width, height int, depth bool, samples int, format texture.Format) *Framebuffer {
 	f := new(Framebuffer)
 	f.width = width
 	f.height = height
@@ -131,7 +144,7 @@ func NewFrameMultisampleScreen(width, height int, depth bool, samples int) *Fram
 	gl.CreateFramebuffers(1, &f.handle)
 
 	gl.CreateRenderbuffers(1, &f.texRenderbuffer)
-	gl.NamedRenderbufferStorageMultisample(f.texRenderbuffer, int32(samples), texture.RGBA.InternalFormat(), int32(width), int32(height))
+	gl.NamedRenderbufferStorageMultisample(f.texRenderbuffer, int32(samples), format.InternalFormat(), int32(width), int32(height))
 	gl.NamedFramebufferRenderbuffer(f.handle, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, f.texRenderbuffer)
 
 	if depth {
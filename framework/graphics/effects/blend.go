@@ -21,6 +21,10 @@ type Blend struct {
 }
 
 func NewBlend(width, height, frames int, weights []float32) *Blend {
+	return NewBlendFormat(width, height, frames, weights, texture.RGB)
+}
+
+func NewBlendFormat(width, height, frames int, weights []float32, format texture.Format) *Blend {
 	if frames != len(weights) {
 		panic("Wrong number of weights")
 	}
@@ -71,7 +75,7 @@ func NewBlend(width, height, frames int, weights []float32) *Blend {
 		effect.blendShader.SetUniformArr("weights", i, v/sum)
 	}
 
-	effect.multiTexture = texture.NewTextureMultiLayerFormat(width, height, texture.RGB, 0, frames)
+	effect.multiTexture = texture.NewTextureMultiLayerFormat(width, height, format, 0, frames)
 
 	for i := 0; i < frames; i++ {
 		effect.fbos = append(effect.fbos, buffer.NewFrameLayer(effect.multiTexture, i))
@@ -96,7 +96,7 @@ func (effect *BloomEffect) EndAndRender() {
 
 	blend.Push()
 	blend.Enable()
-	blend.SetFunction(blend.SrcAlpha, blend.OneMinusSrcAlpha)
+	blend.SetFunctionSeparate(blend.SrcAlpha, blend.OneMinusSrcAlpha, blend.One, blend.OneMinusSrcAlpha)
 
 	effect.blurEffect.Begin()
 	gl.ClearColor(0, 0, 0, 0)
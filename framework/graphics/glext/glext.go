@@ -0,0 +1,23 @@
+package glext
+
+import (
+	"github.com/go-gl/gl/v3.3-core/gl"
+)
+
+var extensions map[string]bool
+
+// ExtensionSupported checks if current OpenGL context supports the extension, unlike glfw.ExtensionSupported it doesn't need a window
+func ExtensionSupported(extension string) bool {
+	if extensions == nil {
+		extensions = make(map[string]bool)
+
+		var count int32
+		gl.GetIntegerv(gl.NUM_EXTENSIONS, &count)
+
+		for i := int32(0); i < count; i++ {
+			extensions[gl.GoStr(gl.GetStringi(gl.EXTENSIONS, uint32(i)))] = true
+		}
+	}
+
+	return extensions[extension]
+}
@@ -0,0 +1,182 @@
+package headless
+
+/*
+#cgo LDFLAGS: -lEGL
+
+#include <stdlib.h>
+#include <string.h>
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+
+static int hasExtension(EGLDisplay display, const char* name) {
+	const char* extensions = eglQueryString(display, EGL_EXTENSIONS);
+	return extensions != NULL && strstr(extensions, name) != NULL;
+}
+
+// Prefer Mesa's surfaceless platform as it doesn't need X11/Wayland or DRM device access
+static EGLDisplay getDisplay() {
+	if (hasExtension(EGL_NO_DISPLAY, "EGL_MESA_platform_surfaceless")) {
+		PFNEGLGETPLATFORMDISPLAYEXTPROC getPlatformDisplay = (PFNEGLGETPLATFORMDISPLAYEXTPROC) eglGetProcAddress("eglGetPlatformDisplayEXT");
+
+		if (getPlatformDisplay != NULL) {
+			EGLDisplay display = getPlatformDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+
+			if (display != EGL_NO_DISPLAY) {
+				return display;
+			}
+		}
+	}
+
+	return eglGetDisplay(EGL_DEFAULT_DISPLAY);
+}
+
+static EGLConfig chooseConfig(EGLDisplay display, int pbuffer) {
+	EGLint attribs[] = {
+		EGL_SURFACE_TYPE, pbuffer ? EGL_PBUFFER_BIT : 0,
+		EGL_RENDERABLE_TYPE, EGL_OPENGL_BIT,
+		EGL_RED_SIZE, 8,
+		EGL_GREEN_SIZE, 8,
+		EGL_BLUE_SIZE, 8,
+		EGL_ALPHA_SIZE, 8,
+		EGL_NONE
+	};
+
+	EGLConfig config;
+	EGLint count = 0;
+
+	if (!eglChooseConfig(display, attribs, &config, 1, &count) || count == 0) {
+		return NULL;
+	}
+
+	return config;
+}
+
+static EGLContext createContext(EGLDisplay display, EGLConfig config) {
+	EGLint attribs[] = {
+		EGL_CONTEXT_MAJOR_VERSION, 3,
+		EGL_CONTEXT_MINOR_VERSION, 3,
+		EGL_CONTEXT_OPENGL_PROFILE_MASK, EGL_CONTEXT_OPENGL_CORE_PROFILE_BIT,
+		EGL_CONTEXT_OPENGL_FORWARD_COMPATIBLE, EGL_TRUE,
+		EGL_NONE
+	};
+
+	return eglCreateContext(display, config, EGL_NO_CONTEXT, attribs);
+}
+
+static EGLSurface createPbuffer(EGLDisplay display, EGLConfig config) {
+	EGLint attribs[] = {
+		EGL_WIDTH, 1,
+		EGL_HEIGHT, 1,
+		EGL_NONE
+	};
+
+	return eglCreatePbufferSurface(display, config, attribs);
+}
+
+static void* getProcAddress(const char* name) {
+	return (void*) eglGetProcAddress(name);
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"unsafe"
+)
+
+var (
+	display C.EGLDisplay
+	context C.EGLContext
+	surface C.EGLSurface
+)
+
+// NoDisplay returns true if there's no X11 or Wayland display danser could open a window on
+func NoDisplay() bool {
+	return os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == ""
+}
+
+// Init creates offscreen OpenGL 3.3 core context and makes it current on the calling thread.
+// Everything has to be rendered to framebuffers as there's no default one.
+func Init() error {
+	display = C.getDisplay()
+	if display == 0 {
+		return errors.New("failed to get EGL display")
+	}
+
+	var major, minor C.EGLint
+	if C.eglInitialize(display, &major, &minor) == C.EGL_FALSE {
+		return fmt.Errorf("failed to initialize EGL: 0x%x", int(C.eglGetError()))
+	}
+
+	if C.eglBindAPI(C.EGL_OPENGL_API) == C.EGL_FALSE {
+		return fmt.Errorf("EGL doesn't support desktop OpenGL: 0x%x", int(C.eglGetError()))
+	}
+
+	cExt := C.CString("EGL_KHR_surfaceless_context")
+	defer C.free(unsafe.Pointer(cExt))
+
+	surfaceless := C.hasExtension(display, cExt) != 0
+
+	config := C.chooseConfig(display, boolToInt(!surfaceless))
+	if config == 0 {
+		return errors.New("no suitable EGL config found")
+	}
+
+	context = C.createContext(display, config)
+	if context == nil {
+		return fmt.Errorf("failed to create OpenGL 3.3 context: 0x%x", int(C.eglGetError()))
+	}
+
+	surface = nil
+
+	if !surfaceless {
+		surface = C.createPbuffer(display, config)
+		if surface == nil {
+			return fmt.Errorf("failed to create pbuffer surface: 0x%x", int(C.eglGetError()))
+		}
+	}
+
+	if C.eglMakeCurrent(display, surface, surface, context) == C.EGL_FALSE {
+		return fmt.Errorf("failed to make EGL context current: 0x%x", int(C.eglGetError()))
+	}
+
+	return nil
+}
+
+// GetProcAddress is used to load OpenGL functions with gl.InitWithProcAddrFunc
+func GetProcAddress(name string) unsafe.Pointer {
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+
+	return C.getProcAddress(cName)
+}
+
+func Destroy() {
+	if display == 0 {
+		return
+	}
+
+	C.eglMakeCurrent(display, nil, nil, nil)
+
+	if surface != nil {
+		C.eglDestroySurface(display, surface)
+	}
+
+	if context != nil {
+		C.eglDestroyContext(display, context)
+	}
+
+	C.eglTerminate(display)
+
+	display = 0
+}
+
+func boolToInt(b bool) C.int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package headless
+
+import (
+	"errors"
+	"unsafe"
+)
+
+func NoDisplay() bool {
+	return false
+}
+
+func Init() error {
+	return errors.New("headless rendering is supported only on Linux")
+}
+
+func GetProcAddress(_ string) unsafe.Pointer {
+	return nil
+}
+
+func Destroy() {}
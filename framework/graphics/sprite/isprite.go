@@ -24,6 +24,8 @@ type ISprite interface {
 
 	ClearTransformationsOfType(transformationType animation.TransformationType)
 
+	RemoveTransformations(transformations []*animation.Transformation)
+
 	AdjustTimesToTransformations()
 
 	ResetValuesToTransforms()
@@ -39,6 +39,19 @@ func (manager *Manager) Add(sprite ISprite) {
 	manager.spriteQueue[n] = sprite
 }
 
+// Clear removes all sprites from the manager
+func (manager *Manager) Clear() {
+	manager.spriteQueue = nil
+	manager.spriteProcessed = nil
+
+	manager.mutex.Lock()
+
+	manager.interObjects = 0
+	manager.dirty = true
+
+	manager.mutex.Unlock()
+}
+
 func (manager *Manager) Update(time float64) {
 	dirtyLocal := false
 	toRemove := 0
@@ -13,6 +13,7 @@ const (
 	RGBA
 	BGRA
 	RGBA32F
+	RGBA16
 )
 
 func (f Format) InternalFormat() uint32 {
@@ -33,6 +34,8 @@ func (f Format) InternalFormat() uint32 {
 		return gl.BGRA
 	case RGBA32F:
 		return gl.RGBA32F
+	case RGBA16:
+		return gl.RGBA16
 	}
 
 	panic("Wrong texture format!")
@@ -48,7 +51,7 @@ func (f Format) Format() uint32 {
 		return gl.RGB
 	case BGR:
 		return gl.BGR
-	case RGBA, RGBA32F:
+	case RGBA, RGBA32F, RGBA16:
 		return gl.RGBA
 	case BGRA:
 		return gl.BGRA
@@ -63,7 +66,7 @@ func (f Format) Size() int {
 		return 1
 	case RGB, BGR, RGB32F:
 		return 3
-	case RGBA, BGRA, RGBA32F:
+	case RGBA, BGRA, RGBA32F, RGBA16:
 		return 4
 	}
 
@@ -76,6 +79,8 @@ func (f Format) Type() uint32 {
 		return gl.UNSIGNED_BYTE
 	case Depth, RGB32F, RGBA32F:
 		return gl.FLOAT
+	case RGBA16:
+		return gl.UNSIGNED_SHORT
 	}
 
 	panic("Wrong texture format!")
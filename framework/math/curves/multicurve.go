@@ -53,25 +53,36 @@ func NewMultiCurveT(typ string, points []vector.Vector2f, desiredLength float64)
 	mCurve := NewMultiCurve(typ, points)
 
 	if mCurve.length > 0 {
-		diff := float64(mCurve.length) - desiredLength
+		mCurve.truncate(desiredLength)
+	}
+
+	mCurve.updateSections()
+
+	return mCurve
+}
 
-		for len(mCurve.lines) > 0 {
-			line := mCurve.lines[len(mCurve.lines)-1]
+// truncate removes or shortens the last lines of the path so it's not longer than desiredLength
+func (mCurve *MultiCurve) truncate(desiredLength float64) {
+	diff := float64(mCurve.length) - desiredLength
 
-			if float64(line.GetLength()) > diff+minPartWidth {
-				if line.Point1 != line.Point2 {
-					pt := line.PointAt((line.GetLength() - float32(diff)) / line.GetLength())
-					mCurve.lines[len(mCurve.lines)-1] = NewLinear(line.Point1, pt)
-				}
+	for len(mCurve.lines) > 0 {
+		line := mCurve.lines[len(mCurve.lines)-1]
 
-				break
+		if float64(line.GetLength()) > diff+minPartWidth {
+			if line.Point1 != line.Point2 {
+				pt := line.PointAt((line.GetLength() - float32(diff)) / line.GetLength())
+				mCurve.lines[len(mCurve.lines)-1] = NewLinear(line.Point1, pt)
 			}
 
-			diff -= float64(line.GetLength())
-			mCurve.lines = mCurve.lines[:len(mCurve.lines)-1]
+			break
 		}
+
+		diff -= float64(line.GetLength())
+		mCurve.lines = mCurve.lines[:len(mCurve.lines)-1]
 	}
+}
 
+func (mCurve *MultiCurve) updateSections() {
 	mCurve.length = 0.0
 
 	for _, l := range mCurve.lines {
@@ -86,8 +97,6 @@ func NewMultiCurveT(typ string, points []vector.Vector2f, desiredLength float64)
 		prev += mCurve.lines[i].GetLength()
 		mCurve.sections[i+1] = prev
 	}
-
-	return mCurve
 }
 
 func (mCurve *MultiCurve) PointAt(t float32) vector.Vector2f {
@@ -0,0 +1,109 @@
+package curves
+
+import (
+	"github.com/wieku/danser-go/framework/math/mutils"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"math"
+)
+
+// stableArcTolerance is the maximum distance between arc's approximation and the circle
+const stableArcTolerance = 0.1
+
+// NewMultiCurveStable creates a path the same way as osu!stable does.
+// Perfect curves with collinear points become linear paths, short arcs keep their length,
+// paths ending with a red anchor keep the duplicated point and aren't extended.
+// Expected values are documented in testdata/stable_paths.json.
+func NewMultiCurveStable(typ string, points []vector.Vector2f, desiredLength float64) *MultiCurve {
+	var lines []Linear
+
+	switch typ {
+	case "P":
+		lines = processPerfectStable(points)
+	case "L":
+		lines = processLinearStable(points)
+	case "B":
+		lines = processBezier(points)
+	case "C":
+		lines = processCatmull(points)
+	}
+
+	mCurve := &MultiCurve{
+		lines:      lines,
+		firstPoint: points[0],
+	}
+
+	mCurve.updateSections()
+
+	if mCurve.length > 0 {
+		mCurve.truncate(desiredLength)
+		mCurve.updateSections()
+
+		mCurve.extend(desiredLength)
+		mCurve.updateSections()
+	}
+
+	return mCurve
+}
+
+// extend adds a line to the path along the direction of the last line if it's still shorter than desiredLength.
+// Zero length last line comes from a red anchor at the end, osu!stable doesn't extend such paths.
+func (mCurve *MultiCurve) extend(desiredLength float64) {
+	diff := float32(desiredLength) - mCurve.length
+	if diff <= minPartWidth {
+		return
+	}
+
+	line := mCurve.lines[len(mCurve.lines)-1]
+
+	lineLength := line.GetLength()
+	if lineLength == 0 {
+		return
+	}
+
+	direction := line.Point2.Sub(line.Point1).Scl(1 / lineLength)
+
+	mCurve.lines = append(mCurve.lines, NewLinear(line.Point2, line.Point2.Add(direction.Scl(diff))))
+}
+
+// processLinearStable keeps the red anchor at the end of the path, red anchors in the middle don't change the shape
+func processLinearStable(points []vector.Vector2f) []Linear {
+	lines := processLinear(points)
+
+	if last := len(points) - 1; last > 0 && points[last] == points[last-1] {
+		lines = append(lines, NewLinear(points[last], points[last]))
+	}
+
+	return lines
+}
+
+func processPerfectStable(points []vector.Vector2f) []Linear {
+	if len(points) != 3 {
+		return processBezier(points)
+	}
+
+	if vector.IsStraightLine32(points[0], points[1], points[2]) {
+		return processLinearStable(points)
+	}
+
+	return approximateCircularArcStable(points[0], points[1], points[2])
+}
+
+// approximateCircularArcStable picks the number of lines by the distance to the circle instead of arc's length, so short arcs aren't lost
+func approximateCircularArcStable(pt1, pt2, pt3 vector.Vector2f) []Linear {
+	arc := NewCirArc(pt1, pt2, pt3)
+
+	r := float64(arc.r)
+
+	segments := 2
+	if 2*r > stableArcTolerance {
+		segments = mutils.Max(2, int(math.Ceil(arc.totalAngle/(2*math.Acos(1-stableArcTolerance/r)))))
+	}
+
+	lines := make([]Linear, segments)
+
+	for i := 0; i < segments; i++ {
+		lines[i] = NewLinear(arc.PointAt(float32(i)/float32(segments)), arc.PointAt(float32(i+1)/float32(segments)))
+	}
+
+	return lines
+}
@@ -0,0 +1,63 @@
+package curves
+
+import (
+	"encoding/json"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"os"
+	"testing"
+)
+
+const stablePathTolerance = 0.5
+
+type stablePathFixture struct {
+	Name   string       `json:"name"`
+	Type   string       `json:"type"`
+	Points [][2]float32 `json:"points"`
+	Length float64      `json:"length"`
+	End    [2]float32   `json:"end"`
+
+	// PathLength is the expected length of the path if it's different from Length
+	PathLength float64 `json:"pathLength"`
+}
+
+func loadStablePathFixtures(t *testing.T) []stablePathFixture {
+	data, err := os.ReadFile("testdata/stable_paths.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fixtures []stablePathFixture
+	if err = json.Unmarshal(data, &fixtures); err != nil {
+		t.Fatal(err)
+	}
+
+	return fixtures
+}
+
+func TestNewMultiCurveStable(t *testing.T) {
+	for _, fixture := range loadStablePathFixtures(t) {
+		t.Run(fixture.Name, func(t *testing.T) {
+			points := make([]vector.Vector2f, len(fixture.Points))
+			for i, p := range fixture.Points {
+				points[i] = vector.NewVec2f(p[0], p[1])
+			}
+
+			curve := NewMultiCurveStable(fixture.Type, points, fixture.Length)
+
+			length := fixture.Length
+			if fixture.PathLength > 0 {
+				length = fixture.PathLength
+			}
+
+			if diff := float64(curve.GetLength()) - length; diff > stablePathTolerance || diff < -stablePathTolerance {
+				t.Errorf("expected length %.3f, got %.3f", length, curve.GetLength())
+			}
+
+			expected := vector.NewVec2f(fixture.End[0], fixture.End[1])
+
+			if end := curve.PointAt(1); end.Dst(expected) > stablePathTolerance {
+				t.Errorf("expected end at %s, got %s", expected.String(), end.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package curves
+
+import (
+	"fmt"
+	"github.com/wieku/danser-go/framework/math/math32"
+	"github.com/wieku/danser-go/framework/math/vector"
+	"strconv"
+)
+
+// svgBezierSize is the size bezier curves are scaled to before approximating, so small SVG shapes keep their detail
+const svgBezierSize = 200.0
+
+type svgPathParser struct {
+	data string
+	pos  int
+
+	paths   [][]vector.Vector2f
+	current []vector.Vector2f
+
+	point       vector.Vector2f
+	start       vector.Vector2f
+	lastControl vector.Vector2f
+}
+
+// ParseSVGPath parses SVG path data (the "d" attribute) and flattens it to polylines, one for each subpath
+func ParseSVGPath(data string) ([][]vector.Vector2f, error) {
+	parser := &svgPathParser{data: data}
+
+	if err := parser.parse(); err != nil {
+		return nil, err
+	}
+
+	return parser.paths, nil
+}
+
+func (parser *svgPathParser) parse() error {
+	var command, lastCommand byte
+
+	for {
+		parser.skipSeparators()
+
+		if parser.pos >= len(parser.data) {
+			break
+		}
+
+		c := parser.data[parser.pos]
+
+		switch {
+		case isSVGCommand(c):
+			command = c
+			parser.pos++
+		case lastCommand != 0 && lastCommand != 'Z' && lastCommand != 'z' && parser.hasNumber():
+			// Repeated command, implicit commands after moveto are lineto
+			command = lastCommand
+			if command == 'M' {
+				command = 'L'
+			} else if command == 'm' {
+				command = 'l'
+			}
+		default:
+			return fmt.Errorf("unexpected character '%c' at position %d", c, parser.pos)
+		}
+
+		if err := parser.segment(command, lastCommand); err != nil {
+			return fmt.Errorf("command '%c' at position %d: %w", command, parser.pos, err)
+		}
+
+		lastCommand = command
+	}
+
+	parser.finishSubpath()
+
+	return nil
+}
+
+func (parser *svgPathParser) segment(command, lastCommand byte) error {
+	relative := command >= 'a'
+
+	var base vector.Vector2f
+	if relative {
+		base = parser.point
+	}
+
+	nums, err := parser.numbers(svgArgCount(command))
+	if err != nil {
+		return err
+	}
+
+	pt := func(i int) vector.Vector2f {
+		return vector.NewVec2f(nums[i], nums[i+1]).Add(base)
+	}
+
+	control := parser.point
+
+	switch command {
+	case 'M', 'm':
+		parser.finishSubpath()
+
+		parser.point = pt(0)
+		parser.start = parser.point
+		parser.current = []vector.Vector2f{parser.point}
+	case 'L', 'l':
+		parser.lineTo(pt(0))
+	case 'H':
+		parser.lineTo(vector.NewVec2f(nums[0], parser.point.Y))
+	case 'h':
+		parser.lineTo(parser.point.AddS(nums[0], 0))
+	case 'V':
+		parser.lineTo(vector.NewVec2f(parser.point.X, nums[0]))
+	case 'v':
+		parser.lineTo(parser.point.AddS(0, nums[0]))
+	case 'C', 'c':
+		control = pt(2)
+		parser.bezierTo(pt(0), control, pt(4))
+	case 'S', 's':
+		c1 := parser.point
+		if isSVGCubic(lastCommand) {
+			c1 = parser.point.Scl(2).Sub(parser.lastControl)
+		}
+
+		control = pt(0)
+		parser.bezierTo(c1, control, pt(2))
+	case 'Q', 'q':
+		control = pt(0)
+		parser.bezierTo(control, pt(2))
+	case 'T', 't':
+		if isSVGQuadratic(lastCommand) {
+			control = parser.point.Scl(2).Sub(parser.lastControl)
+		}
+
+		parser.bezierTo(control, pt(0))
+	case 'A', 'a':
+		large, sweep := nums[3] != 0, nums[4] != 0
+		parser.arcTo(nums[0], nums[1], nums[2], large, sweep, pt(5))
+	case 'Z', 'z':
+		parser.lineTo(parser.start)
+		parser.finishSubpath()
+
+		parser.current = []vector.Vector2f{parser.start}
+	}
+
+	parser.lastControl = control
+
+	return nil
+}
+
+func (parser *svgPathParser) lineTo(point vector.Vector2f) {
+	if len(parser.current) == 0 {
+		parser.current = []vector.Vector2f{parser.point}
+	}
+
+	parser.current = append(parser.current, point)
+	parser.point = point
+}
+
+func (parser *svgPathParser) bezierTo(points ...vector.Vector2f) {
+	controlPoints := append([]vector.Vector2f{parser.point}, points...)
+
+	minP, maxP := controlPoints[0], controlPoints[0]
+	for _, p := range controlPoints {
+		minP = vector.NewVec2f(math32.Min(minP.X, p.X), math32.Min(minP.Y, p.Y))
+		maxP = vector.NewVec2f(math32.Max(maxP.X, p.X), math32.Max(maxP.Y, p.Y))
+	}
+
+	size := math32.Max(maxP.X-minP.X, maxP.Y-minP.Y)
+	if size == 0 {
+		parser.lineTo(points[len(points)-1])
+		return
+	}
+
+	scale := svgBezierSize / size
+
+	for i, p := range controlPoints {
+		controlPoints[i] = p.Sub(minP).Scl(scale)
+	}
+
+	for _, line := range ApproximateBezier(controlPoints) {
+		parser.lineTo(line.Point2.Scl(1 / scale).Add(minP))
+	}
+
+	// Avoid float errors at the end of the curve
+	parser.point = points[len(points)-1]
+	parser.current[len(parser.current)-1] = parser.point
+}
+
+// arcTo flattens elliptical arc, conversion is described in https://www.w3.org/TR/SVG11/implnote.html#ArcConversionEndpointToCenter
+func (parser *svgPathParser) arcTo(rx, ry, rotation float32, large, sweep bool, end vector.Vector2f) {
+	start := parser.point
+
+	rx, ry = math32.Abs(rx), math32.Abs(ry)
+
+	if rx == 0 || ry == 0 || start == end {
+		parser.lineTo(end)
+		return
+	}
+
+	phi := rotation * math32.Pi / 180
+
+	p := start.Sub(end).Scl(0.5).Rotate(-phi)
+
+	// Scale up radii if they are too small to reach the end point
+	if lambda := p.X*p.X/(rx*rx) + p.Y*p.Y/(ry*ry); lambda > 1 {
+		rx *= math32.Sqrt(lambda)
+		ry *= math32.Sqrt(lambda)
+	}
+
+	num := rx*rx*ry*ry - rx*rx*p.Y*p.Y - ry*ry*p.X*p.X
+	den := rx*rx*p.Y*p.Y + ry*ry*p.X*p.X
+
+	coef := math32.Sqrt(math32.Max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+
+	cp := vector.NewVec2f(coef*rx*p.Y/ry, -coef*ry*p.X/rx)
+	center := cp.Rotate(phi).Add(start.Add(end).Scl(0.5))
+
+	theta1 := vector.NewVec2f((p.X-cp.X)/rx, (p.Y-cp.Y)/ry).AngleR()
+	theta2 := vector.NewVec2f((-p.X-cp.X)/rx, (-p.Y-cp.Y)/ry).AngleR()
+
+	dTheta := theta2 - theta1
+
+	if sweep && dTheta < 0 {
+		dTheta += 2 * math32.Pi
+	} else if !sweep && dTheta > 0 {
+		dTheta -= 2 * math32.Pi
+	}
+
+	segments := int(math32.Ceil(math32.Abs(dTheta) / (math32.Pi / 32)))
+
+	for i := 1; i < segments; i++ {
+		theta := theta1 + dTheta*float32(i)/float32(segments)
+
+		point := vector.NewVec2f(rx*math32.Cos(theta), ry*math32.Sin(theta)).Rotate(phi).Add(center)
+
+		parser.lineTo(point)
+	}
+
+	parser.lineTo(end)
+}
+
+func (parser *svgPathParser) finishSubpath() {
+	if len(parser.current) > 1 {
+		parser.paths = append(parser.paths, parser.current)
+	}
+
+	parser.current = nil
+}
+
+func (parser *svgPathParser) skipSeparators() {
+	for parser.pos < len(parser.data) {
+		switch parser.data[parser.pos] {
+		case ' ', '\t', '\n', '\r', '\f', ',':
+			parser.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (parser *svgPathParser) hasNumber() bool {
+	parser.skipSeparators()
+
+	if parser.pos >= len(parser.data) {
+		return false
+	}
+
+	c := parser.data[parser.pos]
+
+	return (c >= '0' && c <= '9') || c == '.' || c == '-' || c == '+'
+}
+
+func (parser *svgPathParser) numbers(count int) ([]float32, error) {
+	nums := make([]float32, count)
+
+	for i := range nums {
+		if !parser.hasNumber() {
+			return nil, fmt.Errorf("expected %d numbers, got %d", count, i)
+		}
+
+		start := parser.pos
+
+		// Arc flags are single digits that don't have to be separated
+		if count == 7 && (i == 3 || i == 4) {
+			parser.pos++
+		} else {
+			parser.scanNumber()
+		}
+
+		num, err := strconv.ParseFloat(parser.data[start:parser.pos], 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number \"%s\"", parser.data[start:parser.pos])
+		}
+
+		nums[i] = float32(num)
+	}
+
+	return nums, nil
+}
+
+// scanNumber moves past the number, numbers don't need separators if they can't be confused, e.g. "1.5.5-2" is 1.5, .5 and -2
+func (parser *svgPathParser) scanNumber() {
+	data := parser.data
+
+	if data[parser.pos] == '-' || data[parser.pos] == '+' {
+		parser.pos++
+	}
+
+	dot, exponent := false, false
+
+	for ; parser.pos < len(data); parser.pos++ {
+		c := data[parser.pos]
+
+		switch {
+		case c >= '0' && c <= '9':
+		case c == '.' && !dot && !exponent:
+			dot = true
+		case (c == 'e' || c == 'E') && !exponent:
+			exponent = true
+
+			if parser.pos+1 < len(data) && (data[parser.pos+1] == '-' || data[parser.pos+1] == '+') {
+				parser.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func svgArgCount(command byte) int {
+	switch command {
+	case 'M', 'm', 'L', 'l', 'T', 't':
+		return 2
+	case 'H', 'h', 'V', 'v':
+		return 1
+	case 'C', 'c':
+		return 6
+	case 'S', 's', 'Q', 'q':
+		return 4
+	case 'A', 'a':
+		return 7
+	}
+
+	return 0
+}
+
+func isSVGCommand(c byte) bool {
+	return svgArgCount(c) > 0 || c == 'Z' || c == 'z'
+}
+
+func isSVGCubic(command byte) bool {
+	return command == 'C' || command == 'c' || command == 'S' || command == 's'
+}
+
+func isSVGQuadratic(command byte) bool {
+	return command == 'Q' || command == 'q' || command == 'T' || command == 't'
+}
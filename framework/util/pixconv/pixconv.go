@@ -6,7 +6,11 @@ package pixconv
 #include "libyuv.h"
 */
 import "C"
-import "fmt"
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/wieku/danser-go/framework/math/mutils"
+)
 
 type PixFmt int
 
@@ -203,6 +207,35 @@ func ConvertNV21ToRGB(input []byte, output []byte, w, h int) {
 	C.NV21ToRAW((*C.uint8_t)(&input[0]), C.int(w), (*C.uint8_t)(&input[w*h]), C.int(w), (*C.uint8_t)(&output[0]), C.int(w*3), C.int(w), C.int(h))
 }
 
+// UnpremultiplyRGBA converts 8-bit RGBA with premultiplied alpha to straight alpha
+func UnpremultiplyRGBA(input []byte, output []byte, w, h int) {
+	checkDimensions(input, output, w*h*4, w*h*4)
+
+	// libyuv's ARGB is BGRA in memory, but color channels are processed the same way, so it works for RGBA too
+	C.ARGBUnattenuate((*C.uint8_t)(&input[0]), C.int(w*4), (*C.uint8_t)(&output[0]), C.int(w*4), C.int(w), C.int(h))
+}
+
+// UnpremultiplyRGBA64 converts 16-bit little-endian RGBA with premultiplied alpha to straight alpha
+func UnpremultiplyRGBA64(input []byte, output []byte, w, h int) {
+	checkDimensions(input, output, w*h*8, w*h*8)
+
+	for i := 0; i < w*h*8; i += 8 {
+		a := uint32(binary.LittleEndian.Uint16(input[i+6:]))
+
+		for c := 0; c < 6; c += 2 {
+			v := uint32(0)
+
+			if a > 0 {
+				v = mutils.Min(uint32(binary.LittleEndian.Uint16(input[i+c:]))*0xFFFF/a, 0xFFFF)
+			}
+
+			binary.LittleEndian.PutUint16(output[i+c:], uint16(v))
+		}
+
+		binary.LittleEndian.PutUint16(output[i+6:], uint16(a))
+	}
+}
+
 func checkDimensions(input []byte, output []byte, expectedInput int, expectedOutput int) {
 	if len(input) < expectedInput {
 		panic(fmt.Sprintf("input buffer is smaller than required, expected: %d, actual: %d", expectedInput, len(input)))
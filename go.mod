@@ -7,7 +7,7 @@ require (
 	github.com/Microsoft/go-winio v0.5.0
 	github.com/StackExchange/wmi v1.2.1 // indirect
 	github.com/ananagame/rich-go v0.0.0-20210525072106-9d45f0e06959 // indirect
-	github.com/bnch/uleb128 v0.0.0-20160221084957-fac1fe18ad59 // indirect
+	github.com/bnch/uleb128 v0.0.0-20160221084957-fac1fe18ad59
 	github.com/dustin/go-humanize v1.0.0
 	github.com/faiface/mainthread v0.0.0-20171120011319-8b78f0a41ae3
 	github.com/fsnotify/fsnotify v1.5.1
@@ -15,7 +15,7 @@ require (
 	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20210727001814-0db043d8d5be
 	github.com/go-gl/mathgl v1.0.0
 	github.com/go-ole/go-ole v1.2.5 // indirect
-	github.com/itchio/lzma v0.0.0-20190703113020-d3e24e3e3d49 // indirect
+	github.com/itchio/lzma v0.0.0-20190703113020-d3e24e3e3d49
 	github.com/karrick/godirwalk v1.16.1
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.13 // indirect
@@ -30,8 +30,9 @@ require (
 	github.com/tklauser/go-sysconf v0.3.8 // indirect
 	github.com/tklauser/numcpus v0.3.0 // indirect
 	github.com/wieku/rplpa v0.0.0-20210919131836-bff7a920f6e1
+	go.starlark.net v0.0.0-20230525235612-a134d8f9ddca
 	golang.org/x/image v0.0.0-20210628002857-a66eb6448b8d
-	golang.org/x/sys v0.0.0-20211019181941-9d821ace8654
+	golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8
 	golang.org/x/text v0.3.6
 	gopkg.in/natefinch/npipe.v2 v2.0.0-20160621034901-c1b8fa8bdcce // indirect
 )
@@ -1614,6 +1614,10 @@ func (l *launcher) startDanser() {
 				resultFile = strings.TrimPrefix(line[idx:], "Video is available at: ")
 			}
 
+			if idx := strings.Index(line, "Image sequence is available at: "); idx > -1 {
+				resultFile = strings.TrimPrefix(line[idx:], "Image sequence is available at: ")
+			}
+
 			if idx := strings.Index(line, "Screenshot "); idx > -1 && strings.Contains(line, " saved!") {
 				resultFile = strings.TrimSuffix(strings.TrimPrefix(line[idx:], "Screenshot "), " saved!")
 				resultFile = filepath.Join(env.DataDir(), "screenshots", resultFile)